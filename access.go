@@ -0,0 +1,58 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// errAccessStale is returned when an entry changed after it was read,
+// so its last access time isn't updated.
+var errAccessStale = errors.New("entry changed since it was read")
+
+// WithAccessTracking records the last access time of cache entries
+// in Data.LastAccessed when Get, GetWithTTL, or GetReader hit them.
+// Recording an access rewrites the header of the entry file,
+// so it's done at most once per resolution to keep reads cheap;
+// a resolution of zero records every access.
+// Read, List, and the other listing methods don't count as accesses.
+// Tracking is best-effort: errors are logged and the access is dropped,
+// and read-only caches never record accesses.
+func WithAccessTracking(resolution time.Duration) Option {
+	return func(c *Cache) {
+		if resolution < 0 {
+			c.err = fmt.Errorf("invalid access resolution %v", resolution)
+			return
+		}
+		c.trackAccess = true
+		c.accessRes = resolution
+	}
+}
+
+// recordAccess updates the last access time of a cache entry
+// that was just read, if tracking is enabled and the recorded time
+// is older than the resolution.
+// The entry isn't updated if it changed since it was read.
+func (c Cache) recordAccess(key string, read Data) {
+	if !c.trackAccess || c.readOnly {
+		return
+	}
+	now := c.now()
+	if !read.LastAccessed.IsZero() && now.Sub(read.LastAccessed) < c.accessRes {
+		return
+	}
+	unlock := c.locks.lock(key)
+	defer unlock()
+	_, err := c.rewriteHeader(key, func(rec *record) error {
+		if !rec.CreatedAt.Equal(read.CreatedAt) || !rec.Expiry.Equal(read.Expiry) ||
+			!rec.LastAccessed.Equal(read.LastAccessed) {
+			return errAccessStale
+		}
+		rec.LastAccessed = now
+		return nil
+	})
+	if err != nil {
+		c.log(slog.LevelDebug, "error recording access", "key", key, "error", err)
+	}
+}
@@ -0,0 +1,166 @@
+package diskcache_test
+
+import (
+	"io"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestAccessTracking(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("TestNotTrackedByDefault", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, err = cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		data, err := cache.Read("key")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		if !data.LastAccessed.IsZero() {
+			t.Fatalf("Expected no last access time, got %s", data.LastAccessed)
+		}
+	})
+
+	t.Run("TestGet", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"),
+			diskcache.WithClock(clock), diskcache.WithAccessTracking(time.Minute))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		data, err := cache.Read("key")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		if !data.LastAccessed.IsZero() {
+			t.Fatalf("Expected no last access time after Set, got %s", data.LastAccessed)
+		}
+		if !data.CreatedAt.Equal(start) {
+			t.Fatalf("Expected creation time %s, got %s", start, data.CreatedAt)
+		}
+
+		clock.Advance(10 * time.Minute)
+		_, err = cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		data, err = cache.Read("key")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		want := start.Add(10 * time.Minute)
+		if !data.LastAccessed.Equal(want) {
+			t.Fatalf("Expected last access time %s, got %s", want, data.LastAccessed)
+		}
+		if !data.CreatedAt.Equal(start) {
+			t.Fatalf("Expected creation time %s, got %s", start, data.CreatedAt)
+		}
+		if string(data.Value) != "value" {
+			t.Fatalf("Expected value %q, got %q", "value", data.Value)
+		}
+
+		// Accesses within the resolution aren't recorded.
+		clock.Advance(30 * time.Second)
+		_, err = cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		data, _ = cache.Read("key")
+		if !data.LastAccessed.Equal(want) {
+			t.Fatalf("Expected last access time %s, got %s", want, data.LastAccessed)
+		}
+
+		clock.Advance(time.Minute)
+		r, err := cache.GetReader("key")
+		if err != nil {
+			t.Fatalf("Error getting reader: %v", err)
+		}
+		io.ReadAll(r)
+		r.Close()
+		data, _ = cache.Read("key")
+		want = start.Add(11*time.Minute + 30*time.Second)
+		if !data.LastAccessed.Equal(want) {
+			t.Fatalf("Expected last access time %s, got %s", want, data.LastAccessed)
+		}
+
+		err = cache.Set("key", []byte("new"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		data, _ = cache.Read("key")
+		if !data.LastAccessed.IsZero() {
+			t.Fatalf("Expected Set to reset last access time, got %s", data.LastAccessed)
+		}
+	})
+
+	t.Run("TestIndexAndSort", func(t *testing.T) {
+		clock := &fakeClock{now: start}
+		dir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(dir, diskcache.WithClock(clock),
+			diskcache.WithAccessTracking(0), diskcache.WithIndex())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"a", "b", "c"} {
+			err = cache.Set(key, []byte(key), time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		for _, key := range []string{"c", "a"} {
+			clock.Advance(time.Second)
+			_, err = cache.Get(key)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+		}
+		list, err := cache.ListPrefix("", diskcache.SortByAccessed)
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		var keys []string
+		for _, data := range list {
+			keys = append(keys, data.Key)
+		}
+		if len(keys) != 3 || keys[0] != "b" || keys[1] != "c" || keys[2] != "a" {
+			t.Fatalf("Expected keys [b c a], got %v", keys)
+		}
+
+		// The index is loaded with the recorded access times.
+		reopened, err := diskcache.New(dir, diskcache.WithIndex())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		page, _, err := reopened.ListPage(0, 3, diskcache.SortByAccessed)
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(page) != 3 || page[2].Key != "a" || !page[2].LastAccessed.Equal(start.Add(2*time.Second)) {
+			t.Fatalf("Expected a last accessed at %s, got %v", start.Add(2*time.Second), page)
+		}
+	})
+
+	t.Run("TestInvalidResolution", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithAccessTracking(-time.Second))
+		if err == nil {
+			t.Fatalf("Expected error for negative resolution")
+		}
+	})
+}
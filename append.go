@@ -0,0 +1,47 @@
+package diskcache
+
+import "fmt"
+
+// Append appends data to the value of a cache entry,
+// keeping its expiry and metadata.
+// If the entry doesn't exist or is expired, Append creates it
+// with the data as its value, and it never expires,
+// unless the cache has a maximum TTL.
+// Append is atomic with respect to other CAS, Increment, and Append calls
+// on the cache and its copies and, if the cache supports Lock,
+// in other processes sharing the cache directory;
+// calling it while holding Lock on the key deadlocks.
+func (c Cache) Append(key string, data []byte) error {
+	if len(key) == 0 {
+		return fmt.Errorf("key cannot be empty")
+	}
+	unlock, err := c.lockEntry(key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	rec, ok, err := c.current(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		rec.Data = Data{Key: key, Expiry: c.expiryFrom(c.now(), NoExpiry)}
+		rec.CreatedAt = c.now()
+	}
+	rec.Value = append(rec.Value, data...)
+	err = c.keepVersion(c.Filename(key))
+	if err != nil {
+		return fmt.Errorf("error keeping version: %w", err)
+	}
+	err = c.write(rec.Data)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		err = c.evict(key)
+		if err != nil {
+			return fmt.Errorf("error evicting entries: %w", err)
+		}
+	}
+	return nil
+}
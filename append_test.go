@@ -0,0 +1,106 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestAppend(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestCreate", func(t *testing.T) {
+		err := cache.Append("new", []byte("first"))
+		if err != nil {
+			t.Fatalf("Error appending cache: %v", err)
+		}
+		data, err := cache.Read("new")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		if string(data.Value) != "first" || !data.Expiry.IsZero() {
+			t.Fatalf("Expected a new entry that never expires, got %v", data)
+		}
+	})
+
+	t.Run("TestKeepExpiry", func(t *testing.T) {
+		err := cache.SetWithMeta("log", []byte("a"), map[string]string{"day": "monday"}, 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		want, err := cache.Read("log")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		err = cache.Append("log", []byte("b"))
+		if err != nil {
+			t.Fatalf("Error appending cache: %v", err)
+		}
+		got, err := cache.Read("log")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		if string(got.Value) != "ab" || !got.Expiry.Equal(want.Expiry) || got.Meta["day"] != "monday" {
+			t.Fatalf("Expected value ab with the same expiry and metadata, got %v", got)
+		}
+	})
+
+	t.Run("TestConcurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := cache.Append("events", []byte("x"))
+				if err != nil {
+					t.Errorf("Error appending cache: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		got, err := cache.Get("events")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, bytes.Repeat([]byte("x"), 20)) {
+			t.Fatalf("Expected 20 appended bytes, got %s", got)
+		}
+	})
+
+	t.Run("TestSeparateCaches", func(t *testing.T) {
+		// Caches opened separately share only the directory,
+		// like caches in different processes.
+		other, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		caches := []diskcache.Cache{cache, other}
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func(c diskcache.Cache) {
+				defer wg.Done()
+				err := c.Append("shared", []byte("x"))
+				if err != nil {
+					t.Errorf("Error appending cache: %v", err)
+				}
+			}(caches[i%2])
+		}
+		wg.Wait()
+		got, err := cache.Get("shared")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, bytes.Repeat([]byte("x"), 20)) {
+			t.Fatalf("Expected 20 appended bytes, got %s", got)
+		}
+	})
+}
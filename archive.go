@@ -0,0 +1,104 @@
+package diskcache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// Export writes all cache entries to w as a gzip-compressed tar archive,
+// with each entry in a JSON file.
+// Values are exported decoded, so the archive is portable between caches
+// with different options, but the values of encrypted caches aren't encrypted.
+// Expired entries are exported; Import skips them.
+func (c Cache) Export(w io.Writer) error {
+	filenames, err := c.filenames()
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, filename := range filenames {
+		rec, err := c.readDecoded(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading entry: %w", err)
+		}
+		contents, err := json.Marshal(rec.Data)
+		if err != nil {
+			return fmt.Errorf("error marshaling entry: %w", err)
+		}
+		modTime := rec.CreatedAt
+		if modTime.IsZero() {
+			modTime = c.now()
+		}
+		err = tw.WriteHeader(&tar.Header{
+			Name:    hashName(rec.Key) + ".json",
+			Mode:    0644,
+			Size:    int64(len(contents)),
+			ModTime: modTime,
+		})
+		if err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+		_, err = tw.Write(contents)
+		if err != nil {
+			return fmt.Errorf("error writing archive: %w", err)
+		}
+	}
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("error writing archive: %w", err)
+	}
+	return gw.Close()
+}
+
+// Import reads cache entries from a gzip-compressed tar archive
+// written by Export and saves them, replacing entries with the same keys.
+// The entries keep their expiry and creation times.
+// It skips expired entries and files in the archive that aren't entries.
+func (c Cache) Import(r io.Reader) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("error reading archive: %w", err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	now := c.now()
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || path.Ext(header.Name) != ".json" {
+			continue
+		}
+		var entry Data
+		err = json.NewDecoder(tr).Decode(&entry)
+		if err != nil {
+			return fmt.Errorf("error unmarshaling %s: %w", header.Name, err)
+		}
+		if len(entry.Key) == 0 || expired(entry.Expiry, now) {
+			continue
+		}
+		err = c.write(entry)
+		if err != nil {
+			return err
+		}
+	}
+	err = c.evict("")
+	if err != nil {
+		return fmt.Errorf("error evicting entries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestArchive(t *testing.T) {
+	secret := bytes.Repeat([]byte("k"), 32)
+	src, err := diskcache.New(path.Join(t.TempDir(), "src"), diskcache.WithEncryption(secret))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = src.SetWithMeta("key", []byte("value"), map[string]string{"etag": "abc"}, 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = src.Set("forever", []byte("forever"), diskcache.NoExpiry)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = src.Set("expired", []byte("expired"), 1*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	var buf bytes.Buffer
+	err = src.Export(&buf)
+	if err != nil {
+		t.Fatalf("Error exporting cache: %v", err)
+	}
+	dst, err := diskcache.New(path.Join(t.TempDir(), "dst"), diskcache.WithSharding(), diskcache.WithCompression(diskcache.Gzip))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = dst.Import(&buf)
+	if err != nil {
+		t.Fatalf("Error importing cache: %v", err)
+	}
+
+	t.Run("TestEntries", func(t *testing.T) {
+		list, err := dst.List(diskcache.SortByKey)
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(list))
+		}
+		want, err := src.Read("key")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		got := list[1]
+		if got.Key != want.Key || !bytes.Equal(got.Value, want.Value) || !got.Expiry.Equal(want.Expiry) || got.Meta["etag"] != "abc" {
+			t.Fatalf("Expected entry %v, got %v", want, got)
+		}
+		if !list[0].Expiry.IsZero() {
+			t.Fatalf("Expected entry to never expire")
+		}
+	})
+
+	t.Run("TestInvalidArchive", func(t *testing.T) {
+		err := dst.Import(bytes.NewReader([]byte("not an archive")))
+		if err == nil {
+			t.Fatalf("Expected error importing invalid archive")
+		}
+	})
+}
+
+func TestExportRemovedEntry(t *testing.T) {
+	fsys := &faultFS{}
+	src, err := diskcache.New(path.Join(t.TempDir(), "src"), diskcache.WithFS(fsys))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, key := range []string{"kept", "removed"} {
+		err = src.Set(key, []byte("value of "+key), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	// Another process removes the entry after the directory is read.
+	removed := src.Filepath("removed")
+	fsys.fail = func(op string, name string) error {
+		if op == "open" && name == removed {
+			return fs.ErrNotExist
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	err = src.Export(&buf)
+	if err != nil {
+		t.Fatalf("Error exporting cache: %v", err)
+	}
+	dst, err := diskcache.New(path.Join(t.TempDir(), "dst"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = dst.Import(&buf)
+	if err != nil {
+		t.Fatalf("Error importing cache: %v", err)
+	}
+	if !dst.Has("kept") || dst.Has("removed") {
+		t.Fatalf("Expected only kept to be exported")
+	}
+}
@@ -0,0 +1,83 @@
+package diskcache
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Result is the result of getting one key in a batch.
+type Result struct {
+	Value []byte
+	Err   error
+}
+
+// GetMulti gets many cache entries concurrently with a bounded number of workers.
+// It returns a result for every key.
+func (c Cache) GetMulti(keys []string) map[string]Result {
+	var mu sync.Mutex
+	results := make(map[string]Result, len(keys))
+	parallel(keys, runtime.NumCPU(), func(key string) {
+		value, err := c.Get(key)
+		mu.Lock()
+		defer mu.Unlock()
+		results[key] = Result{Value: value, Err: err}
+	})
+	return results
+}
+
+// SetMulti saves many cache entries with the same duration concurrently
+// with a bounded number of workers.
+// It returns the errors of the keys that failed, if any.
+func (c Cache) SetMulti(entries map[string][]byte, duration time.Duration) map[string]error {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	return multi(keys, func(key string) error {
+		return c.Set(key, entries[key], duration)
+	})
+}
+
+// RemoveMulti deletes many cache entries concurrently with a bounded number of workers.
+// It returns the errors of the keys that failed, if any.
+func (c Cache) RemoveMulti(keys []string) map[string]error {
+	return multi(keys, c.Remove)
+}
+
+// multi runs fn for every key concurrently and collects the errors.
+func multi(keys []string, fn func(key string) error) map[string]error {
+	var mu sync.Mutex
+	errs := make(map[string]error)
+	parallel(keys, runtime.NumCPU(), func(key string) {
+		err := fn(key)
+		if err == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		errs[key] = err
+	})
+	return errs
+}
+
+// parallel runs fn for every key with at most workers goroutines.
+func parallel(keys []string, workers int, fn func(key string)) {
+	workers = max(1, min(workers, len(keys)))
+	ch := make(chan string)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range ch {
+				fn(key)
+			}
+		}()
+	}
+	for _, key := range keys {
+		ch <- key
+	}
+	close(ch)
+	wg.Wait()
+}
@@ -0,0 +1,73 @@
+package diskcache_test
+
+import (
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestBatch(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	entries := make(map[string][]byte)
+	var keys []string
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("key%d", i)
+		entries[key] = []byte(fmt.Sprintf("value%d", i))
+		keys = append(keys, key)
+	}
+
+	t.Run("TestSetMulti", func(t *testing.T) {
+		errs := cache.SetMulti(entries, 1*time.Minute)
+		if len(errs) != 0 {
+			t.Fatalf("Error saving cache: %v", errs)
+		}
+		errs = cache.SetMulti(map[string][]byte{"": []byte("value")}, 1*time.Minute)
+		if errs[""] == nil {
+			t.Fatalf("Expected error for empty key, but got nil")
+		}
+	})
+
+	t.Run("TestGetMulti", func(t *testing.T) {
+		results := cache.GetMulti(append(keys, "missing"))
+		if len(results) != len(keys)+1 {
+			t.Fatalf("Expected %d results, got %d", len(keys)+1, len(results))
+		}
+		for key, want := range entries {
+			result := results[key]
+			if result.Err != nil {
+				t.Fatalf("Error getting cache: %v", result.Err)
+			}
+			if string(result.Value) != string(want) {
+				t.Fatalf("Expected cache value to be %s, got %s", want, result.Value)
+			}
+		}
+		if results["missing"].Err == nil {
+			t.Fatalf("Expected error for missing key, but got nil")
+		}
+	})
+
+	t.Run("TestRemoveMulti", func(t *testing.T) {
+		errs := cache.RemoveMulti(keys)
+		if len(errs) != 0 {
+			t.Fatalf("Error deleting cache: %v", errs)
+		}
+		errs = cache.RemoveMulti([]string{"missing"})
+		if errs["missing"] == nil {
+			t.Fatalf("Expected error for missing key, but got nil")
+		}
+		data, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(data) != 0 {
+			t.Fatalf("Expected 0 keys, got %d", len(data))
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package diskcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"sync/atomic"
+)
+
+// bloomName is the name of the blob in which the bloom filter is saved.
+const bloomName = "bloom.filter"
+
+// bloomMagic is the magic number at the start of a saved bloom filter.
+var bloomMagic = []byte("DCF\x02")
+
+// bloomBitsPerEntry and bloomHashes size a bloom filter
+// for a false positive rate of about 1% at its expected number of entries.
+const (
+	bloomBitsPerEntry = 9.6
+	bloomHashes       = 7
+)
+
+// WithBloomFilter keeps a bloom filter of the blobs in the store,
+// sized for the expected number of entries, so most reads of
+// entries that don't exist, such as by Get and Has,
+// return fs.ErrNotExist without touching the disk.
+// Reads of entries that exist, and about 1% of the others,
+// still read the disk.
+// The filter is built from the directory listing when the cache
+// is opened, or loaded from where Close saved it if the number of
+// blobs and their latest modification time haven't changed since,
+// and like the index, it assumes the cache is the only writer
+// to its directory while open.
+// Removing entries doesn't shrink the filter, so it is rebuilt
+// at the next open.
+func WithBloomFilter(expectedEntries int) Option {
+	return func(c *Cache) {
+		if expectedEntries <= 0 {
+			c.err = fmt.Errorf("invalid expected number of entries: %d", expectedEntries)
+			return
+		}
+		c.bloomEntries = expectedEntries
+	}
+}
+
+// bloom is a bloom filter of names, safe for concurrent use.
+// A nil bloom is valid and may contain any name.
+type bloom struct {
+	bits []atomic.Uint64
+	// saved is true if the filter was saved and hasn't changed since.
+	saved atomic.Bool
+	// validator describes the store when the filter was saved or loaded.
+	validator bloomValidator
+}
+
+// bloomValidator describes the blobs in a store, other than the saved
+// bloom filter, so a filter saved before another writer changed
+// the store isn't loaded.
+type bloomValidator struct {
+	// entries is the number of blobs.
+	entries uint64
+	// modTime is the latest modification time of the blobs,
+	// in nanoseconds since the Unix epoch.
+	modTime int64
+}
+
+// validateBloom returns the validator of the blobs in a store.
+func validateBloom(entries []StoreEntry) bloomValidator {
+	var v bloomValidator
+	for _, entry := range entries {
+		if entry.Name == bloomName {
+			continue
+		}
+		v.entries++
+		if !entry.ModTime.IsZero() {
+			v.modTime = max(v.modTime, entry.ModTime.UnixNano())
+		}
+	}
+	return v
+}
+
+// newBloom returns an empty bloom filter for a number of entries.
+func newBloom(entries int) *bloom {
+	n := int(math.Ceil(float64(entries) * bloomBitsPerEntry / 64))
+	return &bloom{bits: make([]atomic.Uint64, n)}
+}
+
+// positions calls f with the bit positions of a name.
+func (b *bloom) positions(name string, f func(word int, mask uint64) bool) {
+	h := fnv.New64a()
+	io.WriteString(h, name)
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32|1
+	m := uint64(len(b.bits)) * 64
+	for i := range uint64(bloomHashes) {
+		bit := (h1 + i*h2) % m
+		if !f(int(bit/64), 1<<(bit%64)) {
+			return
+		}
+	}
+}
+
+// add adds a name to the filter.
+func (b *bloom) add(name string) {
+	if b == nil {
+		return
+	}
+	b.positions(name, func(word int, mask uint64) bool {
+		b.bits[word].Or(mask)
+		return true
+	})
+}
+
+// mayContain returns false if the name was never added to the filter.
+func (b *bloom) mayContain(name string) bool {
+	if b == nil {
+		return true
+	}
+	found := true
+	b.positions(name, func(word int, mask uint64) bool {
+		found = b.bits[word].Load()&mask != 0
+		return found
+	})
+	return found
+}
+
+// MarshalBinary encodes the filter and its validator.
+func (b *bloom) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(bloomMagic[:len(bloomMagic):len(bloomMagic)])
+	buf.Grow(24 + 8*len(b.bits))
+	binary.Write(buf, binary.BigEndian, b.validator.entries)
+	binary.Write(buf, binary.BigEndian, b.validator.modTime)
+	binary.Write(buf, binary.BigEndian, uint64(len(b.bits)))
+	for i := range b.bits {
+		binary.Write(buf, binary.BigEndian, b.bits[i].Load())
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a filter encoded by MarshalBinary.
+func (b *bloom) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, bloomMagic) || len(data) < len(bloomMagic)+24 {
+		return errors.New("invalid bloom filter")
+	}
+	data = data[len(bloomMagic):]
+	b.validator.entries = binary.BigEndian.Uint64(data)
+	b.validator.modTime = int64(binary.BigEndian.Uint64(data[8:]))
+	data = data[16:]
+	n := binary.BigEndian.Uint64(data)
+	data = data[8:]
+	if n == 0 || len(data)%8 != 0 || uint64(len(data)/8) != n {
+		return errors.New("invalid bloom filter")
+	}
+	b.bits = make([]atomic.Uint64, n)
+	for i := range b.bits {
+		b.bits[i].Store(binary.BigEndian.Uint64(data[8*i:]))
+	}
+	return nil
+}
+
+// openBloom loads the bloom filter saved by Close, if it matches
+// the expected number of entries and the blobs in the store,
+// or else builds it from the blobs in the store.
+// Unless the cache is read-only, it removes the saved filter,
+// which would be stale if the cache weren't closed.
+func (c Cache) openBloom() (*bloom, error) {
+	entries, err := c.store.ListEntries()
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	b := newBloom(c.bloomEntries)
+	saved, err := c.readBlob(bloomName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		c.log(slog.LevelWarn, "error reading bloom filter", "error", err)
+	}
+	loaded := &bloom{}
+	if err == nil && loaded.UnmarshalBinary(saved) == nil && len(loaded.bits) == len(b.bits) &&
+		loaded.validator == validateBloom(entries) {
+		b = loaded
+	}
+	if err == nil && !c.readOnly {
+		err = c.store.RemoveEntry(bloomName)
+		if err != nil {
+			return nil, fmt.Errorf("error removing bloom filter: %w", err)
+		}
+	}
+	if b == loaded {
+		return b, nil
+	}
+	if err == nil {
+		c.log(slog.LevelInfo, "rebuilding stale bloom filter")
+	}
+	for _, entry := range entries {
+		b.add(entry.Name)
+	}
+	return b, nil
+}
+
+// saveBloom saves the bloom filter of the cache for the next open.
+func (c Cache) saveBloom() error {
+	if c.bloom == nil || c.readOnly {
+		return nil
+	}
+	entries, err := c.baseStore().ListEntries()
+	if err != nil {
+		return fmt.Errorf("error saving bloom filter: %w", err)
+	}
+	saved := &bloom{bits: c.bloom.bits, validator: validateBloom(entries)}
+	data, err := saved.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = c.baseStore().WriteEntry(bloomName, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error saving bloom filter: %w", err)
+	}
+	c.bloom.saved.Store(true)
+	return nil
+}
+
+// bloomStore is a store that skips reading blobs
+// that its bloom filter knows don't exist.
+type bloomStore struct {
+	Store
+	filter *bloom
+}
+
+// WriteEntry adds the name to the filter before writing the blob,
+// so the blob can be read as soon as it's written.
+// If the filter was saved by Close, the saved filter is removed first,
+// since it would be missing the blob.
+func (s bloomStore) WriteEntry(name string, r io.Reader) (int64, error) {
+	if s.filter.saved.Swap(false) {
+		err := s.Store.RemoveEntry(bloomName)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			s.filter.saved.Store(true)
+			return 0, fmt.Errorf("error removing bloom filter: %w", err)
+		}
+	}
+	s.filter.add(name)
+	return s.Store.WriteEntry(name, r)
+}
+
+// ReadEntry returns fs.ErrNotExist without reading the blob
+// if the filter knows it doesn't exist.
+func (s bloomStore) ReadEntry(name string) (io.ReadCloser, error) {
+	if !s.filter.mayContain(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return s.Store.ReadEntry(name)
+}
+
+// baseStore returns the store of the cache without its bloom filter.
+func (c Cache) baseStore() Store {
+	if s, ok := c.store.(bloomStore); ok {
+		return s.Store
+	}
+	return c.store
+}
@@ -0,0 +1,154 @@
+package diskcache_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestBloomFilter(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	opens := 0
+	fsys := &faultFS{fail: func(op string, name string) error {
+		if op == "open" && filepath.Ext(name) == ".json" {
+			opens++
+		}
+		return nil
+	}}
+	cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys), diskcache.WithBloomFilter(100))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.Set("present", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestMiss", func(t *testing.T) {
+		opens = 0
+		for i := range 100 {
+			_, err := cache.Get(fmt.Sprintf("missing%d", i))
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("Expected ErrNotExist, got %v", err)
+			}
+		}
+		if cache.Has("missing") {
+			t.Fatalf("Expected missing key to not exist")
+		}
+		if opens > 5 {
+			t.Fatalf("Expected few misses to open files, got %d", opens)
+		}
+	})
+
+	t.Run("TestHit", func(t *testing.T) {
+		value, err := cache.Get("present")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(value) != "value" {
+			t.Fatalf("Expected value, got %s", value)
+		}
+		if !cache.Has("present") {
+			t.Fatalf("Expected present key to exist")
+		}
+	})
+
+	t.Run("TestRebuild", func(t *testing.T) {
+		plain, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = plain.Set("other", []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		reopened, err := diskcache.New(cacheDir, diskcache.WithBloomFilter(100))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"present", "other"} {
+			if !reopened.Has(key) {
+				t.Fatalf("Expected %s to exist", key)
+			}
+		}
+	})
+
+	t.Run("TestPersist", func(t *testing.T) {
+		err := cache.Close()
+		if err != nil {
+			t.Fatalf("Error closing cache: %v", err)
+		}
+		// A write after Close invalidates the saved filter.
+		err = cache.Set("late", []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		reopened, err := diskcache.New(cacheDir, diskcache.WithBloomFilter(100))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		if !reopened.Has("late") {
+			t.Fatalf("Expected late to exist")
+		}
+		err = reopened.Close()
+		if err != nil {
+			t.Fatalf("Error closing cache: %v", err)
+		}
+		reopened, err = diskcache.New(cacheDir, diskcache.WithBloomFilter(100))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"present", "other", "late"} {
+			if !reopened.Has(key) {
+				t.Fatalf("Expected %s to exist", key)
+			}
+		}
+		keys, err := reopened.Keys()
+		if err != nil {
+			t.Fatalf("Error listing keys: %v", err)
+		}
+		if len(keys) != 3 {
+			t.Fatalf("Expected 3 keys, got %v", keys)
+		}
+	})
+
+	t.Run("TestStale", func(t *testing.T) {
+		closed, err := diskcache.New(cacheDir, diskcache.WithBloomFilter(100))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = closed.Close()
+		if err != nil {
+			t.Fatalf("Error closing cache: %v", err)
+		}
+		// A cache without the filter doesn't remove the saved filter.
+		plain, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = plain.Set("unfiltered", []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		reopened, err := diskcache.New(cacheDir, diskcache.WithBloomFilter(100))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		if !reopened.Has("unfiltered") {
+			t.Fatalf("Expected unfiltered to exist")
+		}
+	})
+
+	t.Run("TestInvalidSize", func(t *testing.T) {
+		_, err := diskcache.New(cacheDir, diskcache.WithBloomFilter(0))
+		if err == nil {
+			t.Fatalf("Expected error for invalid size, but got nil")
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package diskcache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// ErrCASMismatch is returned by CAS when the current value
+// doesn't equal the expected value.
+var ErrCASMismatch = errors.New("current value doesn't match expected value")
+
+// CAS saves a cache entry with a key, value, and duration,
+// only if its current value equals the expected value.
+// A nil expected value matches an entry that doesn't exist or is expired.
+// It keeps the metadata of the current entry.
+// It returns ErrCASMismatch if the values don't match.
+// CAS is atomic with respect to other CAS, Increment, and Append calls
+// on the cache and its copies and, if the cache supports Lock,
+// in other processes sharing the cache directory;
+// calling it while holding Lock on the key deadlocks.
+func (c Cache) CAS(key string, expected []byte, value []byte, duration time.Duration) error {
+	if len(key) == 0 {
+		return fmt.Errorf("key cannot be empty")
+	}
+	unlock, err := c.lockEntry(key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	current, ok, err := c.current(key)
+	if err != nil {
+		return err
+	}
+	if ok != (expected != nil) || !bytes.Equal(current.Value, expected) {
+		return ErrCASMismatch
+	}
+	// The key is already locked, so save it without locking it again.
+	done := c.observe("Set", key)
+	err = c.setLocked(key, value, current.Meta, duration)
+	done(OperationResult{Bytes: len(value), Err: err})
+	return err
+}
+
+// current reads the current record of a cache entry,
+// and returns false if it doesn't exist or is expired.
+func (c Cache) current(key string) (record, bool, error) {
+	rec, err := c.readDecoded(c.Filename(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return record{}, false, nil
+	}
+	if err != nil {
+		return record{}, false, fmt.Errorf("error reading entry: %w", err)
+	}
+	if expired(rec.Expiry, c.now()) {
+		return record{}, false, nil
+	}
+	return rec, true, nil
+}
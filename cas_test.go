@@ -0,0 +1,129 @@
+package diskcache_test
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestCAS(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "testkey"
+
+	t.Run("TestAbsent", func(t *testing.T) {
+		err := cache.CAS(key, []byte("other"), []byte("first"), 1*time.Minute)
+		if !errors.Is(err, diskcache.ErrCASMismatch) {
+			t.Fatalf("Expected ErrCASMismatch, got %v", err)
+		}
+		err = cache.CAS(key, nil, []byte("first"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error swapping cache: %v", err)
+		}
+	})
+
+	t.Run("TestPresent", func(t *testing.T) {
+		err := cache.CAS(key, nil, []byte("second"), 1*time.Minute)
+		if !errors.Is(err, diskcache.ErrCASMismatch) {
+			t.Fatalf("Expected ErrCASMismatch, got %v", err)
+		}
+		err = cache.CAS(key, []byte("first"), []byte("second"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error swapping cache: %v", err)
+		}
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "second" {
+			t.Fatalf("Expected cache value to be second, got %s", got)
+		}
+	})
+
+	t.Run("TestConcurrent", func(t *testing.T) {
+		counter := "counter"
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					current, err := cache.Get(counter)
+					var n int
+					if err == nil {
+						n, _ = strconv.Atoi(string(current))
+					} else {
+						current = nil
+					}
+					err = cache.CAS(counter, current, []byte(strconv.Itoa(n+1)), 1*time.Minute)
+					if err == nil {
+						return
+					}
+					if !errors.Is(err, diskcache.ErrCASMismatch) {
+						t.Errorf("Error swapping cache: %v", err)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		got, err := cache.Get(counter)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "10" {
+			t.Fatalf("Expected counter to be 10, got %s", got)
+		}
+	})
+
+	t.Run("TestSeparateCaches", func(t *testing.T) {
+		// Caches opened separately share only the directory,
+		// like caches in different processes.
+		other, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		caches := []diskcache.Cache{cache, other}
+		counter := "shared"
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func(c diskcache.Cache) {
+				defer wg.Done()
+				for {
+					current, err := c.Get(counter)
+					var n int
+					if err == nil {
+						n, _ = strconv.Atoi(string(current))
+					} else {
+						current = nil
+					}
+					err = c.CAS(counter, current, []byte(strconv.Itoa(n+1)), 1*time.Minute)
+					if err == nil {
+						return
+					}
+					if !errors.Is(err, diskcache.ErrCASMismatch) {
+						t.Errorf("Error swapping cache: %v", err)
+						return
+					}
+				}
+			}(caches[i%2])
+		}
+		wg.Wait()
+		got, err := cache.Get(counter)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "20" {
+			t.Fatalf("Expected counter to be 20, got %s", got)
+		}
+	})
+}
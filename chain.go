@@ -0,0 +1,119 @@
+package diskcache
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+// Chained is a cache that reads through a primary cache to fallback caches,
+// such as a shared seed cache on a network file system
+// in front of which each machine keeps a local cache.
+// Entries found in a fallback are promoted into the primary,
+// keeping their expiry, so the next Get reads them locally.
+// Set and Remove change only the primary, and the fallbacks are
+// never written, so they can be opened read-only.
+type Chained struct {
+	primary   Cache
+	fallbacks []Cache
+}
+
+// Chain returns a Chained that reads from a primary cache first,
+// then from the fallbacks in order.
+func Chain(primary Cache, fallbacks ...Cache) Chained {
+	return Chained{primary: primary, fallbacks: fallbacks}
+}
+
+// Primary returns the primary cache of the chain.
+func (ch Chained) Primary() Cache {
+	return ch.primary
+}
+
+// Get returns the value of a cache entry from the primary,
+// or else from the first fallback that has it unexpired,
+// promoting it into the primary.
+// It returns the error of the primary if no cache has the entry.
+func (ch Chained) Get(key string) ([]byte, error) {
+	value, _, err := ch.GetWithTTL(key)
+	return value, err
+}
+
+// GetWithTTL returns the value of a cache entry and its remaining lifetime,
+// from the primary or else from a fallback, like Get.
+// The lifetime is NoExpiry if the entry never expires.
+func (ch Chained) GetWithTTL(key string) ([]byte, time.Duration, error) {
+	value, ttl, err := ch.primary.GetWithTTL(key)
+	if !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, ErrExpired) {
+		return value, ttl, err
+	}
+	for _, fallback := range ch.fallbacks {
+		entry, ok := ch.fallback(fallback, key)
+		if !ok {
+			continue
+		}
+		ch.promote(entry)
+		if entry.Expiry.IsZero() {
+			return entry.Value, NoExpiry, nil
+		}
+		return entry.Value, entry.Expiry.Sub(ch.primary.now()), nil
+	}
+	return nil, 0, err
+}
+
+// fallback reads an unexpired cache entry from a fallback cache.
+// Errors other than a missing entry are logged, not returned,
+// so an unavailable fallback doesn't fail the read.
+func (ch Chained) fallback(fallback Cache, key string) (Data, bool) {
+	entry, err := fallback.Read(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Data{}, false
+	}
+	if err != nil {
+		ch.primary.log(slog.LevelWarn, "error reading fallback", "key", key, "dir", fallback.dir, "error", err)
+		return Data{}, false
+	}
+	if expired(entry.Expiry, ch.primary.now()) {
+		return Data{}, false
+	}
+	return entry, true
+}
+
+// promote saves an entry read from a fallback into the primary.
+// Errors are logged, since the entry was read anyway.
+func (ch Chained) promote(entry Data) {
+	entry.LastAccessed = time.Time{}
+	err := ch.primary.write(entry)
+	if err == nil {
+		err = ch.primary.evict(entry.Key)
+	}
+	if err != nil {
+		ch.primary.log(slog.LevelWarn, "error promoting entry", "key", entry.Key, "error", err)
+		return
+	}
+	ch.primary.log(slog.LevelDebug, "promoted entry", "key", entry.Key)
+}
+
+// Has returns true if the primary or a fallback has a cache entry.
+func (ch Chained) Has(key string) bool {
+	if ch.primary.Has(key) {
+		return true
+	}
+	for _, fallback := range ch.fallbacks {
+		if fallback.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Set saves a cache entry in the primary.
+func (ch Chained) Set(key string, value []byte, duration time.Duration) error {
+	return ch.primary.Set(key, value, duration)
+}
+
+// Remove deletes a cache entry from the primary.
+// The entry is read from a fallback again by the next Get, if one has it.
+func (ch Chained) Remove(key string) error {
+	return ch.primary.Remove(key)
+}
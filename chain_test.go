@@ -0,0 +1,109 @@
+package diskcache_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestChain(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newCache := func() diskcache.Cache {
+		t.Helper()
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithClock(clock))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		return cache
+	}
+	primary := newCache()
+	seed := newCache()
+	shared := newCache()
+	for key, cache := range map[string]diskcache.Cache{"local": primary, "seed": seed, "shared": shared} {
+		err := cache.Set(key, []byte(key), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	err := seed.Set("expired", []byte("seed"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = shared.Set("expired", []byte("shared"), 1*time.Hour)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	readOnly, err := diskcache.New(seed.Dir(), diskcache.WithReadOnly(), diskcache.WithClock(clock))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	chain := diskcache.Chain(primary, readOnly, shared)
+	clock.Advance(2 * time.Minute)
+
+	t.Run("TestPrimary", func(t *testing.T) {
+		value, err := chain.Get("local")
+		if err != nil || string(value) != "local" {
+			t.Fatalf("Expected local, got %s, %v", value, err)
+		}
+	})
+
+	t.Run("TestFallback", func(t *testing.T) {
+		for _, key := range []string{"seed", "shared"} {
+			value, ttl, err := chain.GetWithTTL(key)
+			if err != nil || string(value) != key {
+				t.Fatalf("Expected %s, got %s, %v", key, value, err)
+			}
+			if ttl != 58*time.Minute {
+				t.Fatalf("Expected 58m, got %v", ttl)
+			}
+		}
+	})
+
+	t.Run("TestPromote", func(t *testing.T) {
+		value, ttl, err := primary.GetWithTTL("seed")
+		if err != nil || string(value) != "seed" {
+			t.Fatalf("Expected seed to be promoted, got %s, %v", value, err)
+		}
+		if ttl != 58*time.Minute {
+			t.Fatalf("Expected the expiry to be kept, got %v", ttl)
+		}
+	})
+
+	t.Run("TestSkipExpired", func(t *testing.T) {
+		value, err := chain.Get("expired")
+		if err != nil || string(value) != "shared" {
+			t.Fatalf("Expected shared, got %s, %v", value, err)
+		}
+	})
+
+	t.Run("TestMiss", func(t *testing.T) {
+		_, err := chain.Get("missing")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected ErrNotExist, got %v", err)
+		}
+		if chain.Has("missing") {
+			t.Fatalf("Expected missing key to not exist")
+		}
+	})
+
+	t.Run("TestSetAndRemove", func(t *testing.T) {
+		err := chain.Set("new", []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		if !primary.Has("new") || shared.Has("new") {
+			t.Fatalf("Expected new to be saved only in the primary")
+		}
+		err = chain.Remove("shared")
+		if err != nil {
+			t.Fatalf("Error removing cache: %v", err)
+		}
+		if primary.Has("shared") || !chain.Has("shared") {
+			t.Fatalf("Expected shared to be removed only from the primary")
+		}
+	})
+}
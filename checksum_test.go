@@ -0,0 +1,94 @@
+package diskcache_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestVerify(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, key := range []string{"good", "bad"} {
+		err = cache.Set(key, []byte("value of "+key), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	tamper(t, cache.Filepath("bad"))
+
+	t.Run("TestVerify", func(t *testing.T) {
+		err := cache.Verify("good")
+		if err != nil {
+			t.Fatalf("Error verifying cache: %v", err)
+		}
+		err = cache.Verify("bad")
+		if !errors.Is(err, diskcache.ErrCorrupt) {
+			t.Fatalf("Expected ErrCorrupt, got %v", err)
+		}
+		err = cache.Verify("missing")
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("Expected ErrNotExist, got %v", err)
+		}
+	})
+
+	t.Run("TestVerifyAll", func(t *testing.T) {
+		err := cache.VerifyAll()
+		if !errors.Is(err, diskcache.ErrCorrupt) {
+			t.Fatalf("Expected ErrCorrupt, got %v", err)
+		}
+	})
+
+	t.Run("TestGetWithoutVerify", func(t *testing.T) {
+		_, err := cache.Get("bad")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+	})
+
+	t.Run("TestGetWithVerify", func(t *testing.T) {
+		verified, err := diskcache.New(cacheDir, diskcache.WithVerify())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		_, err = verified.Get("good")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		_, err = verified.Get("bad")
+		if !errors.Is(err, diskcache.ErrCorrupt) {
+			t.Fatalf("Expected ErrCorrupt, got %v", err)
+		}
+	})
+}
+
+// tamper replaces the value of an entry file without updating its checksum.
+func tamper(t *testing.T, filepath string) {
+	t.Helper()
+	contents, err := os.ReadFile(filepath)
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	var rec map[string]any
+	err = json.Unmarshal(contents, &rec)
+	if err != nil {
+		t.Fatalf("Error unmarshaling file: %v", err)
+	}
+	rec["Value"] = []byte("tampered")
+	contents, err = json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Error marshaling file: %v", err)
+	}
+	err = os.WriteFile(filepath, contents, 0644)
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package diskcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CleanReport describes the entries Clean would remove.
+type CleanReport struct {
+	// Entries is the expired entries, sorted by key.
+	Entries []ExpiredEntry
+	// Bytes is the total size in bytes of the expired entries.
+	Bytes int64
+}
+
+// ExpiredEntry is an expired cache entry.
+type ExpiredEntry struct {
+	Key    string
+	Expiry time.Time
+	// Size is the size in bytes of the entry, including its sidecar, if any.
+	Size int64
+	// ExpiredFor is how long ago the entry expired.
+	ExpiredFor time.Duration
+}
+
+// CleanReport returns a report of the expired entries Clean would remove,
+// without removing them.
+// If the cache is indexed, it finds expired entries from the index.
+// It works on read-only caches.
+func (c Cache) CleanReport() (CleanReport, error) {
+	now := c.now()
+	var entries []ExpiredEntry
+	if c.index != nil {
+		entries = c.index.expiredEntries(now)
+	} else {
+		var err error
+		entries, err = c.expiredEntries(now)
+		if err != nil {
+			return CleanReport{}, err
+		}
+	}
+	slices.SortFunc(entries, func(a, b ExpiredEntry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	report := CleanReport{Entries: entries}
+	for _, entry := range entries {
+		report.Bytes += entry.Size
+	}
+	return report, nil
+}
+
+// expiredEntries reads the expired cache entries from the directory.
+// Entries removed while reading are skipped.
+func (c Cache) expiredEntries(now time.Time) ([]ExpiredEntry, error) {
+	filenames, err := c.readDir()
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	sizes, err := c.sizes()
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	var entries []ExpiredEntry
+	for _, filename := range filenames {
+		data, err := c.readMeta(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !expired(data.Expiry, now) {
+			continue
+		}
+		entries = append(entries, ExpiredEntry{
+			Key:        data.Key,
+			Expiry:     data.Expiry,
+			Size:       sizes[filename],
+			ExpiredFor: now.Sub(data.Expiry),
+		})
+	}
+	return entries, nil
+}
+
+// DefaultMaxParallel is the number of entries Clean removes
+// and Warm loads at a time.
+const DefaultMaxParallel = 16
+
+// CleanOption configures CleanContext.
+type CleanOption func(*cleanConfig)
+
+// cleanConfig is the configuration of a clean.
+type cleanConfig struct {
+	maxParallel int
+}
+
+// WithMaxParallel sets the maximum number of entries
+// CleanContext removes at a time.
+// A value of zero or less uses DefaultMaxParallel.
+func WithMaxParallel(n int) CleanOption {
+	return func(cfg *cleanConfig) {
+		cfg.maxParallel = n
+	}
+}
+
+// CleanContext deletes expired cache entries from disk, like Clean.
+// It stops removing entries when the context is done,
+// waits for the removals in progress, and returns an error
+// wrapping the context error along with any removal errors.
+// The context is also passed to the operation hook and logger.
+func (c Cache) CleanContext(ctx context.Context, options ...CleanOption) error {
+	cfg := cleanConfig{maxParallel: DefaultMaxParallel}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if cfg.maxParallel <= 0 {
+		cfg.maxParallel = DefaultMaxParallel
+	}
+	c = c.WithContext(ctx)
+	done := c.observe("Clean", "")
+	start := time.Now()
+	err := c.clean(ctx, cfg.maxParallel)
+	c.stats.clean(time.Since(start))
+	done(OperationResult{Err: err})
+	return err
+}
+
+// clean deletes expired cache entries from disk,
+// removing up to maxParallel entries at a time,
+// until the context is done.
+func (c Cache) clean(ctx context.Context, maxParallel int) error {
+	if c.readOnly {
+		return errReadOnly("clean", c.dir)
+	}
+	keys, err := c.expiredKeys()
+	if err != nil {
+		return err
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+	sem := make(chan struct{}, maxParallel)
+loop:
+	for _, key := range keys {
+		// Check the context first, since select picks randomly
+		// when a slot is also free.
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := c.remove(key, true)
+			if err != nil {
+				c.log(slog.LevelWarn, "error removing expired entry", "key", key, "error", err)
+				mu.Lock()
+				errs = errors.Join(errs, err)
+				mu.Unlock()
+			}
+		}(key)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		errs = errors.Join(fmt.Errorf("clean canceled: %w", err), errs)
+	}
+	return errs
+}
@@ -0,0 +1,186 @@
+package diskcache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestCleanReport(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []diskcache.Option
+	}{
+		{"TestDirectory", nil},
+		{"TestIndex", []diskcache.Option{diskcache.WithIndex()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), tc.options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			err = cache.Set("current", []byte("value"), diskcache.NoExpiry)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			for _, key := range []string{"b", "a"} {
+				err = cache.Set(key, []byte("value"), time.Nanosecond)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+			time.Sleep(time.Millisecond)
+			size, err := cache.Size()
+			if err != nil {
+				t.Fatalf("Error getting size: %v", err)
+			}
+			report, err := cache.CleanReport()
+			if err != nil {
+				t.Fatalf("Error reporting clean: %v", err)
+			}
+			if len(report.Entries) != 2 {
+				t.Fatalf("Expected 2 entries, got %d", len(report.Entries))
+			}
+			if report.Entries[0].Key != "a" || report.Entries[1].Key != "b" {
+				t.Fatalf("Expected entries a and b, got %s and %s", report.Entries[0].Key, report.Entries[1].Key)
+			}
+			for _, entry := range report.Entries {
+				if entry.Size <= 0 {
+					t.Fatalf("Expected positive size, got %d", entry.Size)
+				}
+				if entry.ExpiredFor <= 0 {
+					t.Fatalf("Expected positive expired duration, got %v", entry.ExpiredFor)
+				}
+				if entry.Expiry.IsZero() {
+					t.Fatalf("Expected expiry for %s", entry.Key)
+				}
+			}
+			if report.Bytes != report.Entries[0].Size+report.Entries[1].Size {
+				t.Fatalf("Expected bytes to be the sum of the sizes, got %d", report.Bytes)
+			}
+			if report.Bytes >= size {
+				t.Fatalf("Expected bytes less than cache size %d, got %d", size, report.Bytes)
+			}
+			// The report doesn't remove anything.
+			list, err := cache.List()
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			if len(list) != 3 {
+				t.Fatalf("Expected 3 entries, got %d", len(list))
+			}
+			err = cache.Clean()
+			if err != nil {
+				t.Fatalf("Error cleaning cache: %v", err)
+			}
+			report, err = cache.CleanReport()
+			if err != nil {
+				t.Fatalf("Error reporting clean: %v", err)
+			}
+			if len(report.Entries) != 0 || report.Bytes != 0 {
+				t.Fatalf("Expected empty report, got %+v", report)
+			}
+		})
+	}
+}
+
+func TestCleanContext(t *testing.T) {
+	// newExpiredCache returns a cache on fsys with n expired entries.
+	newExpiredCache := func(t *testing.T, fsys diskcache.FS, n int) diskcache.Cache {
+		t.Helper()
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for i := range n {
+			err = cache.Set(fmt.Sprintf("key%d", i), []byte("value"), time.Nanosecond)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return cache
+	}
+
+	t.Run("TestMaxParallel", func(t *testing.T) {
+		var active, peak atomic.Int64
+		fsys := &faultFS{}
+		cache := newExpiredCache(t, fsys, 20)
+		fsys.fail = func(op string, _ string) error {
+			if op == "remove" {
+				n := active.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				active.Add(-1)
+			}
+			return nil
+		}
+		err := cache.CleanContext(context.Background(), diskcache.WithMaxParallel(2))
+		if err != nil {
+			t.Fatalf("Error cleaning cache: %v", err)
+		}
+		if peak.Load() > 2 {
+			t.Fatalf("Expected at most 2 parallel removals, got %d", peak.Load())
+		}
+		list, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 0 {
+			t.Fatalf("Expected 0 entries, got %d", len(list))
+		}
+	})
+
+	t.Run("TestCanceled", func(t *testing.T) {
+		cache := newExpiredCache(t, &faultFS{}, 3)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := cache.CleanContext(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		list, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 3 {
+			t.Fatalf("Expected 3 entries, got %d", len(list))
+		}
+	})
+
+	t.Run("TestCanceledDuringClean", func(t *testing.T) {
+		fsys := &faultFS{}
+		cache := newExpiredCache(t, fsys, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		var once sync.Once
+		fsys.fail = func(op string, _ string) error {
+			if op == "remove" {
+				once.Do(cancel)
+			}
+			return nil
+		}
+		err := cache.CleanContext(ctx, diskcache.WithMaxParallel(1))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		list, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) == 0 || len(list) == 10 {
+			t.Fatalf("Expected some entries to remain, got %d", len(list))
+		}
+	})
+}
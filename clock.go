@@ -0,0 +1,36 @@
+package diskcache
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock tells the time.
+// The cache uses it to compute expiry and creation times
+// and to check whether entries are expired.
+type Clock interface {
+	Now() time.Time
+}
+
+// WithClock sets the clock of the cache, such as a fake clock
+// that tests advance instead of waiting for entries to expire.
+// The default clock is the system clock.
+// The janitor and Watch still run on real time,
+// but check expiry against the clock.
+func WithClock(clock Clock) Option {
+	return func(c *Cache) {
+		if clock == nil {
+			c.err = errors.New("clock is nil")
+			return
+		}
+		c.clock = clock
+	}
+}
+
+// now returns the current time from the clock of the cache.
+func (c Cache) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
@@ -0,0 +1,83 @@
+package diskcache_test
+
+import (
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// fakeClock is a clock that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func TestClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithClock(clock))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.Set("key", []byte("value"), 1*time.Hour)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestExpiry", func(t *testing.T) {
+		want := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+		if !cache.Expiry("key").Equal(want) {
+			t.Fatalf("Expected expiry %s, got %s", want, cache.Expiry("key"))
+		}
+		_, ttl, err := cache.GetWithTTL("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if ttl != 1*time.Hour {
+			t.Fatalf("Expected TTL of 1h, got %v", ttl)
+		}
+	})
+
+	t.Run("TestAdvance", func(t *testing.T) {
+		clock.Advance(59 * time.Minute)
+		if cache.IsExpired("key") {
+			t.Fatalf("Expected cache to not be expired")
+		}
+		clock.Advance(2 * time.Minute)
+		if !cache.IsExpired("key") {
+			t.Fatalf("Expected cache to be expired")
+		}
+		_, err := cache.Get("key")
+		if err == nil {
+			t.Fatalf("Expected error getting expired cache")
+		}
+		err = cache.Clean()
+		if err != nil {
+			t.Fatalf("Error cleaning cache: %v", err)
+		}
+		if cache.Has("key") {
+			t.Fatalf("Expected expired entry to be removed")
+		}
+	})
+
+	t.Run("TestNilClock", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithClock(nil))
+		if err == nil {
+			t.Fatalf("Expected error for nil clock")
+		}
+	})
+}
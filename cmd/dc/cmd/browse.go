@@ -0,0 +1,251 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jluckyiv/diskcache"
+	"github.com/spf13/cobra"
+)
+
+// browseCmd represents the browse command
+var browseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Browse the cache interactively",
+	Long: `Browse the entries in the cache interactively.
+
+Type / to search the keys, and move with the arrow keys or j and k.
+The value of the selected entry is previewed below the list.
+Press d to delete the selected entry, t to touch it, extending its
+expiry by --touch-duration, r to reload the entries, and q to quit.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if porcelain {
+			cobra.CheckErr(errors.New("browse is interactive and can't be used with --porcelain"))
+		}
+		touchDuration := durationFlag(cmd, "touch-duration")
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		m := newBrowseModel(cache, touchDuration)
+		_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+		cobra.CheckErr(err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(browseCmd)
+	browseCmd.Flags().DurationP("touch-duration", "d", 1*time.Hour, "Duration to extend an entry by when touched (0 never expires)")
+}
+
+// previewHeight is the number of lines of the value preview.
+const previewHeight = 8
+
+// browseKeys are the keys of the browse command,
+// in addition to those of the list.
+var browseKeys = struct {
+	delete, touch, refresh key.Binding
+}{
+	delete:  key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+	touch:   key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "touch")),
+	refresh: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+}
+
+// browseItem is an entry in the list of the browse command.
+type browseItem struct {
+	diskcache.EntryInfo
+}
+
+func (i browseItem) Title() string {
+	return i.Key
+}
+
+func (i browseItem) Description() string {
+	return fmt.Sprintf("%s, %d bytes", describeExpiry(i.Expiry), i.Size)
+}
+
+func (i browseItem) FilterValue() string {
+	return i.Key
+}
+
+// describeExpiry describes an expiry time relative to now.
+func describeExpiry(expiry time.Time) string {
+	switch {
+	case expiry.IsZero():
+		return "never expires"
+	case time.Now().After(expiry):
+		return fmt.Sprintf("expired %s ago", time.Since(expiry).Round(time.Second))
+	default:
+		return fmt.Sprintf("expires in %s", time.Until(expiry).Round(time.Second))
+	}
+}
+
+// browseModel is the state of the browse command.
+type browseModel struct {
+	cache         diskcache.Cache
+	touchDuration time.Duration
+	list          list.Model
+	width         int
+	// previewKey is the key whose value is in preview.
+	previewKey string
+	preview    string
+}
+
+// newBrowseModel returns the state of the browse command for a cache.
+func newBrowseModel(cache diskcache.Cache, touchDuration time.Duration) browseModel {
+	l := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "dc browse " + cacheDir
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{browseKeys.delete, browseKeys.touch, browseKeys.refresh}
+	}
+	l.AdditionalFullHelpKeys = l.AdditionalShortHelpKeys
+	return browseModel{cache: cache, touchDuration: touchDuration, list: l}
+}
+
+// entriesMsg is the result of reading the entries of the cache.
+type entriesMsg struct {
+	items []list.Item
+	err   error
+}
+
+// loadEntries reads the keys, expiries, and sizes of the entries.
+func (m browseModel) loadEntries() tea.Msg {
+	infos, err := m.cache.ListInfo()
+	if err != nil {
+		return entriesMsg{err: err}
+	}
+	items := make([]list.Item, len(infos))
+	for i, info := range infos {
+		items[i] = browseItem{info}
+	}
+	return entriesMsg{items: items}
+}
+
+func (m browseModel) Init() tea.Cmd {
+	return m.loadEntries
+}
+
+func (m browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.list.SetSize(msg.Width, max(msg.Height-previewHeight-1, 0))
+	case entriesMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage("Error: " + msg.err.Error())
+		}
+		cmd := m.list.SetItems(msg.items)
+		// Keep the selection in the list when entries were removed.
+		if n := len(m.list.VisibleItems()); m.list.Index() >= n && n > 0 {
+			m.list.Select(n - 1)
+		}
+		m.previewKey = ""
+		return m.updatePreview(), cmd
+	case tea.KeyMsg:
+		if m.list.SettingFilter() {
+			break
+		}
+		item, selected := m.list.SelectedItem().(browseItem)
+		switch {
+		case key.Matches(msg, browseKeys.refresh):
+			return m, m.loadEntries
+		case key.Matches(msg, browseKeys.delete) && selected:
+			err := m.cache.Remove(item.Key)
+			if err != nil {
+				return m, m.list.NewStatusMessage("Error: " + err.Error())
+			}
+			return m, tea.Batch(m.loadEntries, m.list.NewStatusMessage("Deleted "+item.Key))
+		case key.Matches(msg, browseKeys.touch) && selected:
+			err := m.cache.Touch(item.Key, m.touchDuration)
+			if err != nil {
+				return m, m.list.NewStatusMessage("Error: " + err.Error())
+			}
+			return m, tea.Batch(m.loadEntries, m.list.NewStatusMessage("Touched "+item.Key))
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m.updatePreview(), cmd
+}
+
+// updatePreview reads the value of the selected entry,
+// if it isn't already in preview.
+func (m browseModel) updatePreview() browseModel {
+	item, ok := m.list.SelectedItem().(browseItem)
+	if !ok {
+		m.previewKey, m.preview = "", ""
+		return m
+	}
+	if item.Key == m.previewKey {
+		return m
+	}
+	m.previewKey = item.Key
+	entry, err := m.cache.Read(item.Key)
+	if err != nil {
+		m.preview = "Error: " + err.Error()
+		return m
+	}
+	m.preview = previewValue(entry.Value, max(m.width, 1), previewHeight-1)
+	return m
+}
+
+// previewValue returns the first lines of a value, cut to a width,
+// with control characters and invalid UTF-8 shown as dots.
+func previewValue(value []byte, width int, lines int) string {
+	var b strings.Builder
+	for i, line := range strings.SplitN(string(value), "\n", lines+1) {
+		if i == lines {
+			break
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		n := 0
+		for len(line) > 0 && n < width {
+			r, size := utf8.DecodeRuneInString(line)
+			line = line[size:]
+			if r == utf8.RuneError || unicode.IsControl(r) {
+				r = '.'
+			}
+			b.WriteRune(r)
+			n++
+		}
+	}
+	return b.String()
+}
+
+// previewStyle is the style of the value preview.
+var previewStyle = lipgloss.NewStyle().
+	Border(lipgloss.NormalBorder(), true, false, false, false).
+	Height(previewHeight - 1)
+
+func (m browseModel) View() string {
+	return lipgloss.JoinVertical(lipgloss.Left, m.list.View(), previewStyle.Width(m.width).Render(m.preview))
+}
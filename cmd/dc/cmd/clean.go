@@ -23,8 +23,8 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/jluckyiv/diskcache"
 	"github.com/spf13/cobra"
 )
 
@@ -33,14 +33,33 @@ var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "clean the cache (expired entries)",
 	Run: func(cmd *cobra.Command, args []string) {
-		cache, err := diskcache.New(cacheDir)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		cache, err := openCache()
 		cobra.CheckErr(err)
+		if dryRun {
+			report, err := cache.CleanReport()
+			cobra.CheckErr(err)
+			for _, entry := range report.Entries {
+				if porcelain {
+					printFields(entry.Key, entry.Size, entry.ExpiredFor)
+					continue
+				}
+				fmt.Printf("%s %d bytes, expired %s ago\n", entry.Key, entry.Size, entry.ExpiredFor.Round(time.Second))
+			}
+			if !porcelain {
+				fmt.Printf("Would remove %d entries, %d bytes\n", len(report.Entries), report.Bytes)
+			}
+			return
+		}
 		err = cache.Clean()
 		cobra.CheckErr(err)
-		fmt.Println("Cache cleaned")
+		if !porcelain {
+			fmt.Println("Cache cleaned")
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(cleanCmd)
+	cleanCmd.Flags().BoolP("dry-run", "n", false, "Report the expired entries without removing them")
 }
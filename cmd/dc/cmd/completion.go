@@ -0,0 +1,60 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/spf13/cobra"
+)
+
+// completeKeys completes a key flag with the keys in the cache
+// that start with what was typed.
+// The cache is opened with a manifest, so the keys are read from
+// one small file instead of every entry file, and the manifest
+// is brought up to date with the directory on each completion.
+// If the cache directory doesn't exist, there are no completions.
+func completeKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Cobra runs initConfig before it parses the flags of the command
+	// being completed, so read the config again for --config and --profile.
+	initConfig()
+	if _, err := os.Stat(cacheDir); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cache, err := openCache(diskcache.WithManifest())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	keys, err := cache.Keys()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []string
+	for _, key := range keys {
+		if strings.HasPrefix(key, toComplete) {
+			completions = append(completions, key)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/spf13/cobra"
+)
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Maintain the cache periodically",
+	Long: `Maintain the cache until interrupted, removing the expired entries
+on an interval and, with --max-bytes, the oldest entries until the cache
+fits in a size budget. Sizes may have a K, M, G, or T suffix (powers of 1024).
+
+  dc daemon --clean-every 10m --max-bytes 2G --log-file ~/dc.log
+
+Run it in the background with a service manager, or with nohup or &.
+The log goes to standard error unless --log-file is set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		every, _ := cmd.Flags().GetDuration("clean-every")
+		maxBytesFlag, _ := cmd.Flags().GetString("max-bytes")
+		logFile, _ := cmd.Flags().GetString("log-file")
+		if every <= 0 {
+			cobra.CheckErr(fmt.Errorf("invalid interval %v: must be positive", every))
+		}
+		var maxBytes int64
+		if maxBytesFlag != "" {
+			var err error
+			maxBytes, err = parseSize(maxBytesFlag)
+			cobra.CheckErr(err)
+		}
+		var w io.Writer = os.Stderr
+		if logFile != "" {
+			f, err := os.OpenFile(expandHome(logFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			cobra.CheckErr(err)
+			defer f.Close()
+			w = f
+		}
+		logger := slog.New(slog.NewTextHandler(w, nil))
+		cache, err := openCache(diskcache.WithLogger(logger))
+		cobra.CheckErr(err)
+		policy := diskcache.PrunePolicy{MaxBytes: maxBytes}
+		logger.Info("started daemon", "dir", cacheDir, "interval", every, "max_bytes", maxBytes)
+		maintain(cache, policy, logger)
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		for {
+			select {
+			case <-ticker.C:
+				maintain(cache, policy, logger)
+			case sig := <-stop:
+				logger.Info("stopped daemon", "signal", sig.String())
+				return
+			}
+		}
+	},
+}
+
+// maintain prunes the cache to the policy, logging what it removed.
+func maintain(cache diskcache.Cache, policy diskcache.PrunePolicy, logger *slog.Logger) {
+	start := time.Now()
+	report, err := cache.Prune(policy)
+	if err != nil {
+		logger.Error("error pruning cache", "error", err)
+	}
+	logger.Info("pruned cache", "removed", len(report.Removed), "bytes", report.Bytes, "took", time.Since(start))
+}
+
+// parseSize parses a size in bytes, with an optional K, M, G, or T suffix
+// for powers of 1024, optionally followed by B or iB, such as 2G or 512MiB.
+func parseSize(s string) (int64, error) {
+	number := strings.TrimSpace(s)
+	number = strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(number), "B"), "I")
+	multiplier := int64(1)
+	if i := strings.IndexAny(number, "KMGT"); i >= 0 && i == len(number)-1 {
+		multiplier = 1 << (10 * (strings.IndexByte("KMGT", number[i]) + 1))
+		number = number[:i]
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.Flags().Duration("clean-every", 10*time.Minute, "Interval to remove expired entries on")
+	daemonCmd.Flags().String("max-bytes", "", "Size budget of the cache, such as 2G (default is no budget)")
+	daemonCmd.Flags().String("log-file", "", "File to append the log to")
+}
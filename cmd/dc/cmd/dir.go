@@ -0,0 +1,47 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// dirCmd represents the dir command
+var dirCmd = &cobra.Command{
+	Use:   "dir",
+	Short: "Print the cache directory",
+	Long: `Print the cache directory, which is, in order of precedence:
+
+  the DC_CACHE_DIR environment variable,
+  the cache_dir setting of the profile or config file,
+  or the dc subdirectory of the user's cache directory,
+  such as $XDG_CACHE_HOME/dc or ~/.cache/dc on Linux.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(cacheDir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dirCmd)
+}
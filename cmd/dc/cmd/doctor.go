@@ -0,0 +1,115 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the cache directory for bad entry files",
+	Long: `Check every entry file in the cache directory, reporting files that
+can't be read, such as for lack of permission, files that are empty,
+files that don't decode or fail their checksum, and files whose name
+doesn't match the key they hold.
+
+Exits 1 if there are problems that weren't fixed.
+
+With --fix, bad files are moved to a quarantine directory next to
+the cache directory, or removed with --delete.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fix, _ := cmd.Flags().GetBool("fix")
+		del, _ := cmd.Flags().GetBool("delete")
+		quarantine, _ := cmd.Flags().GetString("quarantine-dir")
+		if quarantine == "" {
+			quarantine = filepath.Clean(cacheDir) + ".quarantine"
+		}
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		problems, err := cache.Diagnose()
+		cobra.CheckErr(err)
+		if len(problems) == 0 {
+			if !porcelain {
+				fmt.Println("No problems found")
+			}
+			return
+		}
+		unfixed, quarantined := 0, false
+		for _, p := range problems {
+			action, err := "", error(nil)
+			switch {
+			case !fix:
+				unfixed++
+			case del:
+				action, err = "removed", cache.Discard(p)
+			default:
+				action, err = "quarantined", cache.Quarantine(p, quarantine)
+				quarantined = quarantined || err == nil
+			}
+			if err != nil {
+				action = "failed"
+				unfixed++
+			}
+			if porcelain {
+				var errString string
+				if p.Err != nil {
+					errString = p.Err.Error()
+				}
+				printFields(p.Filename, p.Kind, p.Key, errString, action)
+				continue
+			}
+			line := fmt.Sprintf("%s: %s", p.Filename, p.Kind)
+			switch {
+			case p.Key != "":
+				line += fmt.Sprintf(" (holds key %s)", p.Key)
+			case p.Err != nil:
+				line += fmt.Sprintf(" (%v)", p.Err)
+			}
+			switch {
+			case err != nil:
+				line += fmt.Sprintf(", not fixed: %v", err)
+			case action != "":
+				line += ", " + action
+			}
+			fmt.Println(line)
+		}
+		if quarantined && !porcelain {
+			fmt.Println("Quarantined files are in", quarantine)
+		}
+		if unfixed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().Bool("fix", false, "Quarantine the bad files")
+	doctorCmd.Flags().Bool("delete", false, "With --fix, remove the bad files instead of quarantining them")
+	doctorCmd.Flags().String("quarantine-dir", "", "Directory to quarantine bad files in (default is the cache directory with a .quarantine suffix)")
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// duCmd represents the du command
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Report the disk usage of the cache",
+	Long: `Report the number and size of the entries in the cache,
+and how many of them, and how much of the size, are expired.
+
+With --by-prefix, the report breaks the usage down by key prefix,
+with a row for each prefix and one for the keys that match none.
+A key that matches more than one prefix counts toward the longest.
+
+  dc du --by-prefix api: --by-prefix user:`,
+	Run: func(cmd *cobra.Command, args []string) {
+		prefixes, _ := cmd.Flags().GetStringSlice("by-prefix")
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		infos, err := cache.ListInfo()
+		cobra.CheckErr(err)
+		groups := make(map[string]*usage)
+		for _, prefix := range prefixes {
+			groups[prefix] = &usage{}
+		}
+		var other, total usage
+		now := time.Now()
+		for _, info := range infos {
+			expired := !info.Expiry.IsZero() && now.After(info.Expiry)
+			total.add(info.Size, expired)
+			if prefix, ok := longestPrefix(info.Key, prefixes); ok {
+				groups[prefix].add(info.Size, expired)
+			} else {
+				other.add(info.Size, expired)
+			}
+		}
+		if porcelain {
+			for _, prefix := range prefixes {
+				groups[prefix].print("prefix", prefix)
+			}
+			if len(prefixes) > 0 {
+				other.print("other", "")
+			}
+			total.print("total", "")
+			return
+		}
+		var rows [][]string
+		if len(prefixes) > 0 {
+			for prefix, u := range groups {
+				rows = append(rows, u.row(prefix))
+			}
+			slices.SortFunc(rows, func(a, b []string) int {
+				return strings.Compare(a[0], b[0])
+			})
+			rows = append(rows, other.row("(other)"))
+		}
+		rows = append(rows, total.row("total"))
+		cell := lipgloss.NewStyle().PaddingRight(2)
+		t := newTable().
+			Headers("PREFIX", "ENTRIES", "SIZE", "EXPIRED", "EXPIRED SIZE").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				style := cell.Copy()
+				if col > 0 {
+					style = style.Align(lipgloss.Right)
+				}
+				if row == 0 || row == len(rows) {
+					style = style.Bold(true)
+				}
+				return style
+			})
+		fmt.Println(t.Render())
+	},
+}
+
+// usage is the number and size of a group of entries,
+// and how many of them are expired.
+type usage struct {
+	entries, size               int64
+	expiredEntries, expiredSize int64
+}
+
+// add counts an entry toward the usage.
+func (u *usage) add(size int64, expired bool) {
+	u.entries++
+	u.size += size
+	if expired {
+		u.expiredEntries++
+		u.expiredSize += size
+	}
+}
+
+// row returns the usage as a row of the du table.
+func (u *usage) row(name string) []string {
+	return []string{
+		name,
+		strconv.FormatInt(u.entries, 10),
+		formatSize(u.size),
+		strconv.FormatInt(u.expiredEntries, 10),
+		formatSize(u.expiredSize),
+	}
+}
+
+// print prints the usage as a --porcelain record.
+func (u *usage) print(kind string, prefix string) {
+	printFields(kind, prefix, u.entries, u.size, u.expiredEntries, u.expiredSize)
+}
+
+// longestPrefix returns the longest of the prefixes of a key.
+func longestPrefix(key string, prefixes []string) (string, bool) {
+	longest, ok := "", false
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) && (!ok || len(prefix) > len(longest)) {
+			longest, ok = prefix, true
+		}
+	}
+	return longest, ok
+}
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+	duCmd.Flags().StringSlice("by-prefix", nil, "Break the usage down by key prefix (repeatable)")
+}
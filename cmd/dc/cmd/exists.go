@@ -0,0 +1,86 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of commands that report the state of an entry.
+// Exit code 1 means the command failed.
+const (
+	exitFresh   = 0
+	exitExpired = 2
+	exitMissing = 3
+)
+
+// existsCmd represents the exists command
+var existsCmd = &cobra.Command{
+	Use:   "exists",
+	Short: "Check whether a key is in the cache",
+	Long: `Check whether a key is in the cache, for shell scripts to branch on.
+
+Exits 0 if the entry exists and isn't expired, 2 if it's expired,
+3 if it doesn't exist, and 1 if the cache can't be read.
+Prints nothing unless --verbose is set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		key, _ := cmd.Flags().GetString("key")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		state, code := entryState(cache, key)
+		if verbose {
+			fmt.Println(state)
+		}
+		os.Exit(code)
+	},
+}
+
+// entryState returns the state of an entry, fresh, expired, or missing,
+// and its exit code.
+// If the entry can't be read, it exits with the error.
+func entryState(cache diskcache.Cache, key string) (string, int) {
+	entry, err := cache.Read(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "missing", exitMissing
+	}
+	cobra.CheckErr(err)
+	if !entry.Expiry.IsZero() && time.Now().After(entry.Expiry) {
+		return "expired", exitExpired
+	}
+	return "fresh", exitFresh
+}
+
+func init() {
+	rootCmd.AddCommand(existsCmd)
+	existsCmd.Flags().StringP("key", "k", "", "Key to check")
+	existsCmd.Flags().Bool("verbose", false, "Print fresh, expired, or missing")
+	_ = existsCmd.MarkFlagRequired("key")
+	_ = existsCmd.RegisterFlagCompletionFunc("key", completeKeys)
+}
@@ -24,7 +24,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/jluckyiv/diskcache"
 	"github.com/spf13/cobra"
 )
 
@@ -33,11 +32,13 @@ var flushCmd = &cobra.Command{
 	Use:   "flush",
 	Short: "flush the cache (clean all entries)",
 	Run: func(cmd *cobra.Command, args []string) {
-		cache, err := diskcache.New(cacheDir)
+		cache, err := openCache()
 		cobra.CheckErr(err)
 		err = cache.Flush()
 		cobra.CheckErr(err)
-		fmt.Println("Cache flushed")
+		if !porcelain {
+			fmt.Println("Cache flushed")
+		}
 	},
 }
 
@@ -0,0 +1,80 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// formatHelp documents the --format flag of the commands that print entries.
+const formatHelp = `With --format, each entry is printed with a Go template instead,
+followed by a newline. The template has the fields of the entry
+(.Key, .Value, .Expiry, .CreatedAt, .LastAccessed, and .Meta),
+its .Size in bytes, and the .Remaining time until it expires,
+which is zero if it never does. \t and \n in the template are a tab
+and a newline, and the string function converts the value to a string,
+and json to JSON:
+
+  --format '{{.Key}}\t{{.Expiry.Unix}}\t{{string .Value}}'`
+
+// templateFuncs are the functions of --format templates.
+var templateFuncs = template.FuncMap{
+	"string": func(b []byte) string {
+		return string(b)
+	},
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// formatEntry is the data of a --format template for an entry.
+type formatEntry struct {
+	diskcache.Data
+	// Size is the size in bytes of the value.
+	Size int
+	// Remaining is the time until the entry expires,
+	// or zero if it never does.
+	Remaining time.Duration
+}
+
+// parseFormat parses a --format template,
+// in which \t and \n are a tab and a newline.
+func parseFormat(format string) (*template.Template, error) {
+	format = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(format)
+	return template.New("format").Funcs(templateFuncs).Parse(format + "\n")
+}
+
+// printEntry prints an entry with a --format template.
+func printEntry(tmpl *template.Template, data diskcache.Data) error {
+	entry := formatEntry{Data: data, Size: len(data.Value)}
+	if !data.Expiry.IsZero() {
+		entry.Remaining = time.Until(data.Expiry)
+	}
+	return tmpl.Execute(os.Stdout, entry)
+}
@@ -22,8 +22,12 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"time"
 
 	"github.com/jluckyiv/diskcache"
 	"github.com/spf13/cobra"
@@ -33,24 +37,115 @@ import (
 var getCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get a value from the cache",
+	Long: `Get a value from the cache.
+
+With --output, the value is written to a file byte for byte
+instead of being printed.
+
+With --raw, only the value is printed, byte for byte, and the exit
+code tells why there is none: 0 if the entry exists and isn't expired,
+2 if it's expired, 3 if it doesn't exist, and 1 if the cache can't be read.
+
+` + formatHelp,
 	Run: func(cmd *cobra.Command, args []string) {
 		key, _ := cmd.Flags().GetString("key")
-		cache, err := diskcache.New(cacheDir)
+		output, _ := cmd.Flags().GetString("output")
+		raw, _ := cmd.Flags().GetBool("raw")
+		format, _ := cmd.Flags().GetString("format")
+		cache, err := openCache()
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		if output != "" {
+			err := writeValue(cache, key, output)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+		if raw {
+			os.Exit(writeRaw(cache, key))
+		}
+		if format != "" {
+			tmpl, err := parseFormat(format)
+			cobra.CheckErr(err)
+			entry, err := cache.Read(key)
+			if err == nil && !entry.Expiry.IsZero() && time.Now().After(entry.Expiry) {
+				err = diskcache.ErrExpired
+			}
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			cobra.CheckErr(printEntry(tmpl, entry))
+			return
+		}
 		result, err := cache.Get(key)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+		if porcelain {
+			printFields(key, result)
+			return
+		}
 		fmt.Printf("%s=%s\n", key, string(result))
 	},
 }
 
+// writeValue writes the value of an entry to a file.
+// The file isn't created if the entry can't be read,
+// and is removed if writing it fails.
+func writeValue(cache diskcache.Cache, key string, name string) error {
+	r, err := cache.GetReader(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	err = errors.Join(err, f.Close())
+	if err != nil {
+		os.Remove(name)
+		return err
+	}
+	return nil
+}
+
+// writeRaw writes the value of an entry to standard output
+// and returns the exit code of its state.
+// Other errors are printed to standard error.
+func writeRaw(cache diskcache.Cache, key string) int {
+	value, err := cache.Get(key)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return exitMissing
+	case errors.Is(err, diskcache.ErrExpired):
+		return exitExpired
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	_, err = os.Stdout.Write(value)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return exitFresh
+}
+
 func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.Flags().StringP("key", "k", "", "Key to retrieve the value")
+	getCmd.Flags().StringP("output", "o", "", "Write the value to a file")
+	getCmd.Flags().Bool("raw", false, "Print only the value, exiting 2 if expired or 3 if missing")
 	_ = getCmd.MarkFlagRequired("key")
+	getCmd.Flags().String("format", "", "Print the entry with a Go template")
+	getCmd.MarkFlagsMutuallyExclusive("output", "raw", "format")
+	_ = getCmd.RegisterFlagCompletionFunc("key", completeKeys)
 }
@@ -0,0 +1,158 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// grepCmd represents the grep command
+var grepCmd = &cobra.Command{
+	Use:   "grep PATTERN",
+	Short: "Search the keys and values in the cache",
+	Long: `Search the keys in the cache for a regular expression,
+printing the keys that match.
+
+With --values, the values are searched too. The matching lines of a value
+are printed after its key and line number, like grep -n, with --context
+lines around them. A binary value that matches is reported without
+printing it.
+
+  dc grep --values -C 2 'id-1234'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		values, _ := cmd.Flags().GetBool("values")
+		ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+		context, _ := cmd.Flags().GetInt("context")
+		expr := args[0]
+		if ignoreCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		cobra.CheckErr(err)
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		keys, err := cache.Keys()
+		cobra.CheckErr(err)
+		for _, key := range keys {
+			if re.MatchString(key) {
+				if porcelain {
+					printFields("key", key)
+				} else {
+					fmt.Println(key)
+				}
+				continue
+			}
+			if !values {
+				continue
+			}
+			entry, err := cache.Read(key)
+			if errors.Is(err, fs.ErrNotExist) {
+				// The entry was removed since listing the keys.
+				continue
+			}
+			cobra.CheckErr(err)
+			grepValue(os.Stdout, re, key, entry.Value, context)
+		}
+	},
+}
+
+// grepValue prints the lines of a value that match, prefixed by the key
+// and line number, with context lines around them.
+// Like grep, matching lines are separated from their number by a colon,
+// context lines by a hyphen, and groups of context that aren't adjacent
+// by a line of two hyphens.
+func grepValue(w io.Writer, re *regexp.Regexp, key string, value []byte, context int) {
+	if !re.Match(value) {
+		return
+	}
+	if !utf8.Valid(value) || bytes.IndexByte(value, 0) >= 0 {
+		if porcelain {
+			fmt.Fprintln(w, formatFields("binary", key))
+		} else {
+			fmt.Fprintf(w, "%s: binary value matches\n", key)
+		}
+		return
+	}
+	lines := strings.Split(strings.TrimSuffix(string(value), "\n"), "\n")
+	matches := make([]bool, len(lines))
+	shown := make([]bool, len(lines))
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		matches[i] = true
+		for j := max(i-context, 0); j <= min(i+context, len(lines)-1); j++ {
+			shown[j] = true
+		}
+	}
+	if !slices.Contains(matches, true) {
+		// The expression matches across lines.
+		if porcelain {
+			fmt.Fprintln(w, formatFields("value", key))
+		} else {
+			fmt.Fprintf(w, "%s: value matches\n", key)
+		}
+		return
+	}
+	if porcelain {
+		for i, line := range lines {
+			if matches[i] {
+				fmt.Fprintln(w, formatFields("value", key, i+1, line))
+			}
+		}
+		return
+	}
+	last := -1
+	for i, line := range lines {
+		if !shown[i] {
+			continue
+		}
+		if context > 0 && last >= 0 && i > last+1 {
+			fmt.Fprintln(w, "--")
+		}
+		sep := "-"
+		if matches[i] {
+			sep = ":"
+		}
+		fmt.Fprintf(w, "%s%s%d%s%s\n", key, sep, i+1, sep, line)
+		last = i
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(grepCmd)
+	grepCmd.Flags().Bool("values", false, "Search the values as well as the keys")
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "Ignore case")
+	grepCmd.Flags().IntP("context", "C", 0, "Lines of context around matching lines of values")
+}
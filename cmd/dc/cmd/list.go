@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
 	"github.com/jluckyiv/diskcache"
 	"github.com/spf13/cobra"
 )
@@ -35,25 +36,67 @@ import (
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List the keys in the cache",
+	Long: `List the entries in the cache as a table of their key, expiry,
+time remaining, and value size.
+
+With --wide, the table also shows the start of each value.
+
+` + formatHelp,
 	Run: func(cmd *cobra.Command, args []string) {
 		sortByKey, _ := cmd.Flags().GetBool("sort-key")
 		sortByVal, _ := cmd.Flags().GetBool("sort-val")
 		sortByExp, _ := cmd.Flags().GetBool("sort-exp")
+		sortBySize, _ := cmd.Flags().GetBool("sort-size")
+		sortByCreated, _ := cmd.Flags().GetBool("sort-created")
+		reverse, _ := cmd.Flags().GetBool("reverse")
+		wide, _ := cmd.Flags().GetBool("wide")
+		format, _ := cmd.Flags().GetString("format")
 
-		cache, err := diskcache.New(cacheDir)
+		cache, err := openCache()
 		cobra.CheckErr(err)
-		var result []diskcache.Data
+		var sort diskcache.ListOption
 		switch {
 		case sortByKey:
-			result, err = cache.List(diskcache.SortByKey)
+			sort = diskcache.SortByKey
 		case sortByVal:
-			result, err = cache.List(diskcache.SortByValue)
+			sort = diskcache.SortByValue
+		case sortBySize:
+			sort = diskcache.SortBySize
+		case sortByCreated:
+			sort = diskcache.SortByCreated
 		case sortByExp:
-			result, err = cache.List(diskcache.SortByExpiry)
+			sort = diskcache.SortByExpiry
 		default:
-			result, err = cache.List(diskcache.SortByExpiry)
+			sort = diskcache.SortByExpiry
 		}
+		if reverse {
+			sort = diskcache.Desc(sort)
+		}
+		result, err := cache.List(sort)
 		cobra.CheckErr(err)
+		if format != "" {
+			tmpl, err := parseFormat(format)
+			cobra.CheckErr(err)
+			for _, entry := range result {
+				cobra.CheckErr(printEntry(tmpl, entry))
+			}
+			return
+		}
+		if porcelain {
+			now := time.Now()
+			for _, entry := range result {
+				var remaining any = ""
+				if !entry.Expiry.IsZero() {
+					remaining = entry.Expiry.Sub(now)
+				}
+				fields := []any{entry.Key, entry.Expiry, remaining, len(entry.Value)}
+				if wide {
+					fields = append(fields, entry.Value)
+				}
+				printFields(fields...)
+			}
+			return
+		}
 		if len(result) == 0 {
 			fmt.Println("No entries found")
 			os.Exit(0)
@@ -65,24 +108,92 @@ var listCmd = &cobra.Command{
 		expiredStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#8c4351", Dark: "#f7768e"})
 		almostExpiredStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#8f5e15", Dark: "#e0af68"})
 		currentStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#33635c", Dark: "#73daca"})
-		for _, entry := range result {
-			expiryString := entry.Expiry.Local().Format(time.DateTime)
-			switch {
-			case time.Now().After(entry.Expiry):
-				fmt.Printf("%s %s\n", expiredStyle.Render(expiryString), entry.Key)
-			case time.Until(entry.Expiry).Minutes() < 5:
-				fmt.Printf("%s %s\n", almostExpiredStyle.Render(expiryString), entry.Key)
-			default:
-				fmt.Printf("%s %s\n", currentStyle.Render(expiryString), entry.Key)
+		headers := []string{"KEY", "EXPIRES", "REMAINING", "SIZE"}
+		if wide {
+			headers = append(headers, "VALUE")
+		}
+		rows := make([][]string, len(result))
+		styles := make([]lipgloss.Style, len(result))
+		now := time.Now()
+		for i, entry := range result {
+			expiry, remaining := "never", "-"
+			styles[i] = currentStyle
+			if !entry.Expiry.IsZero() {
+				expiry = entry.Expiry.Local().Format(time.DateTime)
+				remaining = entry.Expiry.Sub(now).Round(time.Second).String()
+				switch {
+				case now.After(entry.Expiry):
+					remaining = "expired"
+					styles[i] = expiredStyle
+				case entry.Expiry.Sub(now).Minutes() < 5:
+					styles[i] = almostExpiredStyle
+				}
+			}
+			rows[i] = []string{entry.Key, expiry, remaining, formatSize(int64(len(entry.Value)))}
+			if wide {
+				rows[i] = append(rows[i], previewValue(entry.Value, previewWidth, 1))
 			}
 		}
+		cell := lipgloss.NewStyle().PaddingRight(2).Align(lipgloss.Left)
+		t := newTable().
+			Headers(headers...).
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				style := cell.Copy()
+				if col == 3 {
+					style = style.Align(lipgloss.Right)
+				}
+				switch {
+				case row == 0:
+					return style.Bold(true)
+				case col == 1 || col == 2:
+					return style.Inherit(styles[row-1])
+				default:
+					return style
+				}
+			})
+		fmt.Println(t.Render())
 	},
 }
 
+// newTable returns a table with a line under the headers and no other borders.
+func newTable() *table.Table {
+	return table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderTop(false).
+		BorderBottom(false).
+		BorderLeft(false).
+		BorderRight(false).
+		BorderColumn(false)
+}
+
+// previewWidth is the width of the value column of dc list --wide.
+const previewWidth = 40
+
+// formatSize formats a number of bytes in binary units, such as 1.5 KiB.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().BoolP("sort-key", "K", false, "Sort by key")
 	listCmd.Flags().BoolP("sort-val", "V", false, "Sort by value")
 	listCmd.Flags().BoolP("sort-exp", "E", false, "Sort by expiry")
-	listCmd.MarkFlagsMutuallyExclusive("sort-key", "sort-val", "sort-exp")
+	listCmd.Flags().BoolP("sort-size", "S", false, "Sort by value size")
+	listCmd.Flags().BoolP("sort-created", "C", false, "Sort by creation time")
+	listCmd.Flags().BoolP("reverse", "r", false, "Reverse the sort order")
+	listCmd.Flags().BoolP("wide", "w", false, "Show the start of each value")
+	listCmd.Flags().String("format", "", "Print each entry with a Go template")
+	listCmd.MarkFlagsMutuallyExclusive("wide", "format")
+	listCmd.MarkFlagsMutuallyExclusive("sort-key", "sort-val", "sort-exp", "sort-size", "sort-created")
 }
@@ -0,0 +1,115 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// porcelain is whether commands print output for scripts; see porcelainCmd.
+var porcelain bool
+
+// porcelainCmd is the help topic of the --porcelain output.
+var porcelainCmd = &cobra.Command{
+	Use:   "porcelain",
+	Short: "Output of the --porcelain flag for scripts",
+	Long: `With --porcelain, commands print output for scripts to parse,
+which doesn't change when the human output does. Each line is a record
+of tab-separated fields, with no header and no color. Tabs, newlines,
+carriage returns, and backslashes in fields are escaped as \t, \n, \r,
+and \\. Times are RFC 3339 in UTC, and are empty for entries that never
+expire. Sizes are in bytes and durations in whole seconds.
+
+The fields of each command, in order:
+
+  get      key, value
+  set      key, size, expiry
+  list     key, expiry, seconds remaining (negative once expired), size,
+           and with --wide, value
+  grep     "key" and key for a matching key;
+           "value", key, line number, and line for a matching line;
+           "binary" and key for a matching binary value;
+           "value" and key for a value matching across lines
+  du       "prefix", "other", or "total", prefix, entries, size,
+           expired entries, and expired size
+  clean    with --dry-run, key, size, and seconds expired
+  vacuum   entries removed, entries rewritten, size before, size after
+  doctor   filename, problem, key, error, and action taken
+           ("quarantined", "removed", "failed", or empty)
+  warm     "loaded" or "skipped", and key
+  dir      directory
+  exists   with --verbose, state ("fresh", "expired", or "missing")
+
+flush and clean print nothing, and browse can't be used with --porcelain.`,
+}
+
+// printFields prints a --porcelain record of tab-separated fields.
+func printFields(fields ...any) {
+	fmt.Println(formatFields(fields...))
+}
+
+// formatFields formats a --porcelain record of tab-separated fields.
+func formatFields(fields ...any) string {
+	strs := make([]string, len(fields))
+	for i, field := range fields {
+		switch field := field.(type) {
+		case string:
+			strs[i] = escapeField(field)
+		case []byte:
+			strs[i] = escapeField(string(field))
+		case time.Time:
+			strs[i] = formatTime(field)
+		case time.Duration:
+			strs[i] = strconv.FormatInt(int64(field/time.Second), 10)
+		default:
+			strs[i] = escapeField(fmt.Sprint(field))
+		}
+	}
+	return strings.Join(strs, "\t")
+}
+
+// fieldEscaper escapes the characters of --porcelain fields
+// that would break records.
+var fieldEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// escapeField escapes a --porcelain field.
+func escapeField(s string) string {
+	return fieldEscaper.Replace(s)
+}
+
+// formatTime formats a --porcelain time,
+// which is empty for the zero time of entries that never expire.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func init() {
+	rootCmd.AddCommand(porcelainCmd)
+}
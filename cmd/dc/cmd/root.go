@@ -24,13 +24,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jluckyiv/diskcache"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
 	cfgFile  string
+	profile  string
 	cacheDir string
 )
 
@@ -62,32 +69,164 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $HOME/.dc.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $XDG_CONFIG_HOME/dc/config.toml, .yaml, or .json, or $HOME/.dc.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "", "profile of the config file to use (default is the profile setting)")
+	rootCmd.PersistentFlags().BoolVar(&porcelain, "porcelain", false, "print tab-separated output for scripts (see dc help porcelain)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 }
 
 // initConfig reads in config file and ENV variables if set.
+//
+// The config file sets the cache directory (cache_dir), the duration
+// of entries saved without --duration (default_ttl), whether to color
+// output (color: auto, always, or never), and the terminal background
+// (background: auto, dark, or light), and how entries are written
+// (format: json, binary, cbor, or msgpack; compression: none, gzip, or zlib).
+// The DC_CACHE_DIR environment variable overrides the cache directory.
+// Named profiles under profiles override these settings,
+// and are selected with --profile or the profile setting:
+//
+//	cache_dir = "~/.cache/dc"
+//	profile = "work"
+//
+//	[profiles.work]
+//	cache_dir = "/srv/cache"
+//	default_ttl = "10m"
 func initConfig() {
 	if cfgFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(cfgFile)
 	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".dc")
+		// Find the config directory, then the home directory.
+		if dir, err := os.UserConfigDir(); err == nil {
+			viper.AddConfigPath(filepath.Join(dir, "dc"))
+		}
+		viper.SetConfigName("config")
 	}
 
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
+	err := viper.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok && cfgFile == "" {
+		err = readHomeConfig()
+	}
+	if err == nil && !porcelain {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
-	cacheDir = viper.GetString("cache_dir")
+	if profile == "" {
+		profile = viper.GetString("profile")
+	}
+	if profile != "" {
+		settings := viper.Sub("profiles." + profile)
+		if settings == nil {
+			cobra.CheckErr(fmt.Errorf("unknown profile %q", profile))
+		}
+		for _, key := range settings.AllKeys() {
+			viper.Set(key, settings.Get(key))
+		}
+	}
+	cacheDir = resolveCacheDir()
+	if porcelain {
+		viper.Set("color", "never")
+	}
+	cobra.CheckErr(initColor())
+}
+
+// openCache opens the cache in the cache directory with the format
+// and compression settings, and any other options.
+// The settings only change how entries are written, since entries
+// are read in whatever format and compression they were written with.
+func openCache(options ...diskcache.Option) (diskcache.Cache, error) {
+	if format := viper.GetString("format"); format != "" {
+		options = append(options, diskcache.WithFormat(diskcache.Format(format)))
+	}
+	switch compression := viper.GetString("compression"); compression {
+	case "", "none":
+	case "gzip", "zlib":
+		options = append(options, diskcache.WithCompression(diskcache.Compression(compression)))
+	default:
+		return diskcache.Cache{}, fmt.Errorf("invalid compression setting %q: must be none, gzip, or zlib", compression)
+	}
+	return diskcache.New(cacheDir, options...)
+}
+
+// resolveCacheDir returns the cache directory from the DC_CACHE_DIR
+// environment variable, or else the cache_dir setting, or else
+// the dc subdirectory of the user's cache directory,
+// such as $XDG_CACHE_HOME/dc or ~/.cache/dc on Linux.
+func resolveCacheDir() string {
+	if dir := os.Getenv("DC_CACHE_DIR"); dir != "" {
+		return expandHome(dir)
+	}
+	if dir := viper.GetString("cache_dir"); dir != "" {
+		return expandHome(dir)
+	}
+	dir, err := os.UserCacheDir()
+	cobra.CheckErr(err)
+	return filepath.Join(dir, "dc")
+}
+
+// readHomeConfig reads the config file $HOME/.dc.yaml,
+// where it was before the config directory was searched.
+func readHomeConfig() error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	viper.SetConfigFile(filepath.Join(home, ".dc.yaml"))
+	err = viper.ReadInConfig()
+	if os.IsNotExist(err) {
+		// Leave the config file unset, so none is reported as used.
+		viper.SetConfigFile("")
+	}
+	return err
+}
+
+// expandHome replaces a leading ~ in a path with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// initColor sets the color profile and background of the output
+// from the color and background settings.
+func initColor() error {
+	switch color := viper.GetString("color"); color {
+	case "", "auto":
+	case "always":
+		lipgloss.SetColorProfile(termenv.TrueColor)
+	case "never":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	default:
+		return fmt.Errorf("invalid color setting %q: must be auto, always, or never", color)
+	}
+	switch background := viper.GetString("background"); background {
+	case "", "auto":
+	case "dark":
+		lipgloss.SetHasDarkBackground(true)
+	case "light":
+		lipgloss.SetHasDarkBackground(false)
+	default:
+		return fmt.Errorf("invalid background setting %q: must be auto, dark, or light", background)
+	}
+	return nil
+}
+
+// durationFlag returns the value of a duration flag,
+// or the default_ttl setting if the flag isn't set and the setting is.
+func durationFlag(cmd *cobra.Command, name string) time.Duration {
+	d, _ := cmd.Flags().GetDuration(name)
+	if !cmd.Flags().Changed(name) && viper.IsSet("default_ttl") {
+		return viper.GetDuration("default_ttl")
+	}
+	return d
 }
@@ -0,0 +1,131 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcacheredis"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the cache over the network",
+	Long: `Serve the cache over the network, until interrupted.
+
+With --addr, the cache is served over HTTP as a REST API:
+
+  GET /keys/{key}     returns the value of an entry
+  PUT /keys/{key}     saves the request body as the value of an entry
+  DELETE /keys/{key}  removes an entry
+  GET /keys           lists the unexpired entries as JSON
+
+PUT saves entries for the duration in the Diskcache-TTL header,
+such as 1h30m or a number of seconds, or never expiring if there is none:
+
+  dc serve --addr :8080
+  curl -X PUT -H 'Diskcache-TTL: 10m' --data hello localhost:8080/keys/greeting
+
+With --redis, the cache is served over a subset of the Redis protocol
+(GET, SET, DEL, EXPIRE, TTL, KEYS, and SCAN), so redis-cli and Redis
+client libraries can read and write the cache directory:
+
+  dc serve --redis :6379
+  redis-cli -p 6379 SET greeting hello EX 60
+
+Both may be served at once.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		addr, _ := cmd.Flags().GetString("addr")
+		redisAddr, _ := cmd.Flags().GetString("redis")
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		var servers []func() error
+		var stops []func()
+		if addr != "" {
+			server := &http.Server{Addr: addr, Handler: diskcache.Handler(cache)}
+			servers = append(servers, func() error {
+				fmt.Fprintf(os.Stderr, "Serving %s over HTTP on %s\n", cacheDir, addr)
+				err := server.ListenAndServe()
+				if errors.Is(err, http.ErrServerClosed) {
+					return nil
+				}
+				return err
+			})
+			stops = append(stops, func() { server.Shutdown(context.Background()) })
+		}
+		if redisAddr != "" {
+			server := diskcacheredis.NewServer(cache)
+			servers = append(servers, func() error {
+				fmt.Fprintf(os.Stderr, "Serving %s over the Redis protocol on %s\n", cacheDir, redisAddr)
+				err := server.ListenAndServe(redisAddr)
+				if errors.Is(err, diskcacheredis.ErrServerClosed) {
+					return nil
+				}
+				return err
+			})
+			stops = append(stops, func() { server.Close() })
+		}
+		stopAll := sync.OnceFunc(func() {
+			for _, stop := range stops {
+				stop()
+			}
+		})
+		go func() {
+			interrupt := make(chan os.Signal, 1)
+			signal.Notify(interrupt, os.Interrupt)
+			<-interrupt
+			stopAll()
+		}()
+		// Stop all the servers when one fails, and report the first error.
+		errs := make(chan error, len(servers))
+		for _, serve := range servers {
+			go func() {
+				err := serve()
+				stopAll()
+				errs <- err
+			}()
+		}
+		var firstErr error
+		for range servers {
+			if err := <-errs; err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		cobra.CheckErr(firstErr)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringP("addr", "a", "", "Address to serve HTTP on, such as :8080")
+	serveCmd.Flags().String("redis", "", "Address to serve the Redis protocol on, such as :6379")
+	serveCmd.MarkFlagsOneRequired("addr", "redis")
+}
@@ -23,9 +23,11 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
-	"github.com/jluckyiv/diskcache"
 	"github.com/spf13/cobra"
 )
 
@@ -33,25 +35,181 @@ import (
 var setCmd = &cobra.Command{
 	Use:   "set",
 	Short: "Set a value in the cache",
+	Long: `Set a value in the cache.
+
+The value is the --val flag, or standard input with --stdin or --val -,
+which may be multi-line or binary:
+
+  curl -s https://example.com/api | dc set -k api --stdin -d 10m
+
+With --file, the value is the contents of a file, which are streamed
+to the cache byte for byte, so large artifacts can be cached.
+With compression set, the file is read into memory and compressed
+like any other value instead:
+
+  dc set -k release.tar.gz --file release.tar.gz -d 24h
+
+With --expires-at, the entry expires at a wall-clock time instead of
+after --duration. The time is RFC 3339, a local date and time such as
+"2025-01-31" or "2025-01-31 17:00", or a day and time of day such as
+"9am", "tomorrow 9am", "friday 17:30", or "noon". A time of day alone
+is its next occurrence.
+
+  dc set -k report -v done --expires-at "tomorrow 9am"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		key, _ := cmd.Flags().GetString("key")
 		value, _ := cmd.Flags().GetString("val")
-		duration, _ := cmd.Flags().GetDuration("duration")
-		cache, err := diskcache.New(cacheDir)
+		stdin, _ := cmd.Flags().GetBool("stdin")
+		file, _ := cmd.Flags().GetString("file")
+		duration := durationFlag(cmd, "duration")
+		if expiresAt, _ := cmd.Flags().GetString("expires-at"); expiresAt != "" {
+			t, err := parseExpiresAt(expiresAt, time.Now())
+			cobra.CheckErr(err)
+			duration = time.Until(t).Round(time.Second)
+			if duration <= 0 {
+				cobra.CheckErr(fmt.Errorf("expiry %s has already passed", t.Format(time.RFC3339)))
+			}
+		}
+		cache, err := openCache()
 		cobra.CheckErr(err)
+		report := func(size int64, format string, args ...any) {
+			if porcelain {
+				printFields(key, size, cache.Expiry(key))
+				return
+			}
+			fmt.Printf(format, args...)
+		}
+		if file != "" {
+			f, err := os.Open(file)
+			cobra.CheckErr(err)
+			defer f.Close()
+			err = cache.SetReader(key, f, duration)
+			cobra.CheckErr(err)
+			info, err := f.Stat()
+			cobra.CheckErr(err)
+			report(info.Size(), "Set %s from %s for %s\n", key, file, duration)
+			return
+		}
+		if stdin || value == "-" {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			cobra.CheckErr(err)
+			err = cache.Set(key, data, duration)
+			cobra.CheckErr(err)
+			report(int64(len(data)), "Set %s (%d bytes) for %s\n", key, len(data), duration)
+			return
+		}
 		err = cache.Set(key, []byte(value), duration)
 		cobra.CheckErr(err)
-		fmt.Printf("Set %s=%s for %s\n", key, value, duration)
+		report(int64(len(value)), "Set %s=%s for %s\n", key, value, duration)
 	},
 }
 
+// dateLayouts are the layouts of absolute times for --expires-at.
+// Layouts without a zone are in local time.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// clockLayouts are the layouts of times of day for --expires-at.
+var clockLayouts = []string{"15:04", "3pm", "3:04pm", "3 pm", "3:04 pm"}
+
+// parseExpiresAt parses an --expires-at time relative to now.
+// It's an absolute time in one of dateLayouts, or else an optional
+// day (today, tomorrow, or a weekday) followed by an optional time
+// of day (in one of clockLayouts, noon, or midnight).
+// A day alone is its start, and a time of day alone, or with
+// a weekday, is its next occurrence.
+func parseExpiresAt(s string, now time.Time) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, s, now.Location())
+		if err == nil {
+			return t, nil
+		}
+	}
+	invalid := fmt.Errorf("invalid expiry time %q", s)
+	s = strings.ToLower(strings.TrimSpace(s))
+	day, clock, _ := strings.Cut(s, " ")
+	days, weekday := 0, false
+	switch day {
+	case "today":
+	case "tomorrow":
+		days = 1
+	default:
+		w, ok := parseWeekday(day)
+		if ok {
+			days, weekday = (int(w)-int(now.Weekday())+7)%7, true
+		} else {
+			// There's no day, so it's all a time of day.
+			day, clock = "", s
+		}
+	}
+	hour, min := 0, 0
+	if clock != "" {
+		c, ok := parseClock(clock)
+		if !ok {
+			return time.Time{}, invalid
+		}
+		hour, min = c.Hour(), c.Minute()
+	} else if day == "" {
+		return time.Time{}, invalid
+	}
+	t := time.Date(now.Year(), now.Month(), now.Day()+days, hour, min, 0, 0, now.Location())
+	if !t.After(now) {
+		switch {
+		case day == "":
+			t = t.AddDate(0, 0, 1)
+		case weekday:
+			t = t.AddDate(0, 0, 7)
+		}
+	}
+	return t, nil
+}
+
+// parseWeekday parses the name of a day of the week, such as friday.
+func parseWeekday(s string) (time.Weekday, bool) {
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		if s == strings.ToLower(w.String()) {
+			return w, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses a time of day in one of clockLayouts, noon, or midnight.
+// It expects s to be lowercase.
+func parseClock(s string) (time.Time, bool) {
+	switch s {
+	case "noon":
+		return time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC), true
+	case "midnight":
+		return time.Time{}, true
+	}
+	for _, layout := range clockLayouts {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 func init() {
 	rootCmd.AddCommand(setCmd)
 	setCmd.Flags().StringP("key", "k", "", "Key to store the value")
-	setCmd.Flags().StringP("val", "v", "", "Value to store")
-	setCmd.Flags().DurationP("duration", "d", 1*time.Hour, "Duration to store the value")
+	setCmd.Flags().StringP("val", "v", "", "Value to store (- reads standard input)")
+	setCmd.Flags().Bool("stdin", false, "Read the value from standard input")
+	setCmd.Flags().StringP("file", "f", "", "Read the value from a file")
+	setCmd.Flags().DurationP("duration", "d", 1*time.Hour, "Duration to store the value (0 never expires)")
+	setCmd.Flags().String("expires-at", "", "Time to expire the value, instead of --duration")
 	_ = setCmd.MarkFlagRequired("key")
-	_ = setCmd.MarkFlagRequired("value")
+	_ = setCmd.RegisterFlagCompletionFunc("key", completeKeys)
+	setCmd.MarkFlagsMutuallyExclusive("val", "stdin", "file")
+	setCmd.MarkFlagsMutuallyExclusive("duration", "expires-at")
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,73 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// vacuumCmd represents the vacuum command
+var vacuumCmd = &cobra.Command{
+	Use:   "vacuum",
+	Short: "Remove expired entries and rewrite the rest",
+	Long: `Remove the expired entries and rewrite the rest with the format
+and compression settings, reporting the space reclaimed.
+
+Run it after changing the settings, so the entries written before
+are stored like new ones. --format and --compression override
+the settings:
+
+  dc vacuum --format binary --compression gzip
+
+Entries written by other processes during the vacuum may be lost.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, name := range []string{"format", "compression"} {
+			if value, _ := cmd.Flags().GetString(name); value != "" {
+				viper.Set(name, value)
+			}
+		}
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		report, err := cache.Vacuum()
+		cobra.CheckErr(err)
+		if porcelain {
+			printFields(report.Removed, report.Rewritten, report.SizeBefore, report.SizeAfter)
+			return
+		}
+		fmt.Printf("Removed %d expired entries, rewrote %d entries\n", report.Removed, report.Rewritten)
+		reclaimed := report.Reclaimed()
+		if reclaimed >= 0 {
+			fmt.Printf("Reclaimed %s (%s to %s)\n", formatSize(reclaimed), formatSize(report.SizeBefore), formatSize(report.SizeAfter))
+		} else {
+			fmt.Printf("Grew by %s (%s to %s)\n", formatSize(-reclaimed), formatSize(report.SizeBefore), formatSize(report.SizeAfter))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vacuumCmd)
+	vacuumCmd.Flags().String("format", "", "Format to rewrite the entries in: json, binary, cbor, or msgpack")
+	vacuumCmd.Flags().String("compression", "", "Compression to rewrite the values with: none, gzip, or zlib")
+}
@@ -0,0 +1,191 @@
+/*
+Copyright © 2024 Jackson Lucky <jack@jacksonlucky.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/spf13/cobra"
+)
+
+// warmCmd represents the warm command
+var warmCmd = &cobra.Command{
+	Use:   "warm MANIFEST",
+	Short: "Pre-populate the cache from a manifest",
+	Long: `Pre-populate the cache from a JSON manifest, such as at deploy time,
+so the first reads don't miss. Entries already in the cache and
+unexpired are skipped, and the others are loaded in parallel.
+
+The manifest is a list of entries, each with a key, a value from
+exactly one of value, file, or url, and an optional ttl, which
+defaults to --duration:
+
+  [
+    {"key": "greeting", "value": "hello"},
+    {"key": "config", "file": "config.json", "ttl": "24h"},
+    {"key": "api", "url": "https://example.com/api", "ttl": "10m"}
+  ]
+
+Files are relative to the manifest. A manifest of - is read from
+standard input.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		specs, err := readWarmManifest(cmd, args[0], durationFlag(cmd, "duration"))
+		cobra.CheckErr(err)
+		cache, err := openCache()
+		cobra.CheckErr(err)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		report, err := cache.Warm(ctx, specs)
+		if porcelain {
+			for _, key := range report.Loaded {
+				printFields("loaded", key)
+			}
+			for _, key := range report.Skipped {
+				printFields("skipped", key)
+			}
+		} else {
+			for _, key := range report.Loaded {
+				fmt.Printf("Loaded %s\n", key)
+			}
+			fmt.Printf("Loaded %d entries, skipped %d already cached\n", len(report.Loaded), len(report.Skipped))
+		}
+		cobra.CheckErr(err)
+	},
+}
+
+// warmEntry is an entry of a warm manifest.
+type warmEntry struct {
+	Key   string  `json:"key"`
+	Value *string `json:"value"`
+	File  string  `json:"file"`
+	URL   string  `json:"url"`
+	TTL   string  `json:"ttl"`
+}
+
+// readWarmManifest reads a warm manifest from a file, or standard input
+// for -, and returns its entries with loaders, lasting ttl by default.
+func readWarmManifest(cmd *cobra.Command, name string, ttl time.Duration) ([]diskcache.WarmSpec, error) {
+	var r io.Reader = cmd.InOrStdin()
+	dir := "."
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+		dir = filepath.Dir(name)
+	}
+	var entries []warmEntry
+	err := json.NewDecoder(r).Decode(&entries)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+	specs := make([]diskcache.WarmSpec, len(entries))
+	for i, entry := range entries {
+		spec, err := entry.spec(dir, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("manifest entry %d: %w", i+1, err)
+		}
+		specs[i] = spec
+	}
+	return specs, nil
+}
+
+// spec returns the warm spec of a manifest entry,
+// with files relative to dir, lasting ttl by default.
+func (e warmEntry) spec(dir string, ttl time.Duration) (diskcache.WarmSpec, error) {
+	if e.Key == "" {
+		return diskcache.WarmSpec{}, errors.New("key is empty")
+	}
+	spec := diskcache.WarmSpec{Key: e.Key, TTL: ttl}
+	if e.TTL != "" {
+		d, err := time.ParseDuration(e.TTL)
+		if err != nil {
+			return diskcache.WarmSpec{}, err
+		}
+		spec.TTL = d
+	}
+	sources := 0
+	if e.Value != nil {
+		sources++
+		value := []byte(*e.Value)
+		spec.Load = func(context.Context) ([]byte, error) {
+			return value, nil
+		}
+	}
+	if e.File != "" {
+		sources++
+		file := e.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		spec.Load = func(context.Context) ([]byte, error) {
+			return os.ReadFile(file)
+		}
+	}
+	if e.URL != "" {
+		sources++
+		url := e.URL
+		spec.Load = func(ctx context.Context) ([]byte, error) {
+			return fetch(ctx, url)
+		}
+	}
+	if sources != 1 {
+		return diskcache.WarmSpec{}, fmt.Errorf("%s: exactly one of value, file, or url is required", e.Key)
+	}
+	return spec, nil
+}
+
+// fetch returns the body of a successful GET request to a URL.
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func init() {
+	rootCmd.AddCommand(warmCmd)
+	warmCmd.Flags().DurationP("duration", "d", 1*time.Hour, "Duration of entries without a ttl (0 never expires)")
+}
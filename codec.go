@@ -0,0 +1,91 @@
+package diskcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Codec encodes and decodes the values saved with SetValue
+// and read with GetValue, such as a faster JSON library,
+// MessagePack, or an encrypting wrapper around another codec.
+// A codec must be safe for concurrent use.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec is the default codec, which encodes values as JSON
+// with encoding/json.
+var JSONCodec Codec = jsonCodec{}
+
+// jsonCodec is a codec that uses encoding/json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec sets the codec of the values saved with SetValue
+// and read with GetValue.
+// The default codec is JSONCodec.
+// Entries saved with one codec can't be read with another,
+// so a cache directory should always be opened with the same codec.
+func WithCodec(codec Codec) Option {
+	return func(c *Cache) {
+		if codec == nil {
+			c.err = errors.New("codec is nil")
+			return
+		}
+		c.codec = codec
+	}
+}
+
+// SetValue saves a cache entry whose value is v encoded with the codec
+// of the cache, with a key and duration.
+func (c Cache) SetValue(key string, v any, duration time.Duration) error {
+	return c.setEncoded(c.valueCodec(), key, v, duration)
+}
+
+// GetValue gets a cache entry and decodes its value into out
+// with the codec of the cache.
+// Out must be a pointer, or whatever else the codec decodes into.
+// It returns an error if the entry is expired, like Get.
+func (c Cache) GetValue(key string, out any) error {
+	return c.getDecoded(c.valueCodec(), key, out)
+}
+
+// valueCodec returns the codec of the cache.
+func (c Cache) valueCodec() Codec {
+	if c.codec == nil {
+		return JSONCodec
+	}
+	return c.codec
+}
+
+// setEncoded saves a cache entry whose value is v encoded with a codec.
+func (c Cache) setEncoded(codec Codec, key string, v any, duration time.Duration) error {
+	value, err := codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshaling value: %w", err)
+	}
+	return c.Set(key, value, duration)
+}
+
+// getDecoded gets a cache entry and decodes its value with a codec.
+func (c Cache) getDecoded(codec Codec, key string, out any) error {
+	value, err := c.Get(key)
+	if err != nil {
+		return err
+	}
+	err = codec.Unmarshal(value, out)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling value: %w", err)
+	}
+	return nil
+}
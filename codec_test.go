@@ -0,0 +1,93 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// gobCodec is a codec that uses encoding/gob.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func TestCodec(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+
+	t.Run("TestDefaultCodec", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.SetValue("user", user{Name: "alice", Age: 30}, time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		value, err := cache.Get("user")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(value) != `{"Name":"alice","Age":30}` {
+			t.Fatalf("Expected JSON value, got %s", value)
+		}
+	})
+
+	t.Run("TestWithCodec", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithCodec(gobCodec{}))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.SetValue("user", user{Name: "alice", Age: 30}, time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		var got user
+		err = cache.GetValue("user", &got)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if got != (user{Name: "alice", Age: 30}) {
+			t.Fatalf("Expected alice, got %+v", got)
+		}
+		value, _ := cache.Get("user")
+		if bytes.HasPrefix(value, []byte("{")) {
+			t.Fatalf("Expected gob value, got %s", value)
+		}
+
+		// The JSON helpers ignore the codec.
+		err = cache.SetJSON("json", user{Name: "bob"}, time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		value, _ = cache.Get("json")
+		if !bytes.HasPrefix(value, []byte("{")) {
+			t.Fatalf("Expected JSON value, got %q", value)
+		}
+		err = cache.GetValue("json", &got)
+		if err == nil {
+			t.Fatalf("Expected error decoding JSON with the gob codec")
+		}
+	})
+
+	t.Run("TestNilCodec", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithCodec(nil))
+		if err == nil {
+			t.Fatalf("Expected error for nil codec")
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package diskcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// Compression is a value compression algorithm.
+type Compression string
+
+const (
+	// CompressionNone stores values uncompressed.
+	CompressionNone Compression = ""
+	// Gzip compresses values with gzip.
+	Gzip Compression = "gzip"
+	// Zlib compresses values with zlib.
+	Zlib Compression = "zlib"
+)
+
+// WithCompression compresses values on Set and decompresses them on Get and Read.
+// The algorithm is recorded in each entry,
+// so entries written with other algorithms still read correctly.
+func WithCompression(compression Compression) Option {
+	return func(c *Cache) {
+		c.compression = compression
+	}
+}
+
+// compress compresses a value with the algorithm.
+func compress(compression Compression, value []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case Gzip:
+		w = gzip.NewWriter(&buf)
+	case Zlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		return nil, fmt.Errorf("unknown compression: %q", compression)
+	}
+	_, err := w.Write(value)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompress decompresses a value with the algorithm.
+func decompress(compression Compression, value []byte) ([]byte, error) {
+	var r io.ReadCloser
+	var err error
+	switch compression {
+	case Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(value))
+	case Zlib:
+		r, err = zlib.NewReader(bytes.NewReader(value))
+	default:
+		return nil, fmt.Errorf("unknown compression: %q", compression)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
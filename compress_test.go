@@ -0,0 +1,81 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestCompression(t *testing.T) {
+	value := bytes.Repeat([]byte(`{"name":"value"},`), 1000)
+	for _, compression := range []diskcache.Compression{diskcache.Gzip, diskcache.Zlib} {
+		t.Run(string(compression), func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, diskcache.WithCompression(compression))
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			key := "compressed"
+			err = cache.Set(key, value, 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			got, err := cache.Get(key)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			if !bytes.Equal(got, value) {
+				t.Fatalf("Expected cache value to round trip")
+			}
+			info, err := os.Stat(cache.Filepath(key))
+			if err != nil {
+				t.Fatalf("Error reading file: %v", err)
+			}
+			if info.Size() >= int64(len(value)) {
+				t.Fatalf("Expected file size %d to be less than value size %d", info.Size(), len(value))
+			}
+
+			// A cache without compression reads compressed entries.
+			plain, err := diskcache.New(cacheDir)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			got, err = plain.Get(key)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			if !bytes.Equal(got, value) {
+				t.Fatalf("Expected cache value to round trip")
+			}
+			err = plain.Set("plain", value, 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			data, err := cache.List()
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			for _, d := range data {
+				if !bytes.Equal(d.Value, value) {
+					t.Fatalf("Expected value of %s to round trip", d.Key)
+				}
+			}
+		})
+	}
+
+	t.Run("TestUnknown", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithCompression("bogus"))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", value, 1*time.Minute)
+		if err == nil {
+			t.Fatalf("Expected error for unknown compression, but got nil")
+		}
+	})
+}
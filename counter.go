@@ -0,0 +1,66 @@
+package diskcache
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Increment adds delta to the value of a counter entry
+// and returns the new value.
+// The value is stored as a decimal integer.
+// If the entry doesn't exist or is expired, Increment creates it
+// with the delta as its value and the duration;
+// otherwise it keeps the expiry of the entry.
+// It returns an error if the value isn't an integer.
+// Increment is atomic with respect to other CAS, Increment, and Append calls
+// on the cache and its copies and, if the cache supports Lock,
+// in other processes sharing the cache directory;
+// calling it while holding Lock on the key deadlocks.
+func (c Cache) Increment(key string, delta int64, duration time.Duration) (int64, error) {
+	if len(key) == 0 {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+	unlock, err := c.lockEntry(key)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	rec, ok, err := c.current(key)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	if ok {
+		n, err = strconv.ParseInt(string(rec.Value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value of key %q isn't an integer: %w", key, err)
+		}
+	} else {
+		rec.Data = Data{Key: key, Expiry: c.expiryFrom(c.now(), duration)}
+		rec.CreatedAt = c.now()
+	}
+	n += delta
+	rec.Value = strconv.AppendInt(nil, n, 10)
+	err = c.keepVersion(c.Filename(key))
+	if err != nil {
+		return 0, fmt.Errorf("error keeping version: %w", err)
+	}
+	err = c.write(rec.Data)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		err = c.evict(key)
+		if err != nil {
+			return 0, fmt.Errorf("error evicting entries: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// Decrement subtracts delta from the value of a counter entry
+// and returns the new value, like Increment.
+func (c Cache) Decrement(key string, delta int64, duration time.Duration) (int64, error) {
+	return c.Increment(key, -delta, duration)
+}
@@ -0,0 +1,105 @@
+package diskcache_test
+
+import (
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestIncrement(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "counter"
+
+	t.Run("TestCreate", func(t *testing.T) {
+		n, err := cache.Increment(key, 5, 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error incrementing cache: %v", err)
+		}
+		if n != 5 {
+			t.Fatalf("Expected counter to be 5, got %d", n)
+		}
+	})
+
+	t.Run("TestKeepExpiry", func(t *testing.T) {
+		expiry := cache.Expiry(key)
+		n, err := cache.Decrement(key, 2, 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error decrementing cache: %v", err)
+		}
+		if n != 3 {
+			t.Fatalf("Expected counter to be 3, got %d", n)
+		}
+		if !cache.Expiry(key).Equal(expiry) {
+			t.Fatalf("Expected expiry to be %v, got %v", expiry, cache.Expiry(key))
+		}
+	})
+
+	t.Run("TestConcurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := cache.Increment(key, 1, 1*time.Minute)
+				if err != nil {
+					t.Errorf("Error incrementing cache: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "23" {
+			t.Fatalf("Expected counter to be 23, got %s", got)
+		}
+	})
+
+	t.Run("TestNotInteger", func(t *testing.T) {
+		err := cache.Set("text", []byte("text"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, err = cache.Increment("text", 1, 1*time.Minute)
+		if err == nil {
+			t.Fatalf("Expected error incrementing text")
+		}
+	})
+
+	t.Run("TestSeparateCaches", func(t *testing.T) {
+		// Caches opened separately share only the directory,
+		// like caches in different processes.
+		other, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		caches := []diskcache.Cache{cache, other}
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func(c diskcache.Cache) {
+				defer wg.Done()
+				_, err := c.Increment("shared", 1, 1*time.Minute)
+				if err != nil {
+					t.Errorf("Error incrementing cache: %v", err)
+				}
+			}(caches[i%2])
+		}
+		wg.Wait()
+		got, err := cache.Get("shared")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "20" {
+			t.Fatalf("Expected counter to be 20, got %s", got)
+		}
+	})
+}
@@ -1,55 +1,236 @@
 package diskcache
 
 import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/cipher"
 	"crypto/sha256"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
-	"os"
+	"log/slog"
 	"path/filepath"
 	"slices"
 	"strings"
-	"sync"
 	"time"
 )
 
 // Cache is a disk cache.
-// It stores entries in a directory on disk.
+// It stores entries in a directory on disk, or in another Store.
 type Cache struct {
-	dir string
+	dir          string
+	fsys         FS
+	fileMode     fs.FileMode
+	dirMode      fs.FileMode
+	store        Store
+	index        *index
+	bloom        *bloom
+	bloomEntries int
+	maxEntries   int
+	evictOrder   RemovalOrder
+	clock        Clock
+	trackAccess  bool
+	accessRes    time.Duration
+	defaultTTL   time.Duration
+	minTTL       time.Duration
+	maxTTL       time.Duration
+	janitor      *janitor
+	stats        *stats
+	locks        *keyLocks
+	files        *keyLocks
+	commits      *commitLock
+	journal      bool
+	manifestFile bool
+	sharded      bool
+	compression  Compression
+	aead         cipher.AEAD
+	format       Format
+	codec        Codec
+	rawValues    bool
+	ext          string
+	filenameFunc func(key string) string
+	keyFunc      func(name string) (string, error)
+	versions     int
+	verify       bool
+	readOnly     bool
+	sync         bool
+	onEvict      func(Data)
+	onExpire     func(Data)
+	logger       *slog.Logger
+	hook         OperationHook
+	ctx          context.Context
+	// err is the first error from applying the options.
+	err error
 }
 
+// defaultExt is the default extension of entry files.
+const defaultExt = ".json"
+
+// NoExpiry is a duration for entries that never expire.
+const NoExpiry time.Duration = 0
+
+// ErrExpired is returned when reading an entry that has expired.
+var ErrExpired = errors.New("cache expired")
+
+// Option configures a Cache.
+type Option func(*Cache)
+
 // Data is a cache entry.
-// It contains a key, a value, and an expiry time.
+// It contains a key, a value, an expiry time, and optional metadata.
+// A zero expiry time means the entry never expires.
 // Because the disk cache hashes the key for a filename, the key is stored in the entry.
 // The hash ensures that the filename is valid and unique.
 type Data struct {
 	Expiry time.Time
 	Key    string
 	Value  []byte
+	Meta   map[string]string `json:",omitempty"`
+	// CreatedAt is when the entry was written, or the zero time for entries
+	// written before creation times were recorded.
+	// Touch keeps it; Set resets it.
+	CreatedAt time.Time
+	// LastAccessed is when the entry was last read by Get, GetWithTTL,
+	// or GetReader, or the zero time if it hasn't been read since it was
+	// written or the cache doesn't track access; see WithAccessTracking.
+	LastAccessed time.Time
 }
 
 // New creates a new disk cache in the given directory.
-func New(dir string) (Cache, error) {
-	var err error
+// It accepts options to configure the cache.
+func New(dir string, options ...Option) (Cache, error) {
 	// Validate the directory.
 	if len(dir) == 0 {
 		return Cache{}, fmt.Errorf("directory path is empty")
 	}
+	return Cache{dir: dir, ext: defaultExt}.open(options)
+}
+
+// open applies the options to a cache, creates its directory
+// if it has no other store, loads the index, and starts the janitor.
+func (c Cache) open(options []Option) (Cache, error) {
+	c.stats = &stats{}
+	c.locks = &keyLocks{locks: make(map[string]*keyLock)}
+	c.files = &keyLocks{locks: make(map[string]*keyLock)}
+	c.commits = &commitLock{}
+	for _, option := range options {
+		option(&c)
+		if c.err != nil {
+			return Cache{}, c.err
+		}
+	}
+	if c.fsys == nil {
+		c.fsys = osFS{}
+	}
+	if c.fileMode == 0 {
+		c.fileMode = defaultFileMode
+	}
+	if c.dirMode == 0 {
+		c.dirMode = defaultDirMode
+	}
+	if _, ok := c.fsys.(SyncFS); (c.sync || c.journal) && !ok {
+		return Cache{}, errors.New("file system doesn't support sync")
+	}
+	switch s := c.baseStore().(type) {
+	case nil:
+		err := c.openDir()
+		if err != nil {
+			return Cache{}, err
+		}
+		store := c.newDirStore(c.dir)
+		store.readOnly = c.readOnly
+		c.store = store
+	case fsStore:
+		s.sharded = c.sharded
+		c.store = s
+	}
+	if c.rawValues && (c.aead != nil || c.compression != CompressionNone) {
+		return Cache{}, errors.New("raw values can't be compressed or encrypted")
+	}
+	if c.maxTTL > 0 && c.minTTL > c.maxTTL {
+		return Cache{}, fmt.Errorf("minimum TTL %v is greater than maximum TTL %v", c.minTTL, c.maxTTL)
+	}
+	if c.readOnly && c.janitor != nil {
+		return Cache{}, errors.New("read-only cache can't have a janitor")
+	}
+	if c.readOnly && c.journal {
+		return Cache{}, errors.New("read-only cache can't have a journal")
+	}
+	if c.journal {
+		err := c.recoverJournals()
+		if err != nil {
+			return Cache{}, fmt.Errorf("error recovering journal: %w", err)
+		}
+	}
+	if c.manifestFile {
+		m, err := c.newManifest()
+		if err != nil {
+			return Cache{}, err
+		}
+		c.index.manifest = m
+	}
+	if c.bloomEntries > 0 {
+		b, err := c.openBloom()
+		if err != nil {
+			return Cache{}, err
+		}
+		c.bloom = b
+		c.store = bloomStore{Store: c.store, filter: b}
+	}
+	if c.index != nil {
+		err := c.index.load(c)
+		if err != nil {
+			return Cache{}, fmt.Errorf("error loading index: %w", err)
+		}
+	}
+	if c.janitor != nil {
+		go c.janitor.run(c)
+	}
+	return c, nil
+}
+
+// openDir creates the cache directory if it doesn't exist,
+// or, if the cache is read-only, checks that it exists.
+func (c Cache) openDir() error {
+	if c.readOnly {
+		f, err := c.fsys.Open(c.dir)
+		if err != nil {
+			return fmt.Errorf("error opening cache directory: %w", err)
+		}
+		return f.Close()
+	}
 	// Create the directory if it doesn't exist.
 	// MkdirAll creates a directory and any necessary parents and
 	// is a no-op if the directory already exists.
-	err = os.MkdirAll(dir, 0755)
+	err := c.fsys.MkdirAll(c.dir, c.dirMode)
 	if err != nil {
-		return Cache{}, fmt.Errorf("error creating cache directory: %w", err)
+		return fmt.Errorf("error creating cache directory: %w", err)
 	}
-	return Cache{dir: dir}, nil
+	return nil
 }
 
 // Delete removes the cache directory and all its contents.
+// If the cache has another store, it removes all the blobs in the store.
+// It stops the janitor, if any.
+// It returns ErrReadOnly if the cache is read-only.
 func (c Cache) Delete() error {
-	return os.RemoveAll(c.dir)
+	if c.readOnly {
+		return errReadOnly("delete", c.dir)
+	}
+	c.janitor.close()
+	c.index.reset()
+	if _, err := c.dirStore(); err == nil {
+		return c.fsys.RemoveAll(c.dir)
+	}
+	entries, err := c.store.ListEntries()
+	if err != nil {
+		return err
+	}
+	var errs error
+	for _, entry := range entries {
+		errs = errors.Join(errs, c.removeEntryIfExists(entry.Name))
+	}
+	return errs
 }
 
 // Dir returns the directory path of the cache.
@@ -57,10 +238,20 @@ func (c Cache) Dir() string {
 	return c.dir
 }
 
-// Filename returns the filename of a cache entry.
+// Filename returns the filename of a cache entry,
+// relative to the cache directory.
+// If the cache is sharded, the filename includes the shard subdirectories.
 // TODO: Remove Filename from the public API?
 func (c Cache) Filename(key string) string {
-	return fmt.Sprintf("%x.json", sha256.Sum256([]byte(key)))
+	name := hashName(key)
+	if c.filenameFunc != nil {
+		name = c.filenameFunc(key)
+	}
+	filename := name + c.ext
+	if c.sharded {
+		return shardPath(filename)
+	}
+	return filename
 }
 
 // Filepath returns the full path of a cache entry.
@@ -70,20 +261,175 @@ func (c Cache) Filepath(key string) string {
 }
 
 // Set saves a cache entry with a key, value, and duration.
+// A duration of NoExpiry saves an entry that never expires.
+// If the cache has a maximum number of entries, Set evicts the
+// soonest-to-expire entries to stay within it.
 func (c Cache) Set(key string, value []byte, duration time.Duration) error {
+	return c.SetWithMeta(key, value, nil, duration)
+}
+
+// SetWithMeta saves a cache entry with a key, value, metadata, and duration.
+// The metadata is stored with the entry and returned by Read and List,
+// for things like a content type, a source URL, or an ETag.
+func (c Cache) SetWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	done := c.observe("Set", key)
+	err := c.setWithMeta(key, value, meta, duration)
+	done(OperationResult{Bytes: len(value), Err: err})
+	return err
+}
+
+// setWithMeta saves a cache entry with a key, value, metadata, and duration.
+func (c Cache) setWithMeta(key string, value []byte, meta map[string]string, duration time.Duration) error {
 	// Validate the key.
 	if len(key) == 0 {
 		return fmt.Errorf("key cannot be empty")
 	}
-	bytes, err := json.Marshal(Data{
-		Key:    key,
-		Value:  value,
-		Expiry: time.Now().Add(duration),
+	unlock := c.lockVersions(key)
+	defer unlock()
+	return c.setLocked(key, value, meta, duration)
+}
+
+// setLocked saves a cache entry like setWithMeta,
+// with the key already locked if the cache keeps versions.
+func (c Cache) setLocked(key string, value []byte, meta map[string]string, duration time.Duration) error {
+	err := c.keepVersion(c.Filename(key))
+	if err != nil {
+		return fmt.Errorf("error keeping version: %w", err)
+	}
+	now := c.now()
+	err = c.write(Data{
+		Key:       key,
+		Value:     value,
+		Expiry:    c.expiryFrom(now, duration),
+		Meta:      meta,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	err = c.evict(key)
+	if err != nil {
+		return fmt.Errorf("error evicting entries: %w", err)
+	}
+	return nil
+}
+
+// Touch resets the expiry of a cache entry to now plus the duration.
+// A duration of NoExpiry makes the entry never expire.
+// It returns an error if the entry doesn't exist or is expired.
+// It keeps the creation time of the entry.
+func (c Cache) Touch(key string, duration time.Duration) error {
+	rec, err := c.readDecoded(c.Filename(key))
+	if err != nil {
+		return err
+	}
+	if expired(rec.Expiry, c.now()) {
+		return ErrExpired
+	}
+	rec.Expiry = c.expiryFrom(c.now(), duration)
+	return c.write(rec.Data)
+}
+
+// Expire resets the expiry of a cache entry to now plus the duration,
+// like Touch, but rewrites only the entry file,
+// keeping its value encoded as it is on disk.
+// The value isn't decompressed or checked, and a value in a sidecar file,
+// written with SetReader or raw values, isn't read.
+// An encrypted entry is decrypted and sealed again,
+// since its expiry is authenticated with its ciphertext.
+// A duration of NoExpiry makes the entry never expire.
+// It returns an error if the entry doesn't exist or is expired.
+func (c Cache) Expire(key string, duration time.Duration) error {
+	rec, err := c.rewriteHeader(key, func(rec *record) error {
+		now := c.now()
+		if expired(rec.Expiry, now) {
+			return ErrExpired
+		}
+		rec.Expiry = c.expiryFrom(now, duration)
+		return nil
 	})
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(c.Filepath(key), bytes, 0644)
+	c.log(slog.LevelDebug, "updated expiry", "key", key, "expiry", rec.Expiry)
+	return nil
+}
+
+// rewriteHeader updates the on-disk record of a cache entry
+// without decoding its value or reading its sidecar,
+// and returns the updated record.
+// If update returns an error, the entry isn't rewritten.
+func (c Cache) rewriteHeader(key string, update func(rec *record) error) (record, error) {
+	filename := c.Filename(key)
+	contents, err := c.readEntry(filename)
+	if err != nil {
+		return record{}, fmt.Errorf("error reading data: %w", err)
+	}
+	c.stats.read(len(contents))
+	rec, err := unmarshal(contents)
+	if err != nil {
+		return record{}, fmt.Errorf("error unmarshaling data: %w", err)
+	}
+	rec, err = upgrade(rec)
+	if err != nil {
+		return record{}, err
+	}
+	rec, err = c.updateHeader(rec, update)
+	if err != nil {
+		return record{}, err
+	}
+	updated, err := c.marshal(rec)
+	if err != nil {
+		return record{}, err
+	}
+	_, err = c.store.WriteEntry(filename, bytes.NewReader(updated))
+	if err != nil {
+		return record{}, err
+	}
+	if entry, ok := c.index.get(key); ok {
+		data := rec.Data
+		// The key of an encrypted record is sealed.
+		data.Key = key
+		c.index.set(data, entry.size-int64(len(contents))+int64(len(updated)), filename)
+	}
+	c.stats.write(len(updated))
+	return rec, nil
+}
+
+// updateHeader updates the header of a record.
+// If the record is encrypted and the update changes the fields
+// authenticated with its ciphertext, it is decrypted and sealed again.
+func (c Cache) updateHeader(rec record, update func(rec *record) error) (record, error) {
+	if rec.Sealed == nil {
+		err := update(&rec)
+		return rec, err
+	}
+	before, err := additionalData(rec)
+	if err != nil {
+		return record{}, err
+	}
+	updated := rec
+	err = update(&updated)
+	if err != nil {
+		return record{}, err
+	}
+	after, err := additionalData(updated)
+	if err != nil {
+		return record{}, err
+	}
+	if bytes.Equal(before, after) {
+		return updated, nil
+	}
+	opened, err := c.decrypt(rec)
+	if err != nil {
+		return record{}, fmt.Errorf("error decrypting entry: %w", err)
+	}
+	updated.Key, updated.Value, updated.Meta = opened.Key, opened.Value, opened.Meta
+	updated, err = c.encrypt(updated)
+	if err != nil {
+		return record{}, fmt.Errorf("error encrypting entry: %w", err)
+	}
+	return updated, nil
 }
 
 // Read reads a cache entry from disk and returns all its data.
@@ -93,26 +439,91 @@ func (c Cache) Read(key string) (Data, error) {
 }
 
 // Has checks if a cache entry exists on disk.
+// If the cache is indexed, it checks the index instead.
 func (c Cache) Has(key string) bool {
-	_, err := os.Stat(c.Filepath(key))
-	return err == nil
+	if c.index != nil {
+		_, ok := c.index.get(key)
+		return ok
+	}
+	unlock := c.commits.rlock()
+	defer unlock()
+	r, err := c.store.ReadEntry(c.Filename(key))
+	if err != nil {
+		return false
+	}
+	r.Close()
+	return true
 }
 
 // Get gets a cache entry from disk and returns the value only.
 // It returns an error if the entry is expired.
 func (c Cache) Get(key string) ([]byte, error) {
+	done := c.observe("Get", key)
+	value, err := c.get(key)
+	done(OperationResult{Bytes: len(value), Hit: err == nil, Err: err})
+	return value, err
+}
+
+// get gets a cache entry from disk and returns the value only.
+func (c Cache) get(key string) ([]byte, error) {
+	value, _, err := c.getWithTTL(key)
+	return value, err
+}
+
+// GetWithTTL gets a cache entry from disk and returns its value
+// and remaining lifetime, such as to set the max-age of an HTTP response.
+// The lifetime is NoExpiry if the entry never expires.
+// It returns an error if the entry is expired.
+func (c Cache) GetWithTTL(key string) ([]byte, time.Duration, error) {
+	done := c.observe("GetWithTTL", key)
+	value, ttl, err := c.getWithTTL(key)
+	done(OperationResult{Bytes: len(value), Hit: err == nil, Err: err})
+	return value, ttl, err
+}
+
+// getWithTTL gets a cache entry from disk and returns its value
+// and remaining lifetime.
+func (c Cache) getWithTTL(key string) ([]byte, time.Duration, error) {
+	entry, now, err := c.getEntry(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if entry.Expiry.IsZero() {
+		return entry.Value, NoExpiry, nil
+	}
+	return entry.Value, entry.Expiry.Sub(now), nil
+}
+
+// getEntry gets a cache entry from disk, records the hit or miss,
+// and returns the entry and when it was read.
+// It returns an error if the entry is expired.
+func (c Cache) getEntry(key string) (Data, time.Time, error) {
 	entry, err := c.Read(key)
 	if err != nil {
-		return nil, err
+		c.stats.miss()
+		c.log(slog.LevelDebug, "cache miss", "key", key)
+		return Data{}, time.Time{}, err
 	}
-	if time.Now().After(entry.Expiry) {
-		return nil, fmt.Errorf("cache expired")
+	now := c.now()
+	if expired(entry.Expiry, now) {
+		c.stats.expiredHit()
+		c.log(slog.LevelDebug, "cache expired", "key", key, "expiry", entry.Expiry)
+		return Data{}, time.Time{}, ErrExpired
 	}
-	return entry.Value, nil
+	c.stats.hit()
+	c.log(slog.LevelDebug, "cache hit", "key", key, "bytes", len(entry.Value))
+	c.recordAccess(key, entry)
+	return entry, now, nil
 }
 
 // Expiry returns the expiry time of a cache entry.
+// It returns the zero time if the entry doesn't exist or never expires.
+// If the cache is indexed, it reads the expiry from the index.
 func (c Cache) Expiry(key string) time.Time {
+	if c.index != nil {
+		entry, _ := c.index.get(key)
+		return entry.expiry
+	}
 	entry, err := c.Read(key)
 	if err != nil {
 		return time.Time{}
@@ -120,19 +531,51 @@ func (c Cache) Expiry(key string) time.Time {
 	return entry.Expiry
 }
 
-// IsExpired returns true if a cache entry is expired.
+// IsExpired returns true if a cache entry is expired or doesn't exist.
+// Entries that never expire are never expired.
 func (c Cache) IsExpired(key string) bool {
-	return time.Now().After(c.Expiry(key))
+	if c.index != nil {
+		entry, ok := c.index.get(key)
+		return !ok || expired(entry.expiry, c.now())
+	}
+	entry, err := c.Read(key)
+	if err != nil {
+		return true
+	}
+	return expired(entry.Expiry, c.now())
 }
 
+// list reads all cache entries.
+// If the cache is indexed, it reads the files named in the index
+// instead of reading the directory.
 func (c Cache) list() ([]Data, error) {
-	dirEntries, err := os.ReadDir(c.dir)
+	if c.index == nil {
+		return c.listDir()
+	}
+	filenames, err := c.filenames()
+	if err != nil {
+		return nil, err
+	}
+	var list []Data
+	for _, filename := range filenames {
+		entry, err := c.readFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error reading entry: %w", err)
+		}
+		list = append(list, entry)
+	}
+	return list, nil
+}
+
+// listDir reads all cache entries from the directory.
+func (c Cache) listDir() ([]Data, error) {
+	filenames, err := c.readDir()
 	if err != nil {
 		return nil, fmt.Errorf("error reading directory: %w", err)
 	}
 	var list []Data
-	for _, dirEntry := range dirEntries {
-		entry, err := c.readDirEntry(dirEntry)
+	for _, filename := range filenames {
+		entry, err := c.readFile(filename)
 		if err != nil {
 			return nil, fmt.Errorf("error reading entry: %w", err)
 		}
@@ -141,24 +584,53 @@ func (c Cache) list() ([]Data, error) {
 	return list, nil
 }
 
+// ListOption sorts or filters a list of cache entries,
+// such as SortByKey or FilterExpired.
+// It returns the sorted or filtered list,
+// which may share the underlying array of the original.
+// Sort functions that sort in place, as list options did
+// before filters were added, can be wrapped with Sort.
+type ListOption func([]Data) []Data
+
+// Sort returns a list option that sorts cache entries in place
+// with a sort function.
+func Sort(sort func([]Data)) ListOption {
+	return func(entries []Data) []Data {
+		sort(entries)
+		return entries
+	}
+}
+
 // List returns a list of cache entry data.
-// It accepts sorting options.
-func (c Cache) List(options ...func([]Data)) ([]Data, error) {
+// It accepts sorting and filtering options, applied in order.
+func (c Cache) List(options ...ListOption) ([]Data, error) {
 	list, err := c.list()
 	if err != nil {
 		return nil, err
 	}
-	// Apply the sorting options.
+	return applyListOptions(list, options), nil
+}
+
+// applyListOptions applies sorting and filtering options to a list in order.
+func applyListOptions(list []Data, options []ListOption) []Data {
 	for _, option := range options {
-		option(list)
+		list = option(list)
 	}
-	return list, nil
+	return list
 }
 
 // SortByExpiry is a sort function to sort cache entries by expiry time.
-func SortByExpiry(entries []Data) {
-	slices.SortFunc(entries, func(a, b Data) int {
+// Entries that never expire sort last.
+// The sort is stable, so it can follow another sort function.
+func SortByExpiry(entries []Data) []Data {
+	slices.SortStableFunc(entries, func(a, b Data) int {
 		switch {
+		case a.Expiry.IsZero() && b.Expiry.IsZero():
+			return 0
+		case a.Expiry.IsZero():
+			return 1
+		case b.Expiry.IsZero():
+			return -1
 		case a.Expiry.Before(b.Expiry):
 			return -1
 		case a.Expiry.After(b.Expiry):
@@ -167,98 +639,293 @@ func SortByExpiry(entries []Data) {
 			return 0
 		}
 	})
+	return entries
 }
 
 // SortByKey is a sort function to sort cache entries by key.
-func SortByKey(entries []Data) {
+func SortByKey(entries []Data) []Data {
 	slices.SortFunc(entries, func(a, b Data) int {
 		return strings.Compare(a.Key, b.Key)
 	})
+	return entries
 }
 
 // SortByValue is a sort function to sort cache entries by value.
-func SortByValue(entries []Data) {
-	slices.SortFunc(entries, func(a, b Data) int {
+// The sort is stable, so it can follow another sort function.
+func SortByValue(entries []Data) []Data {
+	slices.SortStableFunc(entries, func(a, b Data) int {
 		return strings.Compare(string(a.Value), string(b.Value))
 	})
+	return entries
+}
+
+// SortByExpiryDesc is a sort function to sort cache entries
+// by expiry time, latest first.
+// Entries that never expire sort first.
+func SortByExpiryDesc(entries []Data) []Data {
+	return Desc(SortByExpiry)(entries)
+}
+
+// SortBySize is a sort function to sort cache entries by value size.
+// The sort is stable, so it can follow another sort function.
+func SortBySize(entries []Data) []Data {
+	slices.SortStableFunc(entries, func(a, b Data) int {
+		return cmp.Compare(len(a.Value), len(b.Value))
+	})
+	return entries
+}
+
+// SortByCreated is a sort function to sort cache entries by creation time.
+// Entries written before creation times were recorded sort first.
+// The sort is stable, so it can follow another sort function.
+func SortByCreated(entries []Data) []Data {
+	slices.SortStableFunc(entries, func(a, b Data) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return entries
+}
+
+// SortByAccessed is a sort function to sort cache entries
+// by last access time, least recently accessed first.
+// Entries never accessed sort first.
+// The sort is stable, so it can follow another sort function.
+func SortByAccessed(entries []Data) []Data {
+	slices.SortStableFunc(entries, func(a, b Data) int {
+		return a.LastAccessed.Compare(b.LastAccessed)
+	})
+	return entries
+}
+
+// Desc returns a sort function that sorts cache entries
+// in the reverse order of another sort function.
+func Desc(sort ListOption) ListOption {
+	return func(entries []Data) []Data {
+		entries = sort(entries)
+		slices.Reverse(entries)
+		return entries
+	}
 }
 
 // Flush deletes all cache entries from disk.
 func (c Cache) Flush() error {
-	dirEntries, err := os.ReadDir(c.dir)
+	if c.readOnly {
+		return errReadOnly("flush", c.dir)
+	}
+	filenames, err := c.readDir()
 	if err != nil {
 		return err
 	}
 	var errs error
-	for _, dirEntry := range dirEntries {
-		err = c.removeDirEntry(dirEntry)
+	for _, filename := range filenames {
+		data := c.hookData(filename, "", false)
+		err = c.removeFile(filename)
 		if err != nil {
 			errs = errors.Join(errs, err)
+			continue
 		}
+		c.stats.remove(1)
+		c.callHooks(data, false)
 	}
+	c.index.reset()
 	if errs != nil {
 		return errs
 	}
 	return nil
 }
 
-// Clean deletes expired cache entries from disk.
+// Clean deletes expired cache entries from disk,
+// removing up to DefaultMaxParallel entries at a time.
+// If the cache is indexed, it finds expired entries from the index.
+// See CleanContext to cancel a clean or change its concurrency.
 func (c Cache) Clean() error {
-	var errs error
+	return c.CleanContext(c.context())
+}
+
+// expiredKeys returns the keys of expired cache entries.
+func (c Cache) expiredKeys() ([]string, error) {
+	now := c.now()
+	if c.index != nil {
+		return c.index.expired(now), nil
+	}
 	list, err := c.list()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var wg sync.WaitGroup
-	errorsChan := make(chan error, len(list))
+	var keys []string
 	for _, data := range list {
-		wg.Add(1)
-		go func(data Data) {
-			defer wg.Done()
-			if time.Now().Before(data.Expiry) {
-				return
-			}
-			err := c.Remove(data.Key)
-			if err != nil {
-				errorsChan <- err
-			}
-		}(data)
-	}
-	wg.Wait()
-	close(errorsChan)
-	for err := range errorsChan {
-		if err != nil {
-			errs = errors.Join(errs, err)
+		if !expired(data.Expiry, now) {
+			continue
 		}
+		keys = append(keys, data.Key)
 	}
-	return errs
+	return keys, nil
 }
 
 // Remove deletes a cache entry from disk.
 func (c Cache) Remove(key string) error {
-	return os.Remove(c.Filepath(key))
+	done := c.observe("Remove", key)
+	err := c.remove(key, false)
+	done(OperationResult{Err: err})
+	return err
+}
+
+// remove deletes a cache entry from disk and calls the removal hooks,
+// including the expiry hook if the entry is removed because it expired.
+func (c Cache) remove(key string, expired bool) error {
+	filename := c.Filename(key)
+	data := c.hookData(filename, key, expired)
+	err := c.removeFile(filename)
+	if err != nil {
+		return err
+	}
+	c.index.remove(key)
+	c.stats.remove(1)
+	reason := "removed"
+	if expired {
+		reason = "expired"
+	}
+	c.log(slog.LevelDebug, "removed entry", "key", key, "reason", reason)
+	c.callHooks(data, expired)
+	return nil
+}
+
+// filenames returns the filenames of the cache entry files,
+// from the index, if any, or from the directory.
+func (c Cache) filenames() ([]string, error) {
+	if c.index != nil {
+		return c.index.filenames(), nil
+	}
+	return c.readDir()
 }
 
-// readDirEntry reads an entry from disk.
-// It differs from the Read method in that it takes a fs.DirEntry instead of a key.
-// It's not part of the public API because the filename is not known outside the package.
-func (c Cache) readDirEntry(dirEntry fs.DirEntry) (Data, error) {
-	return c.readFile(dirEntry.Name())
+// readDir returns the filenames of the cache entry files,
+// relative to the cache directory.
+// It skips other files in the store, such as sidecar and temporary files.
+func (c Cache) readDir() ([]string, error) {
+	entries, err := c.store.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+	var filenames []string
+	for _, entry := range entries {
+		if c.isEntry(entry.Name) {
+			filenames = append(filenames, entry.Name)
+		}
+	}
+	return filenames, nil
+}
+
+// isEntry returns true if the name of a blob in the store
+// is the filename of a cache entry, rather than a sidecar,
+// a temporary file, or a previous version.
+func (c Cache) isEntry(name string) bool {
+	return filepath.Ext(name) == c.ext && !c.isVersion(name)
+}
+
+// sizes returns the sizes in bytes of the cache entries by filename,
+// including their sidecars.
+func (c Cache) sizes() (map[string]int64, error) {
+	files, err := c.entryFiles()
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(files))
+	for filename, file := range files {
+		sizes[filename] = file.Size
+	}
+	return sizes, nil
+}
+
+// entryFiles returns the entry files in the store by filename,
+// with their sizes including their sidecars.
+func (c Cache) entryFiles() (map[string]StoreEntry, error) {
+	entries, err := c.store.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]StoreEntry)
+	for _, entry := range entries {
+		if c.isEntry(entry.Name) {
+			files[entry.Name] = entry
+		}
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name) != rawExt {
+			continue
+		}
+		filename := strings.TrimSuffix(entry.Name, rawExt) + c.ext
+		if file, ok := files[filename]; ok {
+			file.Size += entry.Size
+			files[filename] = file
+		}
+	}
+	return files, nil
 }
 
 // readFile reads a cache entry from disk.
 // It takes a filename instead of a key.
 func (c Cache) readFile(filename string) (Data, error) {
-	bytes, err := os.ReadFile(c.filepath(filename))
+	rec, err := c.readDecoded(filename)
+	if err != nil {
+		return Data{}, err
+	}
+	return rec.Data, nil
+}
+
+// readDecoded reads the on-disk record of a cache entry,
+// including its sidecar, and decodes its data.
+func (c Cache) readDecoded(filename string) (record, error) {
+	rec, err := c.readRecord(filename)
+	if err != nil {
+		return record{}, err
+	}
+	if rec.Raw {
+		rec.Value, err = c.readEntry(c.sidecar(filename))
+		if err != nil {
+			return record{}, fmt.Errorf("error reading data: %w", err)
+		}
+		c.stats.read(len(rec.Value))
+	}
+	rec.Data, err = c.decode(rec)
 	if err != nil {
-		return Data{}, fmt.Errorf("error reading data: %w", err)
+		c.log(slog.LevelWarn, "error decoding entry", "filename", filename, "error", err)
+		return record{}, err
 	}
-	var entry Data
-	err = json.Unmarshal(bytes, &entry)
+	return rec, nil
+}
+
+// readRecord reads the on-disk record of a cache entry
+// without reading its sidecar or decoding its value.
+func (c Cache) readRecord(filename string) (record, error) {
+	bytes, err := c.readEntry(filename)
+	if err != nil {
+		return record{}, fmt.Errorf("error reading data: %w", err)
+	}
+	c.stats.read(len(bytes))
+	rec, err := unmarshal(bytes)
 	if err != nil {
-		return Data{}, fmt.Errorf("error unmarshaling data: %w", err)
+		c.log(slog.LevelWarn, "error unmarshaling entry", "filename", filename, "error", err)
+		return record{}, fmt.Errorf("error unmarshaling data: %w", err)
 	}
-	return entry, nil
+	return upgrade(rec)
+}
+
+// readHeader reads the on-disk record of a cache entry
+// without reading its sidecar, and decodes its data.
+// The value of an entry with a sidecar is empty.
+func (c Cache) readHeader(filename string) (record, error) {
+	rec, err := c.readRecord(filename)
+	if err != nil {
+		return record{}, err
+	}
+	if rec.Raw {
+		return rec, nil
+	}
+	rec.Data, err = c.decode(rec)
+	if err != nil {
+		return record{}, err
+	}
+	return rec, nil
 }
 
 // filepath returns the full path of a cache entry.
@@ -266,13 +933,71 @@ func (c Cache) filepath(filename string) string {
 	return filepath.Join(c.dir, filename)
 }
 
-// removeFile deletes a cache entry from disk.
-func (c Cache) removeFile(filename string) error {
-	return os.Remove(c.filepath(filename))
+// hashName returns the default filename of a key, without the extension.
+// It is the hex-encoded SHA-256 hash of the key.
+func hashName(key string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+}
+
+// expiryFrom returns the expiry time for a duration from now,
+// clamped to the minimum and maximum TTL of the cache, if any.
+// It returns the zero time for NoExpiry.
+func (c Cache) expiryFrom(now time.Time, duration time.Duration) time.Time {
+	duration = c.clampTTL(duration)
+	if duration == NoExpiry {
+		return time.Time{}
+	}
+	return now.Add(duration)
 }
 
-// removeDirEntry deletes a cache entry from disk.
-// It differs from the Remove method in that it takes a fs.DirEntry instead of a key.
-func (c Cache) removeDirEntry(dirEntry fs.DirEntry) error {
-	return c.removeFile(dirEntry.Name())
+// expired returns true if the expiry time is before now.
+// The zero expiry time never expires.
+func expired(expiry time.Time, now time.Time) bool {
+	return !expiry.IsZero() && now.After(expiry)
+}
+
+// write saves a cache entry to disk and updates the index.
+func (c Cache) write(entry Data) error {
+	if c.rawValues {
+		return c.writeRaw(entry, bytes.NewReader(entry.Value))
+	}
+	rec, err := c.encode(entry)
+	if err != nil {
+		return err
+	}
+	contents, err := c.marshal(rec)
+	if err != nil {
+		return err
+	}
+	filename := c.Filename(entry.Key)
+	unlock := c.files.lock(filename)
+	_, err = c.store.WriteEntry(filename, bytes.NewReader(contents))
+	if err == nil {
+		// Remove the sidecar of a previously streamed value, if any.
+		err = c.removeEntryIfExists(c.sidecar(filename))
+	}
+	unlock()
+	if err != nil {
+		return err
+	}
+	c.index.set(entry, int64(len(contents)), filename)
+	c.stats.write(len(contents))
+	c.log(slog.LevelDebug, "wrote entry", "key", entry.Key, "bytes", len(contents), "expiry", entry.Expiry)
+	return nil
+}
+
+// removeFile deletes a cache entry from disk,
+// including its sidecar and previous versions.
+func (c Cache) removeFile(filename string) error {
+	unlock := c.files.lock(filename)
+	defer unlock()
+	err := c.store.RemoveEntry(filename)
+	if err != nil {
+		return err
+	}
+	err = c.removeEntryIfExists(c.sidecar(filename))
+	if err != nil {
+		return err
+	}
+	return c.removeVersions(filename)
 }
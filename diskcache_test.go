@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"fmt"
+	"maps"
 	"os"
 	"path"
 	"sync"
@@ -127,6 +128,193 @@ func TestDiskCache(t *testing.T) {
 		}
 	})
 
+	t.Run("TestGetWithTTL", func(t *testing.T) {
+		err := cache.Set("ttl", []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		value, ttl, err := cache.GetWithTTL("ttl")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(value) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", value)
+		}
+		if ttl <= 59*time.Second || ttl > 1*time.Minute {
+			t.Fatalf("Expected TTL of about 1m, got %v", ttl)
+		}
+		err = cache.Set("ttl", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, ttl, err = cache.GetWithTTL("ttl")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if ttl != diskcache.NoExpiry {
+			t.Fatalf("Expected NoExpiry, got %v", ttl)
+		}
+		err = cache.Set("ttl", []byte("value"), -1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, _, err = cache.GetWithTTL("ttl")
+		if err == nil {
+			t.Fatalf("Expected error getting expired cache")
+		}
+	})
+
+	t.Run("TestMeta", func(t *testing.T) {
+		key := "meta"
+		meta := map[string]string{"Content-Type": "application/json", "ETag": `"abc"`}
+		err := cache.SetWithMeta(key, []byte("{}"), meta, 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		data, err := cache.Read(key)
+		if err != nil {
+			t.Fatalf("Error loading cache: %v", err)
+		}
+		if !maps.Equal(data.Meta, meta) {
+			t.Fatalf("Expected cache meta to be %v, got %v", meta, data.Meta)
+		}
+		err = cache.Touch(key, 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error touching cache: %v", err)
+		}
+		data, err = cache.Read(key)
+		if err != nil {
+			t.Fatalf("Error loading cache: %v", err)
+		}
+		if !maps.Equal(data.Meta, meta) {
+			t.Fatalf("Expected cache meta to survive Touch, got %v", data.Meta)
+		}
+		_ = cache.Remove(key)
+	})
+
+	t.Run("TestNoExpiry", func(t *testing.T) {
+		key := "permanent"
+		err := cache.Set(key, []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", string(got))
+		}
+		if !cache.Expiry(key).IsZero() {
+			t.Fatalf("Expected cache expiry to be zero")
+		}
+		if cache.IsExpired(key) {
+			t.Fatalf("Expected cache to not be expired")
+		}
+		if !cache.IsExpired("missing") {
+			t.Fatalf("Expected missing cache to be expired")
+		}
+		err = cache.Clean()
+		if err != nil {
+			t.Fatalf("Error cleaning cache: %v", err)
+		}
+		if !cache.Has(key) {
+			t.Fatalf("Expected key to exist after clean")
+		}
+		_ = cache.Remove(key)
+	})
+
+	t.Run("TestTouch", func(t *testing.T) {
+		key := "touch"
+		err := cache.Set(key, []byte("value"), 1*time.Second)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.Touch(key, 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error touching cache: %v", err)
+		}
+		expiry := cache.Expiry(key)
+		if expiry.Before(time.Now().Add(59 * time.Minute)) {
+			t.Fatalf("Expected cache expiry to be about 1 hour away, got %s", expiry)
+		}
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", string(got))
+		}
+		err = cache.Touch("expired", 1*time.Hour)
+		if err == nil {
+			t.Fatalf("Expected error touching expired cache")
+		}
+		err = cache.Touch("missing", 1*time.Hour)
+		if err == nil {
+			t.Fatalf("Expected error touching missing cache")
+		}
+		_ = cache.Remove(key)
+	})
+
+	t.Run("TestExpire", func(t *testing.T) {
+		for name, options := range map[string][]diskcache.Option{
+			"TestJSON":        nil,
+			"TestBinary":      {diskcache.WithFormat(diskcache.Binary)},
+			"TestCompression": {diskcache.WithCompression(diskcache.Gzip)},
+			"TestEncryption":  {diskcache.WithEncryption(bytes.Repeat([]byte("k"), 32))},
+			"TestRawValues":   {diskcache.WithRawValues(), diskcache.WithIndex()},
+		} {
+			t.Run(name, func(t *testing.T) {
+				cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), options...)
+				if err != nil {
+					t.Fatalf("Error creating cache: %v", err)
+				}
+				err = cache.Set("key", []byte("value"), 1*time.Second)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+				size, err := cache.Size()
+				if err != nil {
+					t.Fatalf("Error getting size: %v", err)
+				}
+				err = cache.Expire("key", 1*time.Hour)
+				if err != nil {
+					t.Fatalf("Error updating expiry: %v", err)
+				}
+				expiry := cache.Expiry("key")
+				if expiry.Before(time.Now().Add(59 * time.Minute)) {
+					t.Fatalf("Expected cache expiry to be about 1 hour away, got %s", expiry)
+				}
+				got, err := cache.Get("key")
+				if err != nil {
+					t.Fatalf("Error getting cache: %v", err)
+				}
+				if string(got) != "value" {
+					t.Fatalf("Expected cache value to be value, got %s", string(got))
+				}
+				err = cache.Expire("key", diskcache.NoExpiry)
+				if err != nil {
+					t.Fatalf("Error updating expiry: %v", err)
+				}
+				if !cache.Expiry("key").IsZero() {
+					t.Fatalf("Expected no expiry, got %s", cache.Expiry("key"))
+				}
+				newSize, err := cache.Size()
+				if err != nil {
+					t.Fatalf("Error getting size: %v", err)
+				}
+				// A zero expiry encodes shorter than the expiry it replaced.
+				if newSize > size {
+					t.Fatalf("Expected size at most %d, got %d", size, newSize)
+				}
+				err = cache.Expire("missing", 1*time.Hour)
+				if err == nil {
+					t.Fatalf("Expected error updating missing cache")
+				}
+			})
+		}
+	})
+
 	t.Run("TestUpdate", func(t *testing.T) {
 		key := "testkey"
 		oldvalue := []byte("oldvalue")
@@ -273,6 +461,48 @@ func TestDiskCache(t *testing.T) {
 		if string(data[0].Key) != "key2" {
 			t.Fatalf("Expected key2 to be first, got %s", data[0].Key)
 		}
+
+		data, err = cache.List(diskcache.SortByExpiryDesc)
+		if err != nil {
+			t.Fatalf("Error sorting cache: %v", err)
+		}
+		if string(data[0].Key) != "key1" {
+			t.Fatalf("Expected key1 to be first, got %s", data[0].Key)
+		}
+
+		data, err = cache.List(diskcache.SortByCreated)
+		if err != nil {
+			t.Fatalf("Error sorting cache: %v", err)
+		}
+		for i, d := range data {
+			if d.CreatedAt.IsZero() {
+				t.Fatalf("Expected creation time for %s", d.Key)
+			}
+			if i > 0 && d.CreatedAt.Before(data[i-1].CreatedAt) {
+				t.Fatalf("Expected %s to be created after %s", d.Key, data[i-1].Key)
+			}
+		}
+
+		data, err = cache.List(diskcache.Desc(diskcache.SortByKey))
+		if err != nil {
+			t.Fatalf("Error sorting cache: %v", err)
+		}
+		if string(data[0].Key) != "key3" {
+			t.Fatalf("Expected key3 to be first, got %s", data[0].Key)
+		}
+
+		err = cache.Set("key4", []byte("longest value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		data, err = cache.List(diskcache.SortByKey, diskcache.Desc(diskcache.SortBySize))
+		if err != nil {
+			t.Fatalf("Error sorting cache: %v", err)
+		}
+		if string(data[0].Key) != "key4" {
+			t.Fatalf("Expected key4 to be first, got %s", data[0].Key)
+		}
+		_ = cache.Remove("key4")
 	})
 
 	t.Run("TestClean", func(t *testing.T) {
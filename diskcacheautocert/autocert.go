@@ -0,0 +1,71 @@
+// Package diskcacheautocert adapts a disk cache to the Cache interface of
+// golang.org/x/crypto/acme/autocert, so it can store the certificates
+// and account key of autocert-managed TLS servers:
+//
+//	certs, err := diskcacheautocert.New(cache)
+//	m := &autocert.Manager{Cache: certs, Prompt: autocert.AcceptTOS}
+package diskcacheautocert
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/jluckyiv/diskcache"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Namespace is the namespace of the disk cache the entries are stored in.
+const Namespace = "autocert"
+
+// Cache is an autocert.Cache backed by a disk cache.
+// Entries never expire, since autocert renews certificates itself.
+type Cache struct {
+	cache diskcache.Cache
+}
+
+var _ autocert.Cache = Cache{}
+
+// New returns an autocert.Cache that stores entries in the Namespace
+// namespace of a disk cache, in files only the owner can read and write,
+// since they hold private keys.
+func New(cache diskcache.Cache) (Cache, error) {
+	ns, err := cache.Namespace(Namespace, diskcache.WithFileMode(0600), diskcache.WithDirMode(0700))
+	if err != nil {
+		return Cache{}, err
+	}
+	return Cache{cache: ns}, nil
+}
+
+// Get returns the data saved under a name,
+// or autocert.ErrCacheMiss if there is none.
+func (c Cache) Get(ctx context.Context, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, err := c.cache.Get(name)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, diskcache.ErrExpired) {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, err
+}
+
+// Put saves data under a name.
+func (c Cache) Put(ctx context.Context, name string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.cache.Set(name, data, diskcache.NoExpiry)
+}
+
+// Delete removes the data saved under a name, if any.
+func (c Cache) Delete(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := c.cache.Remove(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package diskcacheautocert_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcacheautocert"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestCache(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	certs, err := diskcacheautocert.New(cache)
+	if err != nil {
+		t.Fatalf("Error creating autocert cache: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("TestMiss", func(t *testing.T) {
+		_, err := certs.Get(ctx, "example.com")
+		if !errors.Is(err, autocert.ErrCacheMiss) {
+			t.Fatalf("Expected ErrCacheMiss, got %v", err)
+		}
+	})
+
+	t.Run("TestPutAndGet", func(t *testing.T) {
+		err := certs.Put(ctx, "example.com", []byte("certificate"))
+		if err != nil {
+			t.Fatalf("Error putting certificate: %v", err)
+		}
+		got, err := certs.Get(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("Error getting certificate: %v", err)
+		}
+		if string(got) != "certificate" {
+			t.Fatalf("Expected certificate, got %s", got)
+		}
+	})
+
+	t.Run("TestPermissions", func(t *testing.T) {
+		dir := filepath.Join(cache.Dir(), diskcacheautocert.Namespace)
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatalf("Error reading namespace: %v", err)
+		}
+		if info.Mode().Perm() != 0700 {
+			t.Fatalf("Expected directory mode 0700, got %v", info.Mode().Perm())
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("Error reading namespace: %v", err)
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				t.Fatalf("Error reading file: %v", err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Fatalf("Expected file mode 0600, got %v", info.Mode().Perm())
+			}
+		}
+		if cache.Has("example.com") {
+			t.Fatalf("Expected the certificate to be outside the parent cache")
+		}
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		err := certs.Delete(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("Error deleting certificate: %v", err)
+		}
+		_, err = certs.Get(ctx, "example.com")
+		if !errors.Is(err, autocert.ErrCacheMiss) {
+			t.Fatalf("Expected ErrCacheMiss, got %v", err)
+		}
+		err = certs.Delete(ctx, "example.com")
+		if err != nil {
+			t.Fatalf("Error deleting a missing certificate: %v", err)
+		}
+	})
+
+	t.Run("TestCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := certs.Put(ctx, "example.com", []byte("certificate"))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
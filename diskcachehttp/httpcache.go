@@ -0,0 +1,45 @@
+// Package diskcachehttp caches HTTP responses in disk caches
+// and adapts disk caches to HTTP caching libraries.
+package diskcachehttp
+
+import (
+	"github.com/jluckyiv/diskcache"
+)
+
+// Cache adapts a disk cache to the Cache interface of
+// github.com/gregjones/httpcache, so it can back an httpcache.Transport:
+//
+//	transport := httpcache.NewTransport(diskcachehttp.New(cache))
+//
+// Responses are saved for the default duration of the disk cache,
+// set with diskcache.WithDefaultTTL, which is NoExpiry by default,
+// since the transport decides whether they are fresh.
+// The interface has no errors, so Set and Delete drop them,
+// and Get reports a miss.
+type Cache struct {
+	cache diskcache.Cache
+}
+
+// New returns an adapter for a disk cache.
+func New(cache diskcache.Cache) Cache {
+	return Cache{cache: cache}
+}
+
+// Get returns the response saved under a key, if any.
+func (c Cache) Get(key string) ([]byte, bool) {
+	value, err := c.cache.Get(key)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set saves a response under a key.
+func (c Cache) Set(key string, responseBytes []byte) {
+	_ = c.cache.SetDefault(key, responseBytes)
+}
+
+// Delete removes the response saved under a key.
+func (c Cache) Delete(key string) {
+	_ = c.cache.Remove(key)
+}
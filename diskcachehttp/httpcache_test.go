@@ -0,0 +1,56 @@
+package diskcachehttp_test
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcachehttp"
+)
+
+// httpCache is the Cache interface of github.com/gregjones/httpcache.
+type httpCache interface {
+	Get(key string) (responseBytes []byte, ok bool)
+	Set(key string, responseBytes []byte)
+	Delete(key string)
+}
+
+var _ httpCache = diskcachehttp.Cache{}
+
+func TestCache(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithDefaultTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	adapter := diskcachehttp.New(cache)
+
+	t.Run("TestMiss", func(t *testing.T) {
+		_, ok := adapter.Get("https://example.com/")
+		if ok {
+			t.Fatalf("Expected a miss")
+		}
+	})
+
+	t.Run("TestSetAndGet", func(t *testing.T) {
+		adapter.Set("https://example.com/", []byte("HTTP/1.1 200 OK\r\n\r\n"))
+		got, ok := adapter.Get("https://example.com/")
+		if !ok || string(got) != "HTTP/1.1 200 OK\r\n\r\n" {
+			t.Fatalf("Expected the saved response, got %q, %v", got, ok)
+		}
+		ttl := time.Until(cache.Expiry("https://example.com/"))
+		if ttl < 59*time.Minute || ttl > time.Hour {
+			t.Fatalf("Expected the default TTL, got %s", ttl)
+		}
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		adapter.Delete("https://example.com/")
+		_, ok := adapter.Get("https://example.com/")
+		if ok {
+			t.Fatalf("Expected a miss after Delete")
+		}
+		// Deleting a missing key is a no-op.
+		adapter.Delete("https://example.com/")
+	})
+}
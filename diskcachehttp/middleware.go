@@ -0,0 +1,153 @@
+package diskcachehttp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// KeyFunc returns the cache key of a request,
+// or the empty string if its response shouldn't be cached.
+type KeyFunc func(r *http.Request) string
+
+// URLKey is the KeyFunc of requests by host and request URI,
+// such as example.com/page?q=1.
+func URLKey(r *http.Request) string {
+	return r.Host + r.URL.RequestURI()
+}
+
+// Middleware returns middleware that caches the responses of a handler
+// to GET requests in a disk cache, keyed by keyFunc, or URLKey if nil.
+// The first response to a key is written through to the client while
+// it's recorded, and later requests for the key are served the cached
+// status, headers, and body, with the X-Cache header set to HIT.
+// If ttl is positive, responses are cached for ttl. Otherwise, they're
+// cached for the lifetime given by their Cache-Control max-age or
+// Expires header, like Transport.
+// Requests with a Cookie or Authorization header bypass the cache,
+// since their responses may be meant for one client.
+// Responses with Cache-Control no-store or private, that set cookies,
+// or that vary by request headers, aren't cached.
+func Middleware(cache diskcache.Cache, keyFunc KeyFunc, ttl time.Duration) func(http.Handler) http.Handler {
+	if keyFunc == nil {
+		keyFunc = URLKey
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.Header.Get("Cookie") != "" || r.Header.Get("Authorization") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := keyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if serveCached(w, r, cache, key) {
+				return
+			}
+			w.Header().Set(CacheHeader, "MISS")
+			rec := &recorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			rec.save(cache, key, ttl)
+		})
+	}
+}
+
+// serveCached writes the cached response of a key, if any,
+// and returns true if it did.
+func serveCached(w http.ResponseWriter, r *http.Request, cache diskcache.Cache, key string) bool {
+	dump, err := cache.Get(key)
+	if err != nil {
+		return false
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(dump)), r)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false
+	}
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(CacheHeader, "HIT")
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+	return true
+}
+
+// recorder is an http.ResponseWriter that records
+// the response it writes through to a client.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status and headers of the response and writes them.
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status != 0 {
+		return
+	}
+	rec.status = status
+	rec.header = rec.Header().Clone()
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Write records part of the body of the response and writes it.
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	rec.body.Write(p)
+	return rec.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController.
+func (rec *recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// save caches the recorded response under a key, if it may be cached.
+func (rec *recorder) save(cache diskcache.Cache, key string, ttl time.Duration) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if !cacheableStatus(rec.status) || rec.header.Get("Set-Cookie") != "" || rec.header.Get("Vary") != "" {
+		return
+	}
+	directives := cacheControl(rec.header)
+	_, noStore := directives["no-store"]
+	_, private := directives["private"]
+	if noStore || private {
+		return
+	}
+	if ttl <= 0 {
+		ttl = freshness(rec.header, time.Now())
+	}
+	if ttl <= 0 {
+		return
+	}
+	header := rec.header.Clone()
+	header.Del(CacheHeader)
+	resp := &http.Response{
+		StatusCode:    rec.status,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+		ContentLength: int64(rec.body.Len()),
+	}
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return
+	}
+	_ = cache.Set(key, dump, ttl)
+}
@@ -0,0 +1,112 @@
+package diskcachehttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcachehttp"
+)
+
+func TestMiddleware(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	calls := 0
+	handler := diskcachehttp.Middleware(cache, nil, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+		case "/vary":
+			w.Header().Set("Vary", "Accept-Language")
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "call %d", calls)
+	}))
+
+	serve := func(method string, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	t.Run("TestHit", func(t *testing.T) {
+		first := serve(http.MethodGet, "/page")
+		if first.Header().Get("X-Cache") != "MISS" || first.Body.String() != "call 1" {
+			t.Fatalf("Expected a miss, got %s %q", first.Header().Get("X-Cache"), first.Body)
+		}
+		second := serve(http.MethodGet, "/page")
+		if second.Header().Get("X-Cache") != "HIT" || second.Body.String() != "call 1" {
+			t.Fatalf("Expected a hit, got %s %q", second.Header().Get("X-Cache"), second.Body)
+		}
+		if second.Header().Get("Content-Type") != "text/plain" || second.Code != http.StatusOK {
+			t.Fatalf("Expected the cached status and headers, got %d %v", second.Code, second.Header())
+		}
+	})
+
+	t.Run("TestStatus", func(t *testing.T) {
+		serve(http.MethodGet, "/missing")
+		got := serve(http.MethodGet, "/missing")
+		if got.Code != http.StatusNotFound || got.Header().Get("X-Cache") != "HIT" {
+			t.Fatalf("Expected a cached 404, got %d %s", got.Code, got.Header().Get("X-Cache"))
+		}
+		serve(http.MethodGet, "/error")
+		got = serve(http.MethodGet, "/error")
+		if got.Header().Get("X-Cache") != "MISS" {
+			t.Fatalf("Expected errors not to be cached")
+		}
+	})
+
+	t.Run("TestUncached", func(t *testing.T) {
+		for _, req := range [][2]string{{http.MethodGet, "/private"}, {http.MethodGet, "/vary"}, {http.MethodPost, "/page"}} {
+			before := calls
+			serve(req[0], req[1])
+			serve(req[0], req[1])
+			if calls != before+2 {
+				t.Fatalf("Expected %s %s not to be cached", req[0], req[1])
+			}
+		}
+	})
+
+	t.Run("TestCredentials", func(t *testing.T) {
+		serve(http.MethodGet, "/shared")
+		for _, header := range []string{"Cookie", "Authorization"} {
+			before := calls
+			for range 2 {
+				req := httptest.NewRequest(http.MethodGet, "/shared", nil)
+				req.Header.Set(header, "secret")
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+				if w.Header().Get("X-Cache") == "HIT" {
+					t.Fatalf("Expected a request with %s not to be served from the cache", header)
+				}
+			}
+			if calls != before+2 {
+				t.Fatalf("Expected requests with %s not to be cached", header)
+			}
+		}
+	})
+
+	t.Run("TestKeyFunc", func(t *testing.T) {
+		byPath := diskcachehttp.Middleware(cache, func(r *http.Request) string {
+			return "path:" + r.URL.Path
+		}, time.Minute)(handler)
+		before := calls
+		for _, target := range []string{"/keyed?a=1", "/keyed?a=2"} {
+			byPath.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+		}
+		if calls != before+1 || !cache.Has("path:/keyed") {
+			t.Fatalf("Expected the response to be cached by path")
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package diskcachehttp
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// CacheHeader is the header set on responses served by Transport
+// and Middleware, to HIT if the response came from the cache
+// and MISS otherwise.
+const CacheHeader = "X-Cache"
+
+// Transport is an http.RoundTripper that caches the responses
+// to GET requests in a disk cache, keyed by URL.
+// The status, headers, and body of a response are cached.
+// Responses with a Vary header aren't cached, since the cache can't
+// tell their variants apart, and neither are requests with a Range header.
+// Requests with an Authorization header bypass the cache,
+// but their responses are cached for other requests
+// if their Cache-Control header marks them public.
+type Transport struct {
+	// Transport makes the requests whose responses aren't cached.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	cache diskcache.Cache
+	ttl   time.Duration
+}
+
+// NewTransport returns a Transport that caches responses in a disk cache.
+// If ttl is positive, responses are cached for ttl, whatever their
+// freshness headers. Otherwise, responses are cached for the lifetime
+// given by their Cache-Control max-age or Expires header,
+// and responses with neither aren't cached.
+// Responses with Cache-Control no-store or a Vary header are never cached.
+func NewTransport(cache diskcache.Cache, ttl time.Duration) *Transport {
+	return &Transport{cache: cache, ttl: ttl}
+}
+
+// RoundTrip returns the cached response to a request, if any,
+// or else makes the request and caches the response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.transport().RoundTrip(req)
+	}
+	key := req.URL.String()
+	authorized := req.Header.Get("Authorization") != ""
+	if dump, err := t.cache.Get(key); err == nil && !authorized {
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(dump)), req)
+		if err == nil {
+			resp.Header.Set(CacheHeader, "HIT")
+			return resp, nil
+		}
+	}
+	resp, err := t.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	directives := cacheControl(resp.Header)
+	ttl := t.ttl
+	if ttl <= 0 {
+		ttl = freshness(resp.Header, time.Now())
+	}
+	_, noStore := directives["no-store"]
+	_, public := directives["public"]
+	vary := resp.Header.Get("Vary") != ""
+	if ttl <= 0 || noStore || vary || (authorized && !public) || !cacheableStatus(resp.StatusCode) {
+		resp.Header.Set(CacheHeader, "MISS")
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	dump, err := httputil.DumpResponse(resp, true)
+	if err == nil {
+		// A response that can't be cached is still returned.
+		_ = t.cache.Set(key, dump, ttl)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.Header.Set(CacheHeader, "MISS")
+	return resp, nil
+}
+
+// transport returns the RoundTripper that makes uncached requests.
+func (t *Transport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+	return http.DefaultTransport
+}
+
+// cacheableStatus returns true if responses with a status code
+// may be cached without explicit freshness, following RFC 9110.
+func cacheableStatus(code int) bool {
+	switch code {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusNotFound,
+		http.StatusMethodNotAllowed, http.StatusGone, http.StatusRequestURITooLong,
+		http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// freshness returns how long a response stays fresh from its
+// Cache-Control, Expires, Date, and Age headers, or zero if it
+// shouldn't be cached.
+func freshness(header http.Header, now time.Time) time.Duration {
+	var age time.Duration
+	if seconds, err := strconv.Atoi(header.Get("Age")); err == nil {
+		age = time.Duration(seconds) * time.Second
+	}
+	directives := cacheControl(header)
+	_, noStore := directives["no-store"]
+	_, noCache := directives["no-cache"]
+	if noStore || noCache {
+		return 0
+	}
+	if value, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(seconds)*time.Second - age
+	}
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		now = date
+	}
+	return expires.Sub(now)
+}
+
+// cacheControl returns the directives of a Cache-Control header,
+// mapping their lowercase names to their unquoted values, if any.
+func cacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		name = strings.ToLower(name)
+		if _, ok := directives[name]; name != "" && !ok {
+			directives[name] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
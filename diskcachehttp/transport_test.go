@@ -0,0 +1,152 @@
+package diskcachehttp_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcachehttp"
+)
+
+func TestTransport(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		switch r.URL.Path {
+		case "/max-age":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Encoding")
+		case "/public":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/error":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Header().Set("X-Request", fmt.Sprint(n))
+		fmt.Fprintf(w, "response %d", n)
+	}))
+	defer server.Close()
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	get := func(t *testing.T, client *http.Client, url string) (string, *http.Response) {
+		t.Helper()
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("Error getting %s: %v", url, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Error reading body: %v", err)
+		}
+		return string(body), resp
+	}
+
+	t.Run("TestHeaderTTL", func(t *testing.T) {
+		client := &http.Client{Transport: diskcachehttp.NewTransport(cache, 0)}
+		first, resp := get(t, client, server.URL+"/max-age")
+		if resp.Header.Get("X-Cache") != "MISS" {
+			t.Fatalf("Expected a miss, got %q", resp.Header.Get("X-Cache"))
+		}
+		second, resp := get(t, client, server.URL+"/max-age")
+		if second != first || resp.Header.Get("X-Cache") != "HIT" {
+			t.Fatalf("Expected the cached response %q, got %q (%s)", first, second, resp.Header.Get("X-Cache"))
+		}
+		if resp.Header.Get("X-Request") != "1" || resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected the cached status and headers, got %d %v", resp.StatusCode, resp.Header)
+		}
+		ttl := time.Until(cache.Expiry(server.URL + "/max-age"))
+		if ttl <= 59*time.Second || ttl > time.Minute {
+			t.Fatalf("Expected the max-age TTL, got %v", ttl)
+		}
+	})
+
+	t.Run("TestUncacheable", func(t *testing.T) {
+		client := &http.Client{Transport: diskcachehttp.NewTransport(cache, 0)}
+		for _, p := range []string{"/no-store", "/vary", "/none", "/error"} {
+			first, _ := get(t, client, server.URL+p)
+			second, _ := get(t, client, server.URL+p)
+			if first == second {
+				t.Fatalf("Expected %s not to be cached, got %q twice", p, first)
+			}
+		}
+	})
+
+	t.Run("TestFixedTTL", func(t *testing.T) {
+		client := &http.Client{Transport: diskcachehttp.NewTransport(cache, time.Hour)}
+		first, _ := get(t, client, server.URL+"/none")
+		second, _ := get(t, client, server.URL+"/none")
+		if first != second {
+			t.Fatalf("Expected the fixed TTL to override the headers, got %q and %q", first, second)
+		}
+		for _, p := range []string{"/no-store", "/vary"} {
+			first, _ := get(t, client, server.URL+p)
+			second, _ := get(t, client, server.URL+p)
+			if first == second {
+				t.Fatalf("Expected %s not to be cached, got %q twice", p, first)
+			}
+		}
+	})
+
+	t.Run("TestAuthorization", func(t *testing.T) {
+		client := &http.Client{Transport: diskcachehttp.NewTransport(cache, time.Hour)}
+		getAuthorized := func(url string) string {
+			t.Helper()
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			if err != nil {
+				t.Fatalf("Error creating request: %v", err)
+			}
+			req.Header.Set("Authorization", "Bearer secret")
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Error getting %s: %v", url, err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Error reading body: %v", err)
+			}
+			return string(body)
+		}
+		private := getAuthorized(server.URL + "/private")
+		if cache.Has(server.URL + "/private") {
+			t.Fatalf("Expected the authorized response not to be cached")
+		}
+		cached, _ := get(t, client, server.URL+"/private")
+		if getAuthorized(server.URL+"/private") == cached || cached == private {
+			t.Fatalf("Expected authorized requests to bypass the cache")
+		}
+		public := getAuthorized(server.URL + "/public")
+		if got, _ := get(t, client, server.URL+"/public"); got != public {
+			t.Fatalf("Expected the public response %q to be cached, got %q", public, got)
+		}
+	})
+
+	t.Run("TestPost", func(t *testing.T) {
+		client := &http.Client{Transport: diskcachehttp.NewTransport(cache, time.Hour)}
+		before := requests.Load()
+		for range 2 {
+			resp, err := client.Post(server.URL+"/post", "text/plain", nil)
+			if err != nil {
+				t.Fatalf("Error posting: %v", err)
+			}
+			resp.Body.Close()
+		}
+		if requests.Load() != before+2 {
+			t.Fatalf("Expected POST requests not to be cached")
+		}
+	})
+}
@@ -0,0 +1,43 @@
+// Package diskcachemetrics exposes disk cache statistics as expvar variables,
+// which are served as JSON at /debug/vars by the expvar package's handler.
+package diskcachemetrics
+
+import (
+	"expvar"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// Publish publishes the statistics of a cache as an expvar variable
+// with the given name.
+// Like expvar.Publish, it panics if the name is already registered.
+func Publish(name string, cache diskcache.Cache) {
+	expvar.Publish(name, Var(cache))
+}
+
+// Var returns an expvar variable holding the statistics of a cache,
+// which are read each time the variable is read.
+// Durations are in seconds.
+// If the statistics can't be read, the variable holds the error.
+func Var(cache diskcache.Cache) expvar.Var {
+	return expvar.Func(func() any {
+		s, err := cache.Stats()
+		if err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+		return map[string]any{
+			"hits":                   s.Hits,
+			"misses":                 s.Misses,
+			"expired_hits":           s.ExpiredHits,
+			"writes":                 s.Writes,
+			"removals":               s.Removals,
+			"evictions":              s.Evictions,
+			"bytes_written":          s.BytesWritten,
+			"bytes_read":             s.BytesRead,
+			"cleans":                 s.Cleans,
+			"clean_duration_seconds": s.CleanDuration.Seconds(),
+			"entries":                s.Entries,
+			"size_bytes":             s.Size,
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package diskcachemetrics_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcachemetrics"
+)
+
+func TestPublish(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	diskcachemetrics.Publish("testcache", cache)
+	err = cache.Set("key", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	_, err = cache.Get("key")
+	if err != nil {
+		t.Fatalf("Error getting cache: %v", err)
+	}
+	err = cache.Clean()
+	if err != nil {
+		t.Fatalf("Error cleaning cache: %v", err)
+	}
+
+	v := expvar.Get("testcache")
+	if v == nil {
+		t.Fatalf("Expected testcache to be published")
+	}
+	var metrics map[string]float64
+	err = json.Unmarshal([]byte(v.String()), &metrics)
+	if err != nil {
+		t.Fatalf("Error unmarshaling metrics: %v", err)
+	}
+	for name, want := range map[string]float64{"hits": 1, "writes": 1, "entries": 1, "cleans": 1} {
+		if metrics[name] != want {
+			t.Fatalf("Expected %s to be %v, got %v", name, want, metrics[name])
+		}
+	}
+	if metrics["size_bytes"] == 0 {
+		t.Fatalf("Expected size_bytes to be non-zero")
+	}
+}
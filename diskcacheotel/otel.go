@@ -0,0 +1,46 @@
+// Package diskcacheotel instruments disk caches with OpenTelemetry tracing.
+package diskcacheotel
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/jluckyiv/diskcache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer.
+const instrumentationName = "github.com/jluckyiv/diskcache/diskcacheotel"
+
+// WithTracerProvider creates a span for each Get, Set, Remove, and Clean
+// operation of a cache, using a tracer from the provider.
+// Spans are children of the span in the cache's context,
+// so use Cache.WithContext to add them to a request's trace.
+// Spans are annotated with the SHA-256 hash of the key, rather than the key,
+// the size of the value, and whether Get found a value.
+func WithTracerProvider(tp trace.TracerProvider) diskcache.Option {
+	tracer := tp.Tracer(instrumentationName)
+	return diskcache.WithOperationHook(func(ctx context.Context, op diskcache.Operation) func(diskcache.OperationResult) {
+		var attrs []attribute.KeyValue
+		if op.Key != "" {
+			attrs = append(attrs, attribute.String("diskcache.key_hash", fmt.Sprintf("%x", sha256.Sum256([]byte(op.Key)))))
+		}
+		_, span := tracer.Start(ctx, "diskcache."+op.Name, trace.WithAttributes(attrs...))
+		return func(result diskcache.OperationResult) {
+			if op.Name == "Get" {
+				span.SetAttributes(attribute.Bool("diskcache.hit", result.Hit))
+			}
+			if result.Bytes > 0 {
+				span.SetAttributes(attribute.Int("diskcache.bytes", result.Bytes))
+			}
+			if result.Err != nil && op.Name != "Get" {
+				span.RecordError(result.Err)
+				span.SetStatus(codes.Error, result.Err.Error())
+			}
+			span.End()
+		}
+	})
+}
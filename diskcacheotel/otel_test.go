@@ -0,0 +1,66 @@
+package diskcacheotel_test
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcacheotel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcacheotel.WithTracerProvider(tp))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	cache = cache.WithContext(ctx)
+	err = cache.Set("key", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	_, err = cache.Get("key")
+	if err != nil {
+		t.Fatalf("Error getting cache: %v", err)
+	}
+	_, _ = cache.Get("missing")
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(spans))
+	}
+	for _, span := range spans[:3] {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Fatalf("Expected span %s to be a child of the request span", span.Name())
+		}
+	}
+	want := []struct {
+		name string
+		hit  bool
+	}{{"diskcache.Set", false}, {"diskcache.Get", true}, {"diskcache.Get", false}}
+	for i, w := range want {
+		span := spans[i]
+		if span.Name() != w.name {
+			t.Fatalf("Expected span %d to be %s, got %s", i, w.name, span.Name())
+		}
+		attrs := attribute.NewSet(span.Attributes()...)
+		if _, ok := attrs.Value("diskcache.key_hash"); !ok {
+			t.Fatalf("Expected span %s to have a key hash", span.Name())
+		}
+		if w.name == "diskcache.Get" {
+			hit, _ := attrs.Value("diskcache.hit")
+			if hit.AsBool() != w.hit {
+				t.Fatalf("Expected span %d hit to be %v", i, w.hit)
+			}
+		}
+	}
+}
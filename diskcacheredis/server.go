@@ -0,0 +1,478 @@
+// Package diskcacheredis serves a disk cache over a subset of the Redis
+// protocol (RESP), so Redis clients and tools such as redis-cli
+// can read and write a cache directory.
+//
+// The server supports these commands:
+//
+//	GET key
+//	SET key value [EX seconds | PX milliseconds] [NX | XX]
+//	DEL key [key ...]
+//	EXPIRE key seconds
+//	TTL key
+//	PTTL key
+//	KEYS pattern
+//	SCAN cursor [MATCH pattern] [COUNT count]
+//	EXISTS key [key ...]
+//	PING [message]
+//	QUIT
+//
+// SET without EX or PX saves the entry for the default duration
+// of the cache, which is NoExpiry unless set with WithDefaultTTL.
+// Expired entries are treated as missing, as in Redis.
+// KEYS and SCAN patterns are globs, as in Cache.Match.
+// The SCAN cursor is an offset into the matching keys sorted in order,
+// so keys removed during a scan may cause others to be skipped.
+package diskcacheredis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// ErrServerClosed is returned by Serve and ListenAndServe
+// after Close is called.
+var ErrServerClosed = errors.New("diskcacheredis: server closed")
+
+// Server serves a cache over the Redis protocol.
+type Server struct {
+	cache diskcache.Cache
+
+	mu sync.Mutex
+	// closers are the listeners and connections to close on Close.
+	closers map[io.Closer]struct{}
+	closed  bool
+}
+
+// NewServer returns a server for a cache.
+func NewServer(cache diskcache.Cache) *Server {
+	return &Server{cache: cache, closers: make(map[io.Closer]struct{})}
+}
+
+// ListenAndServe listens on a TCP address and serves connections on it.
+// It returns ErrServerClosed after Close is called.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on a listener and serves each of them
+// in a new goroutine, until the listener fails or Close is called.
+// It closes the listener when it returns.
+// It returns ErrServerClosed after Close is called.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.track(l) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer s.untrack(l)
+	defer l.Close()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return ErrServerClosed
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close closes the listeners and connections of the server.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	var errs error
+	for c := range s.closers {
+		errs = errors.Join(errs, c.Close())
+	}
+	return errs
+}
+
+// track adds a listener or connection to close on Close,
+// unless the server is already closed.
+func (s *Server) track(c io.Closer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.closers[c] = struct{}{}
+	return true
+}
+
+// untrack removes a listener or connection to close on Close.
+func (s *Server) untrack(c io.Closer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.closers, c)
+}
+
+// isClosed returns true if Close was called.
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// serveConn reads commands from a connection and writes their replies,
+// until the client quits or the connection fails.
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.untrack(conn)
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			var perr protocolError
+			if errors.As(err, &perr) {
+				writeError(w, "ERR Protocol error: "+string(perr))
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		quit := s.exec(w, args)
+		// Flush once the client has no more pipelined commands.
+		if r.Buffered() == 0 || quit {
+			if w.Flush() != nil || quit {
+				return
+			}
+		}
+	}
+}
+
+// exec runs a command and writes its reply.
+// It returns true if the client quit.
+func (s *Server) exec(w *bufio.Writer, args []string) bool {
+	name := strings.ToUpper(args[0])
+	args = args[1:]
+	arity, ok := arities[name]
+	if !ok {
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", strings.ToLower(name)))
+		return false
+	}
+	if len(args) < arity.min || (arity.max >= 0 && len(args) > arity.max) {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+		return false
+	}
+	switch name {
+	case "PING":
+		if len(args) == 1 {
+			writeBulk(w, []byte(args[0]))
+		} else {
+			writeSimple(w, "PONG")
+		}
+	case "QUIT":
+		writeSimple(w, "OK")
+		return true
+	case "COMMAND":
+		// redis-cli asks for the command documentation on startup.
+		writeArray(w, 0)
+	case "GET":
+		s.get(w, args[0])
+	case "SET":
+		s.set(w, args)
+	case "DEL":
+		s.del(w, args)
+	case "EXISTS":
+		s.exists(w, args)
+	case "EXPIRE":
+		s.expire(w, args[0], args[1])
+	case "TTL":
+		s.ttl(w, args[0], time.Second)
+	case "PTTL":
+		s.ttl(w, args[0], time.Millisecond)
+	case "KEYS":
+		s.keys(w, args[0])
+	case "SCAN":
+		s.scan(w, args)
+	}
+	return false
+}
+
+// arity is the minimum and maximum number of arguments of a command,
+// not counting its name.
+// A maximum of -1 means no maximum.
+type arity struct {
+	min, max int
+}
+
+// arities is the arity of each supported command.
+var arities = map[string]arity{
+	"PING":    {0, 1},
+	"QUIT":    {0, -1},
+	"COMMAND": {0, -1},
+	"GET":     {1, 1},
+	"SET":     {2, -1},
+	"DEL":     {1, -1},
+	"EXISTS":  {1, -1},
+	"EXPIRE":  {2, 2},
+	"TTL":     {1, 1},
+	"PTTL":    {1, 1},
+	"KEYS":    {1, 1},
+	"SCAN":    {1, -1},
+}
+
+// get writes the value of an entry, or null if it doesn't exist.
+func (s *Server) get(w *bufio.Writer, key string) {
+	value, err := s.cache.Get(key)
+	if isMissing(err) {
+		writeNull(w)
+		return
+	}
+	if err != nil {
+		s.writeCacheError(w, err)
+		return
+	}
+	writeBulk(w, value)
+}
+
+// set saves an entry and writes OK, or null if NX or XX prevented it.
+func (s *Server) set(w *bufio.Writer, args []string) {
+	key, value := args[0], []byte(args[1])
+	duration := s.cache.DefaultTTL()
+	var nx, xx, hasTTL bool
+	for i := 2; i < len(args); i++ {
+		switch opt := strings.ToUpper(args[i]); opt {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if hasTTL || i+1 == len(args) {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			i++
+			n, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Second
+			if opt == "PX" {
+				unit = time.Millisecond
+			}
+			duration = time.Duration(n) * unit
+			hasTTL = true
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	if nx && xx {
+		writeError(w, "ERR syntax error")
+		return
+	}
+	var err error
+	switch {
+	case nx:
+		// A nil expected value matches an entry that doesn't exist.
+		err = s.cache.CAS(key, nil, value, duration)
+	case xx:
+		var current []byte
+		current, err = s.cache.Get(key)
+		if err == nil {
+			err = s.cache.CAS(key, current, value, duration)
+		}
+	default:
+		err = s.cache.Set(key, value, duration)
+	}
+	if errors.Is(err, diskcache.ErrCASMismatch) || isMissing(err) {
+		writeNull(w)
+		return
+	}
+	if err != nil {
+		s.writeCacheError(w, err)
+		return
+	}
+	writeSimple(w, "OK")
+}
+
+// del removes entries and writes how many of them existed.
+func (s *Server) del(w *bufio.Writer, keys []string) {
+	removed := 0
+	for _, key := range keys {
+		fresh := s.fresh(key)
+		err := s.cache.Remove(key)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			s.writeCacheError(w, err)
+			return
+		}
+		if fresh {
+			removed++
+		}
+	}
+	writeInt(w, int64(removed))
+}
+
+// exists writes how many of the keys have unexpired entries.
+func (s *Server) exists(w *bufio.Writer, keys []string) {
+	n := 0
+	for _, key := range keys {
+		if s.fresh(key) {
+			n++
+		}
+	}
+	writeInt(w, int64(n))
+}
+
+// fresh returns true if a key has an unexpired entry.
+func (s *Server) fresh(key string) bool {
+	return s.cache.Has(key) && !s.cache.IsExpired(key)
+}
+
+// expire sets the lifetime of an entry and writes 1,
+// or 0 if it doesn't exist.
+// A lifetime that isn't positive removes the entry, as in Redis.
+func (s *Server) expire(w *bufio.Writer, key string, arg string) {
+	seconds, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		writeError(w, "ERR value is not an integer or out of range")
+		return
+	}
+	if seconds <= 0 {
+		if !s.fresh(key) {
+			writeInt(w, 0)
+			return
+		}
+		err = s.cache.Remove(key)
+	} else {
+		err = s.cache.Expire(key, time.Duration(seconds)*time.Second)
+	}
+	if isMissing(err) {
+		writeInt(w, 0)
+		return
+	}
+	if err != nil {
+		s.writeCacheError(w, err)
+		return
+	}
+	writeInt(w, 1)
+}
+
+// ttl writes the remaining lifetime of an entry in a unit,
+// -1 if it never expires, or -2 if it doesn't exist.
+func (s *Server) ttl(w *bufio.Writer, key string, unit time.Duration) {
+	_, ttl, err := s.cache.GetWithTTL(key)
+	if isMissing(err) {
+		writeInt(w, -2)
+		return
+	}
+	if err != nil {
+		s.writeCacheError(w, err)
+		return
+	}
+	if ttl == diskcache.NoExpiry {
+		writeInt(w, -1)
+		return
+	}
+	writeInt(w, int64((ttl+unit/2)/unit))
+}
+
+// keys writes the keys of the unexpired entries that match a pattern.
+func (s *Server) keys(w *bufio.Writer, pattern string) {
+	keys, err := s.matchingKeys(pattern)
+	if err != nil {
+		s.writeCacheError(w, err)
+		return
+	}
+	writeArray(w, len(keys))
+	for _, key := range keys {
+		writeBulk(w, []byte(key))
+	}
+}
+
+// scan writes the next cursor and a batch of the keys of unexpired entries
+// that match a pattern.
+func (s *Server) scan(w *bufio.Writer, args []string) {
+	cursor, err := strconv.Atoi(args[0])
+	if err != nil || cursor < 0 {
+		writeError(w, "ERR invalid cursor")
+		return
+	}
+	pattern, count := "*", 10
+	for i := 1; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			writeError(w, "ERR syntax error")
+			return
+		}
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT":
+			count, err = strconv.Atoi(args[i+1])
+			if err != nil || count < 1 {
+				writeError(w, "ERR syntax error")
+				return
+			}
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	matching, err := s.matchingKeys(pattern)
+	if err != nil {
+		s.writeCacheError(w, err)
+		return
+	}
+	start := min(cursor, len(matching))
+	end := min(start+count, len(matching))
+	keys := matching[start:end]
+	next := end
+	if next == len(matching) {
+		next = 0
+	}
+	writeArray(w, 2)
+	writeBulk(w, []byte(strconv.Itoa(next)))
+	writeArray(w, len(keys))
+	for _, key := range keys {
+		writeBulk(w, []byte(key))
+	}
+}
+
+// matchingKeys returns the sorted keys of the unexpired entries
+// that match a pattern.
+func (s *Server) matchingKeys(pattern string) ([]string, error) {
+	return s.cache.MatchKeys(pattern)
+}
+
+// isMissing returns true if an error means an entry doesn't exist
+// or is expired.
+func isMissing(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, diskcache.ErrExpired)
+}
+
+// writeCacheError writes the reply for an error from the cache.
+func (s *Server) writeCacheError(w *bufio.Writer, err error) {
+	if errors.Is(err, diskcache.ErrReadOnly) {
+		writeError(w, "READONLY "+err.Error())
+		return
+	}
+	writeError(w, "ERR "+err.Error())
+}
@@ -0,0 +1,224 @@
+package diskcacheredis_test
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcacheredis"
+)
+
+// client sends commands as RESP arrays and reads the raw replies.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *client) do(args ...string) string {
+	c.t.Helper()
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := c.conn.Write([]byte(b.String()))
+	if err != nil {
+		c.t.Fatalf("Error writing command: %v", err)
+	}
+	return c.reply()
+}
+
+// reply reads a reply, joining the lines of arrays and bulk strings with spaces.
+func (c *client) reply() string {
+	c.t.Helper()
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("Error reading reply: %v", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	switch line[0] {
+	case '$':
+		if line == "$-1" {
+			return "(nil)"
+		}
+		value, err := c.r.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("Error reading reply: %v", err)
+		}
+		return strings.TrimSuffix(value, "\r\n")
+	case '*':
+		var n int
+		fmt.Sscanf(line, "*%d", &n)
+		elems := make([]string, n)
+		for i := range elems {
+			elems[i] = c.reply()
+		}
+		return "[" + strings.Join(elems, " ") + "]"
+	default:
+		return line
+	}
+}
+
+func TestServer(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %v", err)
+	}
+	server := diskcacheredis.NewServer(cache)
+	done := make(chan error)
+	go func() {
+		done <- server.Serve(l)
+	}()
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Error dialing: %v", err)
+	}
+	defer conn.Close()
+	c := &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"PING"}, "+PONG"},
+		{[]string{"GET", "missing"}, "(nil)"},
+		{[]string{"SET", "key", "value"}, "+OK"},
+		{[]string{"GET", "key"}, "value"},
+		{[]string{"TTL", "key"}, ":-1"},
+		{[]string{"EXPIRE", "key", "100"}, ":1"},
+		{[]string{"TTL", "key"}, ":100"},
+		{[]string{"EXPIRE", "missing", "100"}, ":0"},
+		{[]string{"TTL", "missing"}, ":-2"},
+		{[]string{"SET", "key", "other", "NX"}, "(nil)"},
+		{[]string{"SET", "new", "value", "XX"}, "(nil)"},
+		{[]string{"SET", "new", "value", "NX", "EX", "60"}, "+OK"},
+		{[]string{"TTL", "new"}, ":60"},
+		{[]string{"SET", "key", "line1\r\nline2", "XX"}, "+OK"},
+		{[]string{"SET", "user:1", "a"}, "+OK"},
+		{[]string{"SET", "user:2", "b", "PX", "1"}, "+OK"},
+		{[]string{"SET", "key", "value", "EX", "0"}, "-ERR invalid expire time in 'set' command"},
+		{[]string{"EXISTS", "key", "new", "missing"}, ":2"},
+		{[]string{"DEL", "new", "missing"}, ":1"},
+		{[]string{"GET"}, "-ERR wrong number of arguments for 'get' command"},
+		{[]string{"FLUSHALL"}, "-ERR unknown command 'flushall'"},
+	}
+	for _, tt := range tests {
+		got := c.do(tt.args...)
+		if got != tt.want {
+			t.Fatalf("Expected %v to reply %q, got %q", tt.args, tt.want, got)
+		}
+	}
+
+	t.Run("TestPTTL", func(t *testing.T) {
+		c.do("SET", "pttl", "value", "EX", "60")
+		got := c.do("PTTL", "pttl")
+		var ms int
+		fmt.Sscanf(got, ":%d", &ms)
+		if ms <= 59000 || ms > 60000 {
+			t.Fatalf("Expected about 60000 milliseconds, got %s", got)
+		}
+		c.do("DEL", "pttl")
+	})
+
+	t.Run("TestBinarySafe", func(t *testing.T) {
+		value, err := cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(value) != "line1\r\nline2" {
+			t.Fatalf("Expected value to round trip, got %q", value)
+		}
+	})
+
+	t.Run("TestKeys", func(t *testing.T) {
+		time.Sleep(5 * time.Millisecond)
+		got := c.do("KEYS", "*")
+		if got != "[key user:1]" {
+			t.Fatalf("Expected unexpired keys, got %s", got)
+		}
+		got = c.do("KEYS", "user:[0-9]")
+		if got != "[user:1]" {
+			t.Fatalf("Expected matching keys, got %s", got)
+		}
+	})
+
+	t.Run("TestScan", func(t *testing.T) {
+		for i := range 5 {
+			cache.Set(fmt.Sprintf("scan:%d", i), []byte("value"), time.Minute)
+		}
+		var keys []string
+		cursor := "0"
+		for {
+			got := c.do("SCAN", cursor, "MATCH", "scan:*", "COUNT", "2")
+			_, err := fmt.Sscanf(got, "[%s", &cursor)
+			if err != nil {
+				t.Fatalf("Error parsing reply %q: %v", got, err)
+			}
+			elems := strings.TrimSuffix(strings.TrimPrefix(got, "["+cursor+" ["), "]]")
+			keys = append(keys, strings.Fields(elems)...)
+			if cursor == "0" {
+				break
+			}
+		}
+		if len(keys) != 5 {
+			t.Fatalf("Expected 5 keys, got %v", keys)
+		}
+	})
+
+	t.Run("TestInline", func(t *testing.T) {
+		_, err := conn.Write([]byte("GET user:1\r\n"))
+		if err != nil {
+			t.Fatalf("Error writing command: %v", err)
+		}
+		got := c.reply()
+		if got != "a" {
+			t.Fatalf("Expected a, got %s", got)
+		}
+	})
+
+	t.Run("TestReadOnly", func(t *testing.T) {
+		readOnly, err := diskcache.New(cache.Dir(), diskcache.WithReadOnly())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Error listening: %v", err)
+		}
+		server := diskcacheredis.NewServer(readOnly)
+		go server.Serve(l)
+		defer server.Close()
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("Error dialing: %v", err)
+		}
+		defer conn.Close()
+		c := &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+		got := c.do("SET", "key", "value")
+		if !strings.HasPrefix(got, "-READONLY") {
+			t.Fatalf("Expected READONLY error, got %s", got)
+		}
+	})
+
+	t.Run("TestClose", func(t *testing.T) {
+		err := server.Close()
+		if err != nil {
+			t.Fatalf("Error closing server: %v", err)
+		}
+		err = <-done
+		if !errors.Is(err, diskcacheredis.ErrServerClosed) {
+			t.Fatalf("Expected ErrServerClosed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+// Package diskcachesql caches the results of database/sql queries
+// in a disk cache, for read-heavy applications over slow databases:
+//
+//	dq := diskcachesql.New(db, cache)
+//	result, err := dq.Query(ctx, 10*time.Minute, "SELECT id, name FROM users WHERE team = ?", team)
+//
+// Results are keyed by the query, with its whitespace outside quotes
+// normalized, and the values of its arguments, and are invalidated explicitly with Invalidate
+// or InvalidateAll, or when they expire.
+package diskcachesql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// KeyPrefix is the prefix of the cache keys of query results.
+const KeyPrefix = "sql:"
+
+func init() {
+	// Times are the only driver values gob doesn't know.
+	gob.Register(time.Time{})
+}
+
+// Queryer runs queries, like *sql.DB, *sql.Tx, and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Result is the result of a query.
+type Result struct {
+	// Columns is the names of the columns.
+	Columns []string
+	// Rows is the values of the rows, in column order.
+	// Values are the driver values of the columns,
+	// such as int64, float64, bool, []byte, string, time.Time, or nil.
+	Rows [][]any
+}
+
+// DB caches the results of the queries run by a Queryer.
+type DB struct {
+	db    Queryer
+	cache diskcache.Cache
+}
+
+// New returns a DB that runs queries with db and caches their results in cache.
+func New(db Queryer, cache diskcache.Cache) *DB {
+	return &DB{db: db, cache: cache}
+}
+
+// Query returns the cached result of a query with arguments, if any,
+// or else runs the query and caches its result for ttl.
+// Results that can't be cached, such as those with values
+// of types gob can't encode, are still returned.
+func (d *DB) Query(ctx context.Context, ttl time.Duration, query string, args ...any) (Result, error) {
+	key := Key(query, args...)
+	data, err := d.cache.Get(key)
+	if err == nil {
+		var result Result
+		if gob.NewDecoder(bytes.NewReader(data)).Decode(&result) == nil {
+			return result, nil
+		}
+	}
+	result, err := d.query(ctx, query, args)
+	if err != nil {
+		return Result{}, err
+	}
+	var buf bytes.Buffer
+	if gob.NewEncoder(&buf).Encode(result) == nil {
+		_ = d.cache.Set(key, buf.Bytes(), ttl)
+	}
+	return result, nil
+}
+
+// query runs a query and reads all its rows.
+func (d *DB) query(ctx context.Context, query string, args []any) (Result, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Columns: columns}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		err := rows.Scan(ptrs...)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, rows.Err()
+}
+
+// Invalidate removes the cached result of a query with arguments, if any.
+func (d *DB) Invalidate(query string, args ...any) error {
+	err := d.cache.Remove(Key(query, args...))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// InvalidateAll removes the cached results of all queries.
+func (d *DB) InvalidateAll() error {
+	_, err := d.cache.FlushPrefix(KeyPrefix)
+	return err
+}
+
+// Key returns the cache key of the result of a query with arguments.
+// Queries that differ only in whitespace outside quoted strings
+// and identifiers have the same key.
+// Arguments are told apart by the type and value they're sent to the
+// database as: pointers are dereferenced and driver.Valuers are
+// replaced by their values, so *p and p have the same key,
+// but 1 and int64(1) have different keys.
+func Key(query string, args ...any) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00", normalize(query))
+	for _, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			fmt.Fprintf(hash, "@%s=", named.Name)
+			arg = named.Value
+		}
+		arg = argValue(arg)
+		fmt.Fprintf(hash, "%T:%#v\x00", arg, arg)
+	}
+	return KeyPrefix + hex.EncodeToString(hash.Sum(nil))
+}
+
+// normalize replaces each run of whitespace in a query with a space
+// and trims it, leaving quoted strings and identifiers as they are.
+// A backslash inside quotes escapes the next character, so a quote
+// it escapes doesn't end the string; where backslashes don't escape,
+// more of the query is left as it is, which only misses the cache.
+func normalize(query string) string {
+	var b strings.Builder
+	var quote rune
+	escaped := false
+	space := false
+	for _, r := range strings.TrimSpace(query) {
+		switch {
+		case quote != 0:
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// argValue returns the value a query argument is sent to the database as.
+// Pointers are dereferenced, with nil pointers as nil,
+// and driver.Valuers are replaced by their values.
+func argValue(arg any) any {
+	for {
+		v := reflect.ValueOf(arg)
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+		if valuer, ok := arg.(driver.Valuer); ok {
+			value, err := valuer.Value()
+			if err != nil {
+				// The query fails with the same error.
+				return arg
+			}
+			return argValue(value)
+		}
+		if v.Kind() != reflect.Pointer {
+			return arg
+		}
+		arg = v.Elem().Interface()
+	}
+}
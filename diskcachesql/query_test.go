@@ -0,0 +1,207 @@
+package diskcachesql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+	"github.com/jluckyiv/diskcache/diskcachesql"
+)
+
+// queries counts the queries run by the fake driver.
+var queries atomic.Int64
+
+// at is the time in the rows of the fake driver.
+var at = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeDriver is a database driver whose queries return the same rows.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions aren't supported")
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec isn't supported")
+}
+
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	queries.Add(1)
+	return &fakeRows{rows: [][]driver.Value{
+		{int64(1), "one", at},
+		{int64(2), nil, at},
+	}}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "name", "at"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+func TestQuery(t *testing.T) {
+	db, err := sql.Open("fake", "")
+	if err != nil {
+		t.Fatalf("Error opening database: %v", err)
+	}
+	defer db.Close()
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	dq := diskcachesql.New(db, cache)
+	ctx := context.Background()
+	query := "SELECT id, name, at FROM users WHERE team = ?"
+
+	result, err := dq.Query(ctx, time.Minute, query, "a")
+	if err != nil {
+		t.Fatalf("Error querying: %v", err)
+	}
+
+	t.Run("TestHit", func(t *testing.T) {
+		before := queries.Load()
+		cached, err := dq.Query(ctx, time.Minute, "SELECT id, name, at\n\tFROM users  WHERE team = ?", "a")
+		if err != nil {
+			t.Fatalf("Error querying: %v", err)
+		}
+		if queries.Load() != before {
+			t.Fatalf("Expected the cached result, but the query ran")
+		}
+		if len(cached.Rows) != 2 || cached.Columns[1] != "name" {
+			t.Fatalf("Expected 2 rows, got %v", cached)
+		}
+		row := cached.Rows[0]
+		if row[0] != int64(1) || row[1] != "one" || !row[2].(time.Time).Equal(at) {
+			t.Fatalf("Expected the values to keep their types, got %#v", row)
+		}
+		if cached.Rows[1][1] != nil {
+			t.Fatalf("Expected a nil value, got %#v", cached.Rows[1][1])
+		}
+		if len(result.Rows) != len(cached.Rows) {
+			t.Fatalf("Expected the same result, got %v and %v", result, cached)
+		}
+	})
+
+	t.Run("TestArgs", func(t *testing.T) {
+		before := queries.Load()
+		_, err := dq.Query(ctx, time.Minute, query, "b")
+		if err != nil {
+			t.Fatalf("Error querying: %v", err)
+		}
+		if queries.Load() != before+1 {
+			t.Fatalf("Expected other arguments to run the query")
+		}
+	})
+
+	t.Run("TestInvalidate", func(t *testing.T) {
+		err := dq.Invalidate(query, "a")
+		if err != nil {
+			t.Fatalf("Error invalidating: %v", err)
+		}
+		if cache.Has(diskcachesql.Key(query, "a")) {
+			t.Fatalf("Expected the result to be removed")
+		}
+		if !cache.Has(diskcachesql.Key(query, "b")) {
+			t.Fatalf("Expected other results to be kept")
+		}
+		err = dq.Invalidate(query, "a")
+		if err != nil {
+			t.Fatalf("Error invalidating a missing result: %v", err)
+		}
+	})
+
+	t.Run("TestInvalidateAll", func(t *testing.T) {
+		err := cache.Set("other", []byte("value"), time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = dq.InvalidateAll()
+		if err != nil {
+			t.Fatalf("Error invalidating: %v", err)
+		}
+		if cache.Has(diskcachesql.Key(query, "b")) || !cache.Has("other") {
+			t.Fatalf("Expected only query results to be removed")
+		}
+	})
+}
+
+func TestKey(t *testing.T) {
+	id := int64(1)
+	other := int64(2)
+	var missing *int64
+	tests := map[string]struct {
+		a, b []any
+		same bool
+	}{
+		"TestWhitespace":  {[]any{"SELECT  a\n FROM t "}, []any{"SELECT a FROM t"}, true},
+		"TestString":      {[]any{"SELECT 'a  b'"}, []any{"SELECT 'a b'"}, false},
+		"TestIdentifier":  {[]any{`SELECT "a  b" FROM t`}, []any{`SELECT "a b" FROM t`}, false},
+		"TestEscape":      {[]any{`SELECT 'a\'  b'`}, []any{`SELECT 'a\' b'`}, false},
+		"TestPointer":     {[]any{"?", &id}, []any{"?", id}, true},
+		"TestPointers":    {[]any{"?", &id}, []any{"?", &other}, false},
+		"TestNilPointer":  {[]any{"?", missing}, []any{"?", nil}, true},
+		"TestValuer":      {[]any{"?", sql.NullString{String: "a", Valid: true}}, []any{"?", "a"}, true},
+		"TestValuers":     {[]any{"?", sql.NullString{String: "a", Valid: true}}, []any{"?", sql.NullString{String: "b", Valid: true}}, false},
+		"TestNullValuer":  {[]any{"?", sql.NullString{String: "a"}}, []any{"?", nil}, true},
+		"TestNamedValuer": {[]any{"?", sql.Named("id", &sql.NullInt64{Int64: 1, Valid: true})}, []any{"?", sql.Named("id", id)}, true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := diskcachesql.Key(test.a[0].(string), test.a[1:]...)
+			b := diskcachesql.Key(test.b[0].(string), test.b[1:]...)
+			if (a == b) != test.same {
+				t.Fatalf("Expected keys to be the same: %v, got %s and %s", test.same, a, b)
+			}
+		})
+	}
+}
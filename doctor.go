@@ -0,0 +1,122 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ProblemKind is a kind of problem with an entry file.
+type ProblemKind string
+
+const (
+	// ProblemUnreadable is an entry file that can't be read,
+	// such as for lack of permission.
+	ProblemUnreadable ProblemKind = "unreadable"
+	// ProblemEmpty is an entry file with no contents.
+	ProblemEmpty ProblemKind = "empty"
+	// ProblemCorrupt is an entry file that doesn't decode,
+	// or whose value doesn't match its checksum.
+	ProblemCorrupt ProblemKind = "corrupt"
+	// ProblemMisnamed is an entry file whose name isn't
+	// the filename of the key it holds, so Get never finds it.
+	ProblemMisnamed ProblemKind = "misnamed"
+)
+
+// Problem is a problem with an entry file found by Diagnose.
+type Problem struct {
+	// Filename is the name of the entry file, relative to the cache directory.
+	Filename string
+	Kind     ProblemKind
+	// Key is the key the file holds, if it could be read.
+	Key string
+	// Err is the error reading the file, if any.
+	Err error
+}
+
+// Diagnose reads every entry file and returns the problems found,
+// sorted by filename.
+// Entries are decoded with the cache's options, so a cache should be
+// diagnosed with the options it was written with, such as its
+// encryption key and filename function.
+func (c Cache) Diagnose() ([]Problem, error) {
+	filenames, err := c.readDir()
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	c.verify = true
+	var problems []Problem
+	for _, filename := range filenames {
+		contents, err := c.readEntry(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			problems = append(problems, Problem{Filename: filename, Kind: ProblemUnreadable, Err: err})
+			continue
+		}
+		if len(contents) == 0 {
+			problems = append(problems, Problem{Filename: filename, Kind: ProblemEmpty})
+			continue
+		}
+		data, err := c.readFile(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			problems = append(problems, Problem{Filename: filename, Kind: ProblemCorrupt, Err: err})
+			continue
+		}
+		if c.Filename(data.Key) != filename {
+			problems = append(problems, Problem{Filename: filename, Kind: ProblemMisnamed, Key: data.Key})
+		}
+	}
+	slices.SortFunc(problems, func(a, b Problem) int {
+		return strings.Compare(a.Filename, b.Filename)
+	})
+	return problems, nil
+}
+
+// Discard removes the entry file of a problem and its sidecar, if any.
+// Previous versions of the entry are kept, so Rollback may recover it.
+func (c Cache) Discard(p Problem) error {
+	err := c.store.RemoveEntry(p.Filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err = c.removeEntryIfExists(c.sidecar(p.Filename))
+	if err != nil {
+		return err
+	}
+	c.index.removeFilename(p.Filename)
+	return nil
+}
+
+// Quarantine moves the entry file of a problem and its sidecar, if any,
+// to a directory, so they can be inspected without being read as entries.
+// It creates the directory if it doesn't exist.
+// It returns an error if the cache isn't backed by a directory.
+func (c Cache) Quarantine(p Problem, dir string) error {
+	s, err := c.dirStore()
+	if err != nil {
+		return err
+	}
+	if s.readOnly {
+		return errReadOnly("quarantine", p.Filename)
+	}
+	err = s.fsys.MkdirAll(dir, s.dirMode)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{p.Filename, c.sidecar(p.Filename)} {
+		err := s.fsys.Rename(filepath.Join(s.dir, name), filepath.Join(dir, filepath.Base(name)))
+		if err != nil && !(name != p.Filename && errors.Is(err, fs.ErrNotExist)) {
+			return err
+		}
+	}
+	c.index.removeFilename(p.Filename)
+	return nil
+}
@@ -0,0 +1,108 @@
+package diskcache_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestDiagnose(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, key := range []string{"good", "unreadable"} {
+		err = cache.Set(key, []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	contents, err := os.ReadFile(cache.Filepath("good"))
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	files := map[string][]byte{
+		"empty":    nil,
+		"corrupt":  []byte("{not json"),
+		"misnamed": contents,
+	}
+	for key, contents := range files {
+		err = os.WriteFile(cache.Filepath(key), contents, 0644)
+		if err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+	}
+	fsys := &faultFS{fail: func(op string, name string) error {
+		if op == "open" && name == cache.Filepath("unreadable") {
+			return fs.ErrPermission
+		}
+		return nil
+	}}
+	cache, err = diskcache.New(cacheDir, diskcache.WithFS(fsys))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	problems, err := cache.Diagnose()
+	if err != nil {
+		t.Fatalf("Error diagnosing cache: %v", err)
+	}
+	kinds := make(map[string]diskcache.ProblemKind)
+	for _, p := range problems {
+		kinds[p.Filename] = p.Kind
+	}
+	want := map[string]diskcache.ProblemKind{
+		cache.Filename("empty"):      diskcache.ProblemEmpty,
+		cache.Filename("corrupt"):    diskcache.ProblemCorrupt,
+		cache.Filename("misnamed"):   diskcache.ProblemMisnamed,
+		cache.Filename("unreadable"): diskcache.ProblemUnreadable,
+	}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected %d problems, got %v", len(want), problems)
+	}
+	for filename, kind := range want {
+		if kinds[filename] != kind {
+			t.Fatalf("Expected %s to be %s, got %q", filename, kind, kinds[filename])
+		}
+	}
+
+	t.Run("TestDiscard", func(t *testing.T) {
+		err := cache.Discard(diskcache.Problem{Filename: cache.Filename("corrupt")})
+		if err != nil {
+			t.Fatalf("Error discarding entry: %v", err)
+		}
+		_, err = os.Stat(cache.Filepath("corrupt"))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected corrupt file to be removed, got %v", err)
+		}
+	})
+
+	t.Run("TestQuarantine", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "quarantine")
+		err := cache.Quarantine(diskcache.Problem{Filename: cache.Filename("misnamed")}, dir)
+		if err != nil {
+			t.Fatalf("Error quarantining entry: %v", err)
+		}
+		_, err = os.Stat(filepath.Join(dir, cache.Filename("misnamed")))
+		if err != nil {
+			t.Fatalf("Error finding quarantined file: %v", err)
+		}
+		problems, err := cache.Diagnose()
+		if err != nil {
+			t.Fatalf("Error diagnosing cache: %v", err)
+		}
+		if len(problems) != 2 {
+			t.Fatalf("Expected 2 problems left, got %v", problems)
+		}
+		if !cache.Has("good") {
+			t.Fatalf("Expected good entry to be kept")
+		}
+	})
+}
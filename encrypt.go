@@ -0,0 +1,113 @@
+package diskcache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithEncryption encrypts entry keys and values at rest with AES-GCM.
+// The encryption key must be 16, 24, or 32 bytes long
+// to select AES-128, AES-192, or AES-256.
+// Each entry stores its own random nonce.
+// Expiry and creation times aren't encrypted, but they're authenticated
+// with the ciphertext, so an entry whose times were changed on disk
+// fails to decrypt. Access times are neither.
+func WithEncryption(key []byte) Option {
+	return func(c *Cache) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			c.err = fmt.Errorf("error creating cipher: %w", err)
+			return
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			c.err = fmt.Errorf("error creating cipher: %w", err)
+			return
+		}
+		c.aead = aead
+	}
+}
+
+// sealed is the plaintext of an encrypted record.
+type sealed struct {
+	Key   string
+	Value []byte
+	Meta  map[string]string `json:",omitempty"`
+}
+
+// sealedHeader is the additional data authenticated with the ciphertext
+// of a record: the fields of its header that aren't sealed,
+// except the access time, which reads update, and the schema version,
+// which upgrading changes.
+type sealedHeader struct {
+	Expiry      time.Time
+	CreatedAt   time.Time
+	Compression Compression
+	Raw         bool
+	Checksum    string
+}
+
+// additionalData returns the additional data of a record.
+func additionalData(rec record) ([]byte, error) {
+	return json.Marshal(sealedHeader{
+		Expiry:      rec.Expiry,
+		CreatedAt:   rec.CreatedAt,
+		Compression: rec.Compression,
+		Raw:         rec.Raw,
+		Checksum:    rec.Checksum,
+	})
+}
+
+// encrypt moves the key, value, and metadata of a record into its sealed ciphertext.
+func (c Cache) encrypt(rec record) (record, error) {
+	plaintext, err := json.Marshal(sealed{Key: rec.Key, Value: rec.Value, Meta: rec.Meta})
+	if err != nil {
+		return record{}, err
+	}
+	ad, err := additionalData(rec)
+	if err != nil {
+		return record{}, err
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return record{}, err
+	}
+	rec.Nonce = nonce
+	rec.Sealed = c.aead.Seal(nil, nonce, plaintext, ad)
+	rec.Key = ""
+	rec.Value = nil
+	rec.Meta = nil
+	return rec, nil
+}
+
+// decrypt restores the key, value, and metadata of a record from its sealed ciphertext.
+func (c Cache) decrypt(rec record) (record, error) {
+	if c.aead == nil {
+		return record{}, errors.New("entry is encrypted")
+	}
+	ad, err := additionalData(rec)
+	if err != nil {
+		return record{}, err
+	}
+	plaintext, err := c.aead.Open(nil, rec.Nonce, rec.Sealed, ad)
+	if err != nil {
+		return record{}, err
+	}
+	var s sealed
+	err = json.Unmarshal(plaintext, &s)
+	if err != nil {
+		return record{}, err
+	}
+	rec.Key = s.Key
+	rec.Value = s.Value
+	rec.Meta = s.Meta
+	rec.Nonce = nil
+	rec.Sealed = nil
+	return rec, nil
+}
@@ -0,0 +1,162 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestEncryption(t *testing.T) {
+	secret := bytes.Repeat([]byte("k"), 32)
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithEncryption(secret), diskcache.WithCompression(diskcache.Gzip))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "oauth:token"
+	value := []byte("supersecretvalue")
+	err = cache.SetWithMeta(key, value, map[string]string{"source": "https://example.com"}, 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestRoundTrip", func(t *testing.T) {
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected cache value to be %s, got %s", value, got)
+		}
+		data, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(data) != 1 || data[0].Key != key {
+			t.Fatalf("Expected list to contain %s", key)
+		}
+	})
+
+	t.Run("TestCiphertext", func(t *testing.T) {
+		contents, err := os.ReadFile(cache.Filepath(key))
+		if err != nil {
+			t.Fatalf("Error reading file: %v", err)
+		}
+		if bytes.Contains(contents, []byte(key)) {
+			t.Fatalf("Expected file to not contain the plaintext key")
+		}
+		if bytes.Contains(contents, value) {
+			t.Fatalf("Expected file to not contain the plaintext value")
+		}
+		if bytes.Contains(contents, []byte("example.com")) {
+			t.Fatalf("Expected file to not contain the plaintext metadata")
+		}
+	})
+
+	t.Run("TestWrongKey", func(t *testing.T) {
+		other, err := diskcache.New(cacheDir, diskcache.WithEncryption(bytes.Repeat([]byte("x"), 32)))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		_, err = other.Get(key)
+		if err == nil {
+			t.Fatalf("Expected error decrypting with the wrong key")
+		}
+		plain, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		_, err = plain.Get(key)
+		if err == nil {
+			t.Fatalf("Expected error reading encrypted entry without a key")
+		}
+	})
+
+	t.Run("TestTamperedHeader", func(t *testing.T) {
+		err := cache.Set("tampered", value, 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		contents, err := os.ReadFile(cache.Filepath("tampered"))
+		if err != nil {
+			t.Fatalf("Error reading file: %v", err)
+		}
+		var fields map[string]any
+		err = json.Unmarshal(contents, &fields)
+		if err != nil {
+			t.Fatalf("Error unmarshaling file: %v", err)
+		}
+		fields["Expiry"] = time.Now().Add(24 * time.Hour)
+		contents, err = json.Marshal(fields)
+		if err != nil {
+			t.Fatalf("Error marshaling file: %v", err)
+		}
+		err = os.WriteFile(cache.Filepath("tampered"), contents, 0644)
+		if err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+		_, err = cache.Get("tampered")
+		if err == nil {
+			t.Fatalf("Expected error decrypting an entry with a changed expiry")
+		}
+	})
+
+	t.Run("TestExpire", func(t *testing.T) {
+		err := cache.Expire(key, 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error updating expiry: %v", err)
+		}
+		got, ttl, err := cache.GetWithTTL(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) || ttl <= 59*time.Minute {
+			t.Fatalf("Expected %s for about 1h, got %s for %v", value, got, ttl)
+		}
+	})
+
+	t.Run("TestUnauthenticatedHeader", func(t *testing.T) {
+		// Entries sealed without their headers as additional data
+		// don't decrypt.
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			t.Fatalf("Error creating cipher: %v", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			t.Fatalf("Error creating cipher: %v", err)
+		}
+		nonce := make([]byte, aead.NonceSize())
+		contents, err := json.Marshal(map[string]any{
+			"Expiry":  time.Now().Add(1 * time.Minute),
+			"Nonce":   nonce,
+			"Sealed":  aead.Seal(nil, nonce, []byte(`{"Key":"legacy","Value":"dmFsdWU="}`), nil),
+			"Version": 1,
+		})
+		if err != nil {
+			t.Fatalf("Error marshaling file: %v", err)
+		}
+		err = os.WriteFile(cache.Filepath("legacy"), contents, 0644)
+		if err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+		_, err = cache.Get("legacy")
+		if err == nil {
+			t.Fatalf("Expected error decrypting an unauthenticated header")
+		}
+	})
+
+	t.Run("TestInvalidKey", func(t *testing.T) {
+		_, err := diskcache.New(cacheDir, diskcache.WithEncryption([]byte("short")))
+		if err == nil {
+			t.Fatalf("Expected error for invalid encryption key, but got nil")
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// WithMaxEntries caps the number of entries in the cache.
+// When Set exceeds the cap, the soonest-to-expire entries are evicted,
+// or the entries in the order set by WithEvictionOrder.
+// A cap of zero or less means no cap.
+// Each Set lists the cache directory to count the entries,
+// and once the cap is exceeded, reads the headers of all the entry
+// files to pick the entries to evict, without decoding their values.
+// With WithIndex, the entries are counted and picked in memory instead.
+func WithMaxEntries(n int) Option {
+	return func(c *Cache) {
+		c.maxEntries = n
+	}
+}
+
+// evict removes entries in eviction order until the cache holds
+// at most maxEntries entries.
+// It never evicts the given key, which is the key that was just set.
+func (c Cache) evict(keep string) error {
+	if c.maxEntries <= 0 {
+		return nil
+	}
+	if c.index == nil {
+		filenames, err := c.readDir()
+		if err != nil {
+			return fmt.Errorf("error reading directory: %w", err)
+		}
+		if len(filenames) <= c.maxEntries {
+			return nil
+		}
+	}
+	list, err := c.metaMatching(func(string) bool { return true })
+	if err != nil {
+		return err
+	}
+	excess := len(list) - c.maxEntries
+	if excess <= 0 {
+		return nil
+	}
+	if c.evictOrder == RemoveLeastRecentlyUsed {
+		SortByLastUsed(list)
+	} else {
+		SortByExpiry(list)
+	}
+	var errs error
+	for _, data := range list {
+		if excess == 0 {
+			break
+		}
+		if data.Key == keep {
+			continue
+		}
+		c.log(slog.LevelDebug, "evicting entry", "key", data.Key, "expiry", data.Expiry, "order", c.evictOrder, "max_entries", c.maxEntries)
+		err := c.Remove(data.Key)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		c.stats.evict()
+		excess--
+	}
+	return errs
+}
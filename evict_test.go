@@ -0,0 +1,117 @@
+package diskcache_test
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestMaxEntries(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []diskcache.Option
+	}{
+		{"TestUnindexed", []diskcache.Option{diskcache.WithMaxEntries(2)}},
+		{"TestIndexed", []diskcache.Option{diskcache.WithMaxEntries(2), diskcache.WithIndex()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, tc.options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+
+			testData := []struct {
+				key    string
+				expiry time.Duration
+			}{
+				{"key1", 3 * time.Minute},
+				{"key2", 1 * time.Minute},
+				{"key3", 2 * time.Minute},
+			}
+			for _, td := range testData {
+				err := cache.Set(td.key, []byte("value"), td.expiry)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+
+			data, err := cache.List()
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			if len(data) != 2 {
+				t.Fatalf("Expected 2 keys, got %d", len(data))
+			}
+			if cache.Has("key2") {
+				t.Fatalf("Expected soonest-to-expire key2 to be evicted")
+			}
+
+			// Updating an existing key doesn't evict.
+			err = cache.Set("key3", []byte("value"), 1*time.Second)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			if !cache.Has("key1") || !cache.Has("key3") {
+				t.Fatalf("Expected key1 and key3 to exist")
+			}
+
+			// The key being set is never evicted.
+			err = cache.Set("key4", []byte("value"), -1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			if !cache.Has("key4") {
+				t.Fatalf("Expected key4 to exist")
+			}
+			if cache.Has("key3") {
+				t.Fatalf("Expected key3 to be evicted")
+			}
+		})
+	}
+}
+
+func TestMaxEntriesHeaders(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithMaxEntries(2), diskcache.WithCompression(diskcache.Gzip))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.Set("corrupt", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	// Corrupt the value, so only decoding it fails.
+	filename := cache.Filepath("corrupt")
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	var rec map[string]any
+	err = json.Unmarshal(contents, &rec)
+	if err != nil {
+		t.Fatalf("Error unmarshaling file: %v", err)
+	}
+	rec["Value"] = []byte("not gzip")
+	contents, err = json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Error marshaling file: %v", err)
+	}
+	err = os.WriteFile(filename, contents, 0644)
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	for _, key := range []string{"key1", "key2"} {
+		err := cache.Set(key, []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	if cache.Has("corrupt") {
+		t.Fatalf("Expected soonest-to-expire corrupt entry to be evicted")
+	}
+}
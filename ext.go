@@ -0,0 +1,20 @@
+package diskcache
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithExtension sets the extension of entry files, such as ".cache".
+// The default is ".json".
+// The extension must start with a dot, must not contain path separators,
+// and must differ from the extensions of sidecar and temporary files.
+func WithExtension(ext string) Option {
+	return func(c *Cache) {
+		if len(ext) < 2 || ext[0] != '.' || strings.ContainsAny(ext[1:], `./\`) || ext == rawExt || ext == ".tmp" {
+			c.err = fmt.Errorf("invalid extension: %q", ext)
+			return
+		}
+		c.ext = ext
+	}
+}
@@ -0,0 +1,52 @@
+package diskcache_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestExtension(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithExtension(".cache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "testkey"
+	want := fmt.Sprintf("%x.cache", sha256.Sum256([]byte(key)))
+	if got := cache.Filename(key); got != want {
+		t.Fatalf("Want filename to be %s, got %s", want, got)
+	}
+	err = cache.Set(key, []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	// Entries with other extensions are ignored.
+	plain, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = plain.Set("other", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	data, err := cache.List()
+	if err != nil {
+		t.Fatalf("Error listing cache: %v", err)
+	}
+	if len(data) != 1 || data[0].Key != key {
+		t.Fatalf("Expected list to contain only %s, got %v", key, data)
+	}
+
+	for _, ext := range []string{"", "cache", ".", ".a/b", ".bin", ".tmp", ".tar.gz"} {
+		_, err := diskcache.New(cacheDir, diskcache.WithExtension(ext))
+		if err == nil {
+			t.Errorf("Expected error for extension %q, but got nil", ext)
+		}
+	}
+}
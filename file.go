@@ -0,0 +1,193 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// FS is the file system the cache uses for its directory.
+// Its methods behave like the functions of the same names in package os,
+// except that WriteFile writes the contents of a reader.
+// The default FS uses package os.
+// Watch always uses the operating system's file system.
+type FS interface {
+	// Open opens a file for reading.
+	Open(name string) (fs.File, error)
+	// WriteFile writes the contents of r to a file, creating or truncating it,
+	// and returns the number of bytes written.
+	WriteFile(name string, r io.Reader, perm fs.FileMode) (int64, error)
+	// ReadDir reads a directory and returns its entries sorted by filename.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// Remove removes a file or an empty directory.
+	Remove(name string) error
+	// RemoveAll removes a path and any children it contains.
+	RemoveAll(path string) error
+	// Rename renames a file, replacing the destination if it exists.
+	Rename(oldpath string, newpath string) error
+	// MkdirAll creates a directory and any necessary parents.
+	MkdirAll(path string, perm fs.FileMode) error
+}
+
+// SyncFS is an FS that can flush files to stable storage, for WithSync.
+// The default FS implements it.
+type SyncFS interface {
+	FS
+	// Sync flushes a file or directory to stable storage,
+	// like os.File.Sync.
+	Sync(name string) error
+}
+
+// WithFS sets the file system of the cache directory,
+// for example to inject failures in tests.
+func WithFS(fsys FS) Option {
+	return func(c *Cache) {
+		if fsys == nil {
+			c.err = errors.New("file system is nil")
+			return
+		}
+		c.fsys = fsys
+	}
+}
+
+// Default permissions of entry files and cache directories.
+const (
+	defaultFileMode fs.FileMode = 0644
+	defaultDirMode  fs.FileMode = 0755
+)
+
+// WithFileMode sets the permissions of entry files, such as 0600 for secrets.
+// The default is 0644.
+// Existing files keep their permissions until they are rewritten.
+func WithFileMode(mode fs.FileMode) Option {
+	return func(c *Cache) {
+		if mode == 0 || mode&^fs.ModePerm != 0 {
+			c.err = fmt.Errorf("invalid file mode: %v", mode)
+			return
+		}
+		c.fileMode = mode
+	}
+}
+
+// WithDirMode sets the permissions of the cache directory and the
+// shard and namespace subdirectories it creates, such as 0700 for secrets.
+// The default is 0755.
+// As with os.MkdirAll, the umask applies, and existing directories
+// keep their permissions.
+func WithDirMode(mode fs.FileMode) Option {
+	return func(c *Cache) {
+		if mode == 0 || mode&^fs.ModePerm != 0 {
+			c.err = fmt.Errorf("invalid directory mode: %v", mode)
+			return
+		}
+		c.dirMode = mode
+	}
+}
+
+// WithSync flushes each file the cache writes to stable storage,
+// and then the directory it is renamed into, before the write returns,
+// so a write that succeeded survives a crash or power failure.
+// It makes writes much slower.
+// Removals aren't flushed, so a removed entry may reappear after a crash.
+// The file system of the cache must implement SyncFS.
+func WithSync() Option {
+	return func(c *Cache) {
+		c.sync = true
+	}
+}
+
+// osFS is the file system of the operating system.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) WriteFile(name string, r io.Reader, perm fs.FileMode) (int64, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	// Set the mode explicitly, since OpenFile applies the umask.
+	err = f.Chmod(perm)
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	return n, f.Close()
+}
+
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (osFS) Rename(oldpath string, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// writeFileAtomic atomically writes the contents of r to a file
+// with the given permissions.
+// It writes to a temporary file in the same directory and renames it,
+// so readers never observe a partially written file.
+// If durable is true, it flushes the temporary file before renaming it,
+// and the directory after, so the write survives a crash.
+// The file system must then implement SyncFS.
+func writeFileAtomic(fsys FS, path string, r io.Reader, perm fs.FileMode, durable bool) (int64, error) {
+	tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.%016x.tmp", filepath.Base(path), rand.Uint64()))
+	n, err := fsys.WriteFile(tmp, r, perm)
+	if err == nil && durable {
+		err = fsys.(SyncFS).Sync(tmp)
+	}
+	if err != nil {
+		// Remove the partially written temporary file, if any.
+		_ = fsys.Remove(tmp)
+		return 0, err
+	}
+	err = fsys.Rename(tmp, path)
+	if err != nil {
+		_ = fsys.Remove(tmp)
+		return 0, err
+	}
+	if durable {
+		err = fsys.(SyncFS).Sync(filepath.Dir(path))
+		if err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// dirFS is the fs.FS of a directory in a file system.
+type dirFS struct {
+	fsys FS
+	dir  string
+}
+
+func (d dirFS) Open(name string) (fs.File, error) {
+	return d.fsys.Open(filepath.Join(d.dir, filepath.FromSlash(name)))
+}
+
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return d.fsys.ReadDir(filepath.Join(d.dir, filepath.FromSlash(name)))
+}
@@ -0,0 +1,396 @@
+package diskcache_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// faultFS is a file system backed by the operating system
+// that fails operations chosen by the test.
+type faultFS struct {
+	// fail returns the error for an operation on a path, or nil.
+	fail func(op string, name string) error
+	// partial is the number of bytes WriteFile writes before a failure.
+	partial int64
+}
+
+func (f *faultFS) err(op string, name string) error {
+	if f.fail == nil {
+		return nil
+	}
+	err := f.fail(op, name)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
+
+func (f *faultFS) Open(name string) (fs.File, error) {
+	if err := f.err("open", name); err != nil {
+		return nil, err
+	}
+	return os.Open(name)
+}
+
+func (f *faultFS) WriteFile(name string, r io.Reader, perm fs.FileMode) (int64, error) {
+	writeErr := f.err("write", name)
+	if writeErr != nil {
+		r = io.LimitReader(r, f.partial)
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(file, r)
+	file.Close()
+	if writeErr != nil {
+		return n, writeErr
+	}
+	return n, err
+}
+
+func (f *faultFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := f.err("readdir", name); err != nil {
+		return nil, err
+	}
+	return os.ReadDir(name)
+}
+
+func (f *faultFS) Remove(name string) error {
+	if err := f.err("remove", name); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+func (f *faultFS) RemoveAll(name string) error {
+	if err := f.err("removeall", name); err != nil {
+		return err
+	}
+	return os.RemoveAll(name)
+}
+
+func (f *faultFS) Rename(oldpath string, newpath string) error {
+	if err := f.err("rename", newpath); err != nil {
+		return err
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+func (f *faultFS) MkdirAll(name string, perm fs.FileMode) error {
+	if err := f.err("mkdir", name); err != nil {
+		return err
+	}
+	return os.MkdirAll(name, perm)
+}
+
+func (f *faultFS) Sync(name string) error {
+	if err := f.err("sync", name); err != nil {
+		return err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+// failOp returns a fail function that fails every operation op with err.
+func failOp(op string, err error) func(string, string) error {
+	return func(o string, _ string) error {
+		if o == op {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestFS(t *testing.T) {
+	t.Run("TestNilFS", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(nil))
+		if err == nil {
+			t.Fatalf("Expected error for nil file system")
+		}
+	})
+
+	t.Run("TestMkdirError", func(t *testing.T) {
+		fsys := &faultFS{fail: failOp("mkdir", syscall.EACCES)}
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys))
+		if !errors.Is(err, syscall.EACCES) {
+			t.Fatalf("Expected EACCES, got %v", err)
+		}
+	})
+
+	t.Run("TestNoSpace", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		fsys := &faultFS{}
+		cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("old"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		fsys.fail = failOp("write", syscall.ENOSPC)
+		fsys.partial = 5
+		err = cache.Set("key", []byte("new value"), diskcache.NoExpiry)
+		if !errors.Is(err, syscall.ENOSPC) {
+			t.Fatalf("Expected ENOSPC, got %v", err)
+		}
+		fsys.fail = nil
+		// The partial write doesn't replace the entry.
+		got, err := cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "old" {
+			t.Fatalf("Expected old, got %s", got)
+		}
+		assertNoTempFiles(t, cacheDir)
+	})
+
+	t.Run("TestRenameError", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		fsys := &faultFS{fail: failOp("rename", syscall.EACCES)}
+		cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if !errors.Is(err, syscall.EACCES) {
+			t.Fatalf("Expected EACCES, got %v", err)
+		}
+		_, err = cache.Get("key")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected ErrNotExist, got %v", err)
+		}
+		assertNoTempFiles(t, cacheDir)
+	})
+
+	t.Run("TestReadError", func(t *testing.T) {
+		fsys := &faultFS{}
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		fsys.fail = failOp("open", syscall.EACCES)
+		_, err = cache.Get("key")
+		if !errors.Is(err, syscall.EACCES) {
+			t.Fatalf("Expected EACCES, got %v", err)
+		}
+		fsys.fail = failOp("readdir", syscall.EIO)
+		_, err = cache.List()
+		if !errors.Is(err, syscall.EIO) {
+			t.Fatalf("Expected EIO, got %v", err)
+		}
+	})
+
+	t.Run("TestRemoveError", func(t *testing.T) {
+		fsys := &faultFS{}
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		fsys.fail = failOp("remove", syscall.EACCES)
+		err = cache.Remove("key")
+		if !errors.Is(err, syscall.EACCES) {
+			t.Fatalf("Expected EACCES, got %v", err)
+		}
+		fsys.fail = failOp("removeall", syscall.EACCES)
+		err = cache.Delete()
+		if !errors.Is(err, syscall.EACCES) {
+			t.Fatalf("Expected EACCES, got %v", err)
+		}
+	})
+}
+
+// assertNoTempFiles fails the test if a directory has temporary files.
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Error reading directory: %v", err)
+	}
+	for _, dirEntry := range dirEntries {
+		if strings.HasSuffix(dirEntry.Name(), ".tmp") {
+			t.Fatalf("Expected no temporary files, found %s", dirEntry.Name())
+		}
+	}
+}
+
+func TestModes(t *testing.T) {
+	t.Run("TestDefaultModes", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		assertMode(t, cache.Filepath("key"), 0644)
+	})
+
+	t.Run("TestPrivateModes", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithSharding(), diskcache.WithFileMode(0600), diskcache.WithDirMode(0700))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		assertMode(t, cacheDir, fs.ModeDir|0700)
+		assertMode(t, path.Dir(cache.Filepath("key")), fs.ModeDir|0700)
+		assertMode(t, cache.Filepath("key"), 0600)
+		ns, err := cache.Namespace("users")
+		if err != nil {
+			t.Fatalf("Error creating namespace: %v", err)
+		}
+		assertMode(t, path.Join(cacheDir, "users"), fs.ModeDir|0700)
+		err = ns.SetReader("user", strings.NewReader("alice"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		assertMode(t, ns.Filepath("user"), 0600)
+		assertMode(t, ns.ValueFilepath("user"), 0600)
+	})
+
+	t.Run("TestInvalidModes", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		for _, option := range []diskcache.Option{
+			diskcache.WithFileMode(0),
+			diskcache.WithFileMode(fs.ModeDir | 0644),
+			diskcache.WithDirMode(0),
+			diskcache.WithDirMode(01777),
+		} {
+			_, err := diskcache.New(cacheDir, option)
+			if err == nil {
+				t.Fatalf("Expected error for invalid mode")
+			}
+		}
+	})
+}
+
+// assertMode fails the test if a file doesn't have the given mode.
+func assertMode(t *testing.T, name string, want fs.FileMode) {
+	t.Helper()
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("Error reading file info: %v", err)
+	}
+	if info.Mode() != want {
+		t.Fatalf("Expected mode %v for %s, got %v", want, name, info.Mode())
+	}
+}
+
+func TestWithSync(t *testing.T) {
+	var syncs []string
+	fsys := &faultFS{fail: func(op string, name string) error {
+		if op == "sync" {
+			syncs = append(syncs, name)
+		}
+		return nil
+	}}
+
+	t.Run("TestSync", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys), diskcache.WithSync())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		syncs = nil
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		if len(syncs) != 2 || !strings.HasSuffix(syncs[0], ".tmp") || syncs[1] != cacheDir {
+			t.Fatalf("Expected the file and then the directory to be synced, got %v", syncs)
+		}
+	})
+
+	t.Run("TestShards", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys), diskcache.WithSync(), diskcache.WithSharding())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		syncs = nil
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		shard := filepath.Dir(cache.Filepath("key"))
+		if len(syncs) != 4 {
+			t.Fatalf("Expected 4 syncs, got %v", syncs)
+		}
+		got := []string{syncs[0], syncs[1], syncs[3]}
+		if !slices.Equal(got, []string{filepath.Dir(shard), cacheDir, shard}) {
+			t.Fatalf("Expected the shard directories to be synced, got %v", syncs)
+		}
+	})
+
+	t.Run("TestSyncError", func(t *testing.T) {
+		fsys := &faultFS{fail: failOp("sync", syscall.EIO)}
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys), diskcache.WithSync())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if !errors.Is(err, syscall.EIO) {
+			t.Fatalf("Expected EIO, got %v", err)
+		}
+		entries, err := os.ReadDir(cache.Dir())
+		if err != nil {
+			t.Fatalf("Error reading directory: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("Expected no files, got %v", entries)
+		}
+	})
+
+	t.Run("TestUnsupported", func(t *testing.T) {
+		fsys := struct{ diskcache.FS }{&faultFS{}}
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys), diskcache.WithSync())
+		if err == nil {
+			t.Fatalf("Expected error for file system without sync")
+		}
+	})
+
+	t.Run("TestOS", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithSync())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		value, err := cache.Get("key")
+		if err != nil || string(value) != "value" {
+			t.Fatalf("Expected value, got %s, %v", value, err)
+		}
+	})
+}
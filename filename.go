@@ -0,0 +1,14 @@
+package diskcache
+
+// WithFilenameFunc sets the function that maps keys to filenames.
+// The function returns the filename without the extension,
+// and must return a distinct, valid filename for each key.
+// The default is the hex-encoded SHA-256 hash of the key.
+// A sharded cache shards filenames that start with four hex characters,
+// such as hex-encoded hashes, and keeps the others unsharded.
+func WithFilenameFunc(fn func(key string) string) Option {
+	return func(c *Cache) {
+		c.filenameFunc = fn
+		c.keyFunc = nil
+	}
+}
@@ -0,0 +1,71 @@
+package diskcache_test
+
+import (
+	"crypto/md5"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestFilenameFunc(t *testing.T) {
+	md5Name := func(key string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(key)))
+	}
+	for _, tc := range []struct {
+		name    string
+		options []diskcache.Option
+		want    func(key string) string
+	}{
+		{
+			"TestMD5",
+			[]diskcache.Option{diskcache.WithFilenameFunc(md5Name)},
+			func(key string) string { return md5Name(key) + ".json" },
+		},
+		{
+			"TestShardedMD5",
+			[]diskcache.Option{diskcache.WithFilenameFunc(md5Name), diskcache.WithSharding()},
+			func(key string) string {
+				name := md5Name(key)
+				return path.Join(name[0:2], name[2:4], name+".json")
+			},
+		},
+		{
+			"TestShardedPlain",
+			[]diskcache.Option{diskcache.WithFilenameFunc(func(key string) string { return key }), diskcache.WithSharding()},
+			func(key string) string { return key + ".json" },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, tc.options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			key := "testkey"
+			if got := cache.Filename(key); got != tc.want(key) {
+				t.Fatalf("Want filename to be %s, got %s", tc.want(key), got)
+			}
+			err = cache.Set(key, []byte("value"), 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			got, err := cache.Get(key)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			if string(got) != "value" {
+				t.Fatalf("Expected cache value to be value, got %s", string(got))
+			}
+			data, err := cache.List()
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			if len(data) != 1 {
+				t.Fatalf("Expected 1 key, got %d", len(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package diskcache
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FileSystem returns a read-only file system in which each unexpired
+// entry is a file named by its key, holding its value.
+// Slashes in keys are directories, so an entry with the key
+// assets/app.css is the file app.css in the directory assets.
+// Keys that aren't valid paths, such as those with a leading slash,
+// are left out, and keys inside another key aren't listed.
+// Wrap it with http.FS to serve the values with http.FileServer.
+// Reading files doesn't count as accessing the entries.
+func (c Cache) FileSystem() fs.FS {
+	return cacheFS{cache: c}
+}
+
+// cacheFS is the file system of the values of a cache.
+type cacheFS struct {
+	cache Cache
+}
+
+// Open opens the file of an unexpired entry,
+// or a directory of the keys with its name as a prefix.
+// The entries of a directory are listed when it's first read.
+func (f cacheFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name != "." {
+		file, err := f.openFile(name)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return file, err
+		}
+		ok, err := f.isDir(name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return &cacheDir{fsys: f, name: name, info: fileInfo{name: path.Base(name), dir: true}}, nil
+}
+
+// isDir reports whether a directory has any entries,
+// stopping at the first unexpired entry in it.
+func (f cacheFS) isDir(dir string) (bool, error) {
+	prefix := dir + "/"
+	now := f.cache.now()
+	var found bool
+	err := f.cache.walkMeta(func(key string) bool {
+		return strings.HasPrefix(key, prefix) && fs.ValidPath(key)
+	}, func(data Data) bool {
+		found = !expired(data.Expiry, now)
+		return !found
+	})
+	return found, err
+}
+
+// openFile opens the file of an unexpired entry.
+func (f cacheFS) openFile(name string) (fs.File, error) {
+	entry, err := f.cache.Read(name)
+	if err == nil && expired(entry.Expiry, f.cache.now()) {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &cacheFile{info: entryInfo(entry), Reader: bytes.NewReader(entry.Value)}, nil
+}
+
+// readDir returns the entries of a directory, sorted by name.
+// A file and a directory with the same name are listed as the file,
+// which is what Open opens.
+// It reads the keys and expiries of the entries without their values.
+func (f cacheFS) readDir(dir string) ([]fs.DirEntry, error) {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+	list, err := f.cache.metaMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := f.cache.now()
+	children := make(map[string]fs.DirEntry)
+	for _, entry := range list {
+		if !fs.ValidPath(entry.Key) || !strings.HasPrefix(entry.Key, prefix) || expired(entry.Expiry, now) {
+			continue
+		}
+		name, _, isDir := strings.Cut(strings.TrimPrefix(entry.Key, prefix), "/")
+		if isDir {
+			if _, ok := children[name]; !ok {
+				children[name] = fs.FileInfoToDirEntry(fileInfo{name: name, dir: true})
+			}
+			continue
+		}
+		children[name] = fileEntry{fsys: f, key: entry.Key}
+	}
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, entry := range children {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// fileEntry is the directory entry of the file of a cache entry.
+// It reads the entry for its info, since listing the keys
+// of an indexed cache doesn't read their values.
+type fileEntry struct {
+	fsys cacheFS
+	key  string
+}
+
+func (e fileEntry) Name() string      { return path.Base(e.key) }
+func (e fileEntry) IsDir() bool       { return false }
+func (e fileEntry) Type() fs.FileMode { return 0 }
+
+func (e fileEntry) Info() (fs.FileInfo, error) {
+	file, err := e.fsys.openFile(e.key)
+	if err != nil {
+		return nil, err
+	}
+	return file.Stat()
+}
+
+// fileInfo describes a file or directory of a cache file system.
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+// entryInfo returns the info of the file of a cache entry,
+// which was last modified when the entry was written.
+func entryInfo(entry Data) fileInfo {
+	return fileInfo{name: path.Base(entry.Key), size: int64(len(entry.Value)), modTime: entry.CreatedAt}
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// cacheFile is an open file of a cache file system.
+// It reads, seeks, and reads at offsets in the value of the entry.
+type cacheFile struct {
+	info fileInfo
+	*bytes.Reader
+}
+
+func (f *cacheFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *cacheFile) Close() error               { return nil }
+
+// cacheDir is an open directory of a cache file system.
+type cacheDir struct {
+	fsys    cacheFS
+	name    string
+	info    fileInfo
+	entries []fs.DirEntry
+	listed  bool
+	offset  int
+}
+
+func (d *cacheDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *cacheDir) Close() error               { return nil }
+
+func (d *cacheDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir returns the next n entries of the directory, like fs.ReadDirFile.
+func (d *cacheDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		entries, err := d.fsys.readDir(d.name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries = entries
+		d.listed = true
+	}
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
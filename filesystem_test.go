@@ -0,0 +1,129 @@
+package diskcache_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestFileSystem(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		options []diskcache.Option
+	}{
+		{"TestDir", nil},
+		{"TestIndex", []diskcache.Option{diskcache.WithIndex()}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), tt.options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			entries := map[string]string{
+				"index.html":          "<h1>hello</h1>",
+				"assets/app.css":      "body {}",
+				"assets/js/app.js":    "alert(1)",
+				"/absolute":           "invalid",
+				"assets/../escape":    "invalid",
+				"assets/js/app.js/in": "hidden",
+			}
+			for key, value := range entries {
+				err := cache.Set(key, []byte(value), 1*time.Hour)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+			err = cache.Set("expired", []byte("value"), 1*time.Nanosecond)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			time.Sleep(1 * time.Millisecond)
+			fsys := cache.FileSystem()
+
+			err = fstest.TestFS(fsys, "index.html", "assets/app.css", "assets/js/app.js")
+			if err != nil {
+				t.Fatalf("Error testing file system: %v", err)
+			}
+			_, err = fs.Stat(fsys, "expired")
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("Expected ErrNotExist for expired entry, got %v", err)
+			}
+			names, err := fs.ReadDir(fsys, ".")
+			if err != nil {
+				t.Fatalf("Error reading directory: %v", err)
+			}
+			if len(names) != 2 {
+				t.Fatalf("Expected assets and index.html, got %v", names)
+			}
+
+			server := httptest.NewServer(http.FileServer(http.FS(fsys)))
+			defer server.Close()
+			resp, err := http.Get(server.URL + "/assets/app.css")
+			if err != nil {
+				t.Fatalf("Error getting file: %v", err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Error reading body: %v", err)
+			}
+			if string(body) != "body {}" || resp.Header.Get("Content-Type") != "text/css; charset=utf-8" {
+				t.Fatalf("Expected the stylesheet, got %q as %s", body, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestFileSystemHeaders(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithCompression(diskcache.Gzip))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.Set("assets/app.css", []byte("body {}"), 1*time.Hour)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	// Corrupt the value, so only decoding it fails.
+	filename := cache.Filepath("assets/app.css")
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Error reading file: %v", err)
+	}
+	var rec map[string]any
+	err = json.Unmarshal(contents, &rec)
+	if err != nil {
+		t.Fatalf("Error unmarshaling file: %v", err)
+	}
+	rec["Value"] = []byte("not gzip")
+	contents, err = json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Error marshaling file: %v", err)
+	}
+	err = os.WriteFile(filename, contents, 0644)
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	fsys := cache.FileSystem()
+
+	names, err := fs.ReadDir(fsys, "assets")
+	if err != nil {
+		t.Fatalf("Error reading directory: %v", err)
+	}
+	if len(names) != 1 || names[0].Name() != "app.css" {
+		t.Fatalf("Expected app.css, got %v", names)
+	}
+	_, err = fsys.Open("missing.css")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Expected ErrNotExist for missing file, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package diskcache
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Filter returns a list option that keeps the cache entries
+// for which keep returns true, in order.
+func Filter(keep func(Data) bool) ListOption {
+	return func(entries []Data) []Data {
+		return slices.DeleteFunc(entries, func(entry Data) bool {
+			return !keep(entry)
+		})
+	}
+}
+
+// FilterExpired returns a list option that keeps only expired entries.
+// Entries are checked against the system clock when the option is applied.
+func FilterExpired() ListOption {
+	return Filter(func(entry Data) bool {
+		return expired(entry.Expiry, time.Now())
+	})
+}
+
+// FilterUnexpired returns a list option that keeps only unexpired entries,
+// including entries that never expire.
+// Entries are checked against the system clock when the option is applied.
+func FilterUnexpired() ListOption {
+	return Filter(func(entry Data) bool {
+		return !expired(entry.Expiry, time.Now())
+	})
+}
+
+// FilterKeyPrefix returns a list option that keeps only entries
+// whose keys start with a prefix.
+// ListPrefix is faster if the cache is indexed or encodes keys in filenames.
+func FilterKeyPrefix(prefix string) ListOption {
+	return Filter(func(entry Data) bool {
+		return strings.HasPrefix(entry.Key, prefix)
+	})
+}
+
+// FilterExpiringWithin returns a list option that keeps only unexpired
+// entries that expire within a duration.
+// Entries are checked against the system clock when the option is applied.
+func FilterExpiringWithin(d time.Duration) ListOption {
+	return Filter(func(entry Data) bool {
+		now := time.Now()
+		return !entry.Expiry.IsZero() && !expired(entry.Expiry, now) && !entry.Expiry.After(now.Add(d))
+	})
+}
@@ -0,0 +1,77 @@
+package diskcache_test
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestFilter(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for key, duration := range map[string]time.Duration{
+		"api:expired": -1 * time.Minute,
+		"api:soon":    2 * time.Minute,
+		"api:later":   1 * time.Hour,
+		"db:soon":     3 * time.Minute,
+		"db:never":    diskcache.NoExpiry,
+	} {
+		err := cache.Set(key, []byte(key), duration)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	for name, tc := range map[string]struct {
+		options []diskcache.ListOption
+		want    string
+	}{
+		"TestExpired":        {[]diskcache.ListOption{diskcache.FilterExpired()}, "[api:expired]"},
+		"TestUnexpired":      {[]diskcache.ListOption{diskcache.FilterUnexpired(), diskcache.SortByKey}, "[api:later api:soon db:never db:soon]"},
+		"TestKeyPrefix":      {[]diskcache.ListOption{diskcache.FilterKeyPrefix("api:"), diskcache.SortByKey}, "[api:expired api:later api:soon]"},
+		"TestExpiringWithin": {[]diskcache.ListOption{diskcache.FilterExpiringWithin(5 * time.Minute), diskcache.SortByExpiry}, "[api:soon db:soon]"},
+		"TestComposed": {[]diskcache.ListOption{
+			diskcache.SortByKey,
+			diskcache.FilterKeyPrefix("api:"),
+			diskcache.FilterExpiringWithin(5 * time.Minute),
+		}, "[api:soon]"},
+		"TestCustom": {[]diskcache.ListOption{diskcache.Filter(func(entry diskcache.Data) bool {
+			return entry.Expiry.IsZero()
+		})}, "[db:never]"},
+		"TestSort": {[]diskcache.ListOption{diskcache.FilterKeyPrefix("db:"), diskcache.Sort(func(entries []diskcache.Data) {
+			slices.SortFunc(entries, func(a, b diskcache.Data) int {
+				return strings.Compare(b.Key, a.Key)
+			})
+		})}, "[db:soon db:never]"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			list, err := cache.List(tc.options...)
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			var keys []string
+			for _, entry := range list {
+				keys = append(keys, entry.Key)
+			}
+			if fmt.Sprint(keys) != tc.want {
+				t.Fatalf("Expected %s, got %v", tc.want, keys)
+			}
+		})
+	}
+
+	t.Run("TestListPage", func(t *testing.T) {
+		page, total, err := cache.ListPage(0, 1, diskcache.FilterKeyPrefix("db:"))
+		if err != nil {
+			t.Fatalf("Error listing page: %v", err)
+		}
+		if total != 2 || len(page) != 1 || page[0].Key != "db:never" {
+			t.Fatalf("Expected db:never of 2 entries, got %v of %d", page, total)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package diskcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Format is an on-disk encoding of cache entries.
+type Format string
+
+const (
+	// JSON encodes entries as JSON documents with base64-encoded values.
+	// It is the default format.
+	JSON Format = "json"
+	// Binary encodes entries as a magic number, a length-prefixed JSON header,
+	// and the raw value bytes, which avoids the base64 overhead of JSON.
+	Binary Format = "binary"
+	// CBOR encodes entries as a magic number and a CBOR document,
+	// which is more compact than JSON and keeps values as raw bytes.
+	CBOR Format = "cbor"
+	// MessagePack encodes entries as a magic number and a MessagePack
+	// document, which is more compact than JSON and keeps values as raw bytes.
+	MessagePack Format = "msgpack"
+)
+
+// Magic numbers at the start of entry files in formats other than JSON.
+var (
+	binaryMagic  = []byte("DCB\x01")
+	cborMagic    = []byte("DCC\x01")
+	msgpackMagic = []byte("DCM\x01")
+)
+
+// cborEnc encodes records as CBOR, keeping the nanoseconds of times.
+var cborEnc, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+
+// formatOf returns the format of an entry file from its contents.
+func formatOf(data []byte) Format {
+	switch {
+	case bytes.HasPrefix(data, binaryMagic):
+		return Binary
+	case bytes.HasPrefix(data, cborMagic):
+		return CBOR
+	case bytes.HasPrefix(data, msgpackMagic):
+		return MessagePack
+	default:
+		return JSON
+	}
+}
+
+// WithFormat sets the on-disk format of the entries written by the cache.
+// Entry files keep the cache's file extension.
+// The format of each file is detected from its contents when it's read,
+// so caches with entries in mixed formats still read correctly.
+func WithFormat(format Format) Option {
+	return func(c *Cache) {
+		switch format {
+		case JSON, Binary, CBOR, MessagePack:
+			c.format = format
+		default:
+			c.err = fmt.Errorf("unknown format: %q", format)
+		}
+	}
+}
+
+// marshal encodes a record in the cache's format.
+func (c Cache) marshal(rec record) ([]byte, error) {
+	switch c.format {
+	case CBOR:
+		data, err := cborEnc.Marshal(rec)
+		if err != nil {
+			return nil, err
+		}
+		return append(bytes.Clone(cborMagic), data...), nil
+	case MessagePack:
+		var buf bytes.Buffer
+		buf.Write(msgpackMagic)
+		enc := msgpack.NewEncoder(&buf)
+		enc.SetCustomStructTag("json")
+		err := enc.Encode(rec)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case Binary:
+		return marshalBinary(rec)
+	default:
+		return json.Marshal(rec)
+	}
+}
+
+// marshalBinary encodes a record in the binary format.
+func marshalBinary(rec record) ([]byte, error) {
+	value := rec.Value
+	rec.Value = nil
+	header, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(binaryMagic) + 4 + len(header) + len(value))
+	buf.Write(binaryMagic)
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(header))))
+	buf.Write(header)
+	buf.Write(value)
+	return buf.Bytes(), nil
+}
+
+// unmarshal decodes a record in any format.
+func unmarshal(data []byte) (record, error) {
+	var rec record
+	switch formatOf(data) {
+	case JSON:
+		err := json.Unmarshal(data, &rec)
+		return rec, err
+	case CBOR:
+		err := cbor.Unmarshal(data[len(cborMagic):], &rec)
+		return rec, err
+	case MessagePack:
+		dec := msgpack.NewDecoder(bytes.NewReader(data[len(msgpackMagic):]))
+		dec.SetCustomStructTag("json")
+		err := dec.Decode(&rec)
+		return rec, err
+	}
+	data = data[len(binaryMagic):]
+	if len(data) < 4 {
+		return record{}, errors.New("binary entry is truncated")
+	}
+	n := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint64(len(data)) < uint64(n) {
+		return record{}, errors.New("binary entry is truncated")
+	}
+	err := json.Unmarshal(data[:n], &rec)
+	if err != nil {
+		return record{}, err
+	}
+	rec.Value = data[n:]
+	return rec, nil
+}
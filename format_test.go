@@ -0,0 +1,156 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestFormat(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithFormat(diskcache.Binary))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "binary"
+	value := bytes.Repeat([]byte{0, 1, 2, 3, 255}, 1000)
+	err = cache.Set(key, value, 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestRoundTrip", func(t *testing.T) {
+		data, err := cache.Read(key)
+		if err != nil {
+			t.Fatalf("Error loading cache: %v", err)
+		}
+		if data.Key != key {
+			t.Fatalf("Expected cache key to be %s, got %s", key, data.Key)
+		}
+		if !bytes.Equal(data.Value, value) {
+			t.Fatalf("Expected cache value to round trip")
+		}
+		if data.Expiry.IsZero() {
+			t.Fatalf("Expected cache expiry to be non-zero")
+		}
+	})
+
+	t.Run("TestRawValue", func(t *testing.T) {
+		contents, err := os.ReadFile(cache.Filepath(key))
+		if err != nil {
+			t.Fatalf("Error reading file: %v", err)
+		}
+		if !bytes.HasSuffix(contents, value) {
+			t.Fatalf("Expected file to end with the raw value")
+		}
+		if len(contents) > len(value)+300 {
+			t.Fatalf("Expected file size %d to be close to value size %d", len(contents), len(value))
+		}
+	})
+
+	t.Run("TestMixedFormats", func(t *testing.T) {
+		plain, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = plain.Set("json", value, 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		for _, c := range []diskcache.Cache{cache, plain} {
+			data, err := c.List()
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			if len(data) != 2 {
+				t.Fatalf("Expected 2 keys, got %d", len(data))
+			}
+			for _, d := range data {
+				if !bytes.Equal(d.Value, value) {
+					t.Fatalf("Expected value of %s to round trip", d.Key)
+				}
+			}
+		}
+	})
+
+	t.Run("TestCompressed", func(t *testing.T) {
+		cache, err := diskcache.New(cacheDir, diskcache.WithFormat(diskcache.Binary), diskcache.WithCompression(diskcache.Gzip))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("compressed", value, 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		got, err := cache.Get("compressed")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected cache value to round trip")
+		}
+	})
+
+	t.Run("TestEnvelopeFormats", func(t *testing.T) {
+		for _, format := range []diskcache.Format{diskcache.CBOR, diskcache.MessagePack} {
+			dir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(dir, diskcache.WithFormat(format), diskcache.WithIndex())
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			err = cache.SetWithMeta(key, value, map[string]string{"type": "bytes"}, 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			err = cache.Set("forever", []byte("value"), diskcache.NoExpiry)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			contents, err := os.ReadFile(cache.Filepath(key))
+			if err != nil {
+				t.Fatalf("Error reading file: %v", err)
+			}
+			if len(contents) > len(value)+300 {
+				t.Fatalf("Expected %s file size %d to be close to value size %d", format, len(contents), len(value))
+			}
+			// Another cache detects the format and reloads the index.
+			plain, err := diskcache.New(dir, diskcache.WithIndex())
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			for _, c := range []diskcache.Cache{cache, plain} {
+				data, err := c.Read(key)
+				if err != nil {
+					t.Fatalf("Error loading %s cache: %v", format, err)
+				}
+				if data.Key != key || !bytes.Equal(data.Value, value) || data.Meta["type"] != "bytes" {
+					t.Fatalf("Expected %s entry to round trip, got key %q and meta %v", format, data.Key, data.Meta)
+				}
+				if !data.Expiry.Equal(cache.Expiry(key)) || data.Expiry.IsZero() || data.CreatedAt.IsZero() {
+					t.Fatalf("Expected %s times to round trip, got %s and %s", format, data.Expiry, data.CreatedAt)
+				}
+				if !data.LastAccessed.IsZero() {
+					t.Fatalf("Expected %s zero time to round trip, got %s", format, data.LastAccessed)
+				}
+				if c.IsExpired("forever") || !c.Expiry("forever").IsZero() {
+					t.Fatalf("Expected %s entry to never expire, got %s", format, c.Expiry("forever"))
+				}
+				infos, err := c.ListInfo()
+				if err != nil || len(infos) != 2 {
+					t.Fatalf("Expected 2 %s entries, got %v, %v", format, infos, err)
+				}
+			}
+		}
+	})
+
+	t.Run("TestUnknown", func(t *testing.T) {
+		_, err := diskcache.New(cacheDir, diskcache.WithFormat("bogus"))
+		if err == nil {
+			t.Fatalf("Expected error for unknown format, but got nil")
+		}
+	})
+}
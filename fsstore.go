@@ -0,0 +1,58 @@
+package diskcache
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+)
+
+// NewFromFS creates a read-only cache backed by a file system,
+// such as an embed.FS holding a pre-built cache,
+// or an os.DirFS of a cache mounted read-only.
+// The file system holds the contents of a cache directory.
+// It accepts options to configure the cache,
+// which must match the options the cache was built with.
+// Operations that write or remove entries return an error wrapping ErrReadOnly.
+func NewFromFS(fsys fs.FS, options ...Option) (Cache, error) {
+	if fsys == nil {
+		return Cache{}, errors.New("file system is nil")
+	}
+	return Cache{store: fsStore{fsys: fsys}, ext: defaultExt, readOnly: true}.open(options)
+}
+
+// fsStore is a read-only store backed by a file system.
+// Like the default store, it descends into shard subdirectories
+// if the cache is sharded.
+type fsStore struct {
+	fsys    fs.FS
+	sharded bool
+}
+
+// WriteEntry returns ErrReadOnly.
+func (s fsStore) WriteEntry(name string, r io.Reader) (int64, error) {
+	return 0, errReadOnly("write", name)
+}
+
+// ReadEntry opens a file for reading.
+func (s fsStore) ReadEntry(name string) (io.ReadCloser, error) {
+	return s.fsys.Open(filepath.ToSlash(name))
+}
+
+// RemoveEntry returns ErrReadOnly.
+func (s fsStore) RemoveEntry(name string) error {
+	return errReadOnly("remove", name)
+}
+
+// ListEntries returns the files in the root of the file system and,
+// if the store is sharded, in its shard subdirectories.
+func (s fsStore) ListEntries() ([]StoreEntry, error) {
+	entries, err := listFS(s.fsys, s.sharded)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Name = filepath.FromSlash(entries[i].Name)
+	}
+	return entries, nil
+}
@@ -0,0 +1,84 @@
+package diskcache_test
+
+import (
+	"errors"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestNewFromFS(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	built, err := diskcache.New(cacheDir, diskcache.WithSharding())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = built.Set("key", []byte("value"), diskcache.NoExpiry)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	ns, err := built.Namespace("users")
+	if err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+	err = ns.Set("user", []byte("alice"), diskcache.NoExpiry)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	cache, err := diskcache.NewFromFS(os.DirFS(cacheDir), diskcache.WithSharding(), diskcache.WithIndex())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestRead", func(t *testing.T) {
+		got, err := cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", got)
+		}
+		list, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 1 {
+			t.Fatalf("Expected 1 entry, got %d", len(list))
+		}
+	})
+
+	t.Run("TestNamespace", func(t *testing.T) {
+		users, err := cache.Namespace("users")
+		if err != nil {
+			t.Fatalf("Error creating namespace: %v", err)
+		}
+		got, err := users.Get("user")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "alice" {
+			t.Fatalf("Expected cache value to be alice, got %s", got)
+		}
+	})
+
+	t.Run("TestReadOnly", func(t *testing.T) {
+		err := cache.Set("key", []byte("other"), 1*time.Minute)
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		err = cache.Remove("key")
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		got, err := cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", got)
+		}
+	})
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package diskcache
+
+import "os"
+
+// Sync flushes a file or directory to stable storage.
+func (osFS) Sync(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
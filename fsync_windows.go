@@ -0,0 +1,29 @@
+//go:build windows
+
+package diskcache
+
+import "os"
+
+// Sync flushes a file to stable storage.
+// Directories can't be flushed on Windows, where NTFS journals
+// renames itself, so syncing a directory does nothing.
+func (osFS) Sync(name string) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	// FlushFileBuffers requires a handle with write access.
+	f, err := os.OpenFile(name, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
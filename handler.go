@@ -0,0 +1,187 @@
+package diskcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TTLHeader is the HTTP header in which Handler reads the duration
+// of the entries it saves, as a Go duration such as 1h30m
+// or a number of seconds, and reports the remaining lifetime
+// of the entries it returns, in seconds.
+// A duration of zero saves an entry that never expires.
+const TTLHeader = "Diskcache-TTL"
+
+// Handler returns an HTTP handler that serves a cache as a REST API:
+//
+//	GET /keys/{key}     returns the value of an entry
+//	PUT /keys/{key}     saves the request body as the value of an entry
+//	DELETE /keys/{key}  removes an entry
+//	GET /keys           lists the unexpired entries as JSON
+//
+// Keys may contain slashes and are path-escaped.
+// PUT saves entries for the duration in the TTLHeader header,
+// or for the default duration of the cache if there is none.
+// GET reports the remaining lifetime of entries that expire
+// in the TTLHeader and Cache-Control headers.
+// GET /keys accepts a prefix query parameter to list only
+// the entries whose keys start with it.
+// Mount the handler under a prefix with http.StripPrefix.
+func Handler(c Cache) http.Handler {
+	h := handler{cache: c}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /keys", h.list)
+	mux.HandleFunc("GET /keys/{key...}", h.get)
+	mux.HandleFunc("PUT /keys/{key...}", h.put)
+	mux.HandleFunc("DELETE /keys/{key...}", h.delete)
+	return mux
+}
+
+// handler serves a cache over HTTP.
+type handler struct {
+	cache Cache
+}
+
+// entryJSON is an entry listed by GET /keys.
+type entryJSON struct {
+	Key    string     `json:"key"`
+	Expiry *time.Time `json:"expiry,omitempty"`
+	Size   int64      `json:"size"`
+}
+
+// get writes the value of an entry.
+func (h handler) get(w http.ResponseWriter, r *http.Request) {
+	c := h.cache.WithContext(r.Context())
+	key, ok := pathKey(w, r)
+	if !ok {
+		return
+	}
+	value, ttl, err := c.GetWithTTL(key)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	if ttl != NoExpiry {
+		// Round up, so clients don't treat an entry as expired early.
+		seconds := int64(math.Ceil(ttl.Seconds()))
+		w.Header().Set(TTLHeader, strconv.FormatInt(seconds, 10))
+		w.Header().Set("Cache-Control", "max-age="+strconv.FormatInt(seconds, 10))
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(value)))
+	w.Write(value)
+}
+
+// put saves the request body as the value of an entry.
+func (h handler) put(w http.ResponseWriter, r *http.Request) {
+	c := h.cache.WithContext(r.Context())
+	key, ok := pathKey(w, r)
+	if !ok {
+		return
+	}
+	ttl := c.DefaultTTL()
+	if header := r.Header.Get(TTLHeader); header != "" {
+		d, err := parseTTL(header)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ttl = d
+	}
+	err := c.SetReader(key, r.Body, ttl)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// delete removes an entry.
+func (h handler) delete(w http.ResponseWriter, r *http.Request) {
+	c := h.cache.WithContext(r.Context())
+	key, ok := pathKey(w, r)
+	if !ok {
+		return
+	}
+	err := c.Remove(key)
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// list writes the key, expiry, and size of the unexpired entries as JSON.
+func (h handler) list(w http.ResponseWriter, r *http.Request) {
+	c := h.cache.WithContext(r.Context())
+	infos, err := c.ListInfo()
+	if err != nil {
+		h.error(w, r, err)
+		return
+	}
+	prefix := r.URL.Query().Get("prefix")
+	now := c.now()
+	entries := []entryJSON{}
+	for _, info := range infos {
+		if !strings.HasPrefix(info.Key, prefix) || expired(info.Expiry, now) {
+			continue
+		}
+		entry := entryJSON{Key: info.Key, Size: info.Size}
+		if !info.Expiry.IsZero() {
+			entry.Expiry = &info.Expiry
+		}
+		entries = append(entries, entry)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// error writes the HTTP status of a cache error.
+// Unexpected errors are logged rather than returned to the client.
+func (h handler) error(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, ErrExpired):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, ErrReadOnly):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		h.cache.WithContext(r.Context()).log(slog.LevelError, "error serving request",
+			"method", r.Method, "path", r.URL.Path, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// pathKey returns the key in the path of a request,
+// or writes a bad request error if it is empty.
+func pathKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	key := r.PathValue("key")
+	if key == "" {
+		http.Error(w, "key cannot be empty", http.StatusBadRequest)
+		return "", false
+	}
+	return key, true
+}
+
+// parseTTL parses the value of a TTLHeader header.
+func parseTTL(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid %s header %q", TTLHeader, s)
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if seconds < 0 || seconds > math.MaxInt64/int64(time.Second) {
+			return 0, invalid
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, invalid
+	}
+	return d, nil
+}
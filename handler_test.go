@@ -0,0 +1,211 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestHandler(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithDefaultTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	server := httptest.NewServer(diskcache.Handler(cache))
+	defer server.Close()
+	do := func(method, target string, body string, header http.Header) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+target, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Error creating request: %v", err)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Error sending request: %v", err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Error reading response: %v", err)
+		}
+		return resp, string(b)
+	}
+
+	t.Run("TestPutAndGet", func(t *testing.T) {
+		resp, _ := do("PUT", "/keys/users/1", "alice", http.Header{diskcache.TTLHeader: {"90"}})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+		}
+		resp, body := do("GET", "/keys/users/1", "", nil)
+		if resp.StatusCode != http.StatusOK || body != "alice" {
+			t.Fatalf("Expected alice, got %d %q", resp.StatusCode, body)
+		}
+		if got := resp.Header.Get(diskcache.TTLHeader); got != "90" {
+			t.Fatalf("Expected TTL 90, got %q", got)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != "max-age=90" {
+			t.Fatalf("Expected max-age=90, got %q", got)
+		}
+		value, err := cache.Get("users/1")
+		if err != nil || string(value) != "alice" {
+			t.Fatalf("Expected alice in cache, got %q, %v", value, err)
+		}
+	})
+
+	t.Run("TestEscapedKey", func(t *testing.T) {
+		resp, _ := do("PUT", "/keys/a%20b%3Fc", "value", http.Header{diskcache.TTLHeader: {"1h30m"}})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+		}
+		if !cache.Has("a b?c") {
+			t.Fatalf("Expected key %q to exist", "a b?c")
+		}
+		ttl := time.Until(cache.Expiry("a b?c"))
+		if ttl < time.Hour || ttl > 90*time.Minute {
+			t.Fatalf("Expected TTL of 1h30m, got %s", ttl)
+		}
+	})
+
+	t.Run("TestDefaultTTL", func(t *testing.T) {
+		do("PUT", "/keys/default", "value", nil)
+		ttl := time.Until(cache.Expiry("default"))
+		if ttl < 59*time.Minute || ttl > time.Hour {
+			t.Fatalf("Expected default TTL of 1h, got %s", ttl)
+		}
+	})
+
+	t.Run("TestNoExpiry", func(t *testing.T) {
+		do("PUT", "/keys/forever", "value", http.Header{diskcache.TTLHeader: {"0"}})
+		resp, _ := do("GET", "/keys/forever", "", nil)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get(diskcache.TTLHeader) != "" {
+			t.Fatalf("Expected no TTL header, got %d %q", resp.StatusCode, resp.Header.Get(diskcache.TTLHeader))
+		}
+	})
+
+	t.Run("TestInvalidTTL", func(t *testing.T) {
+		for _, ttl := range []string{"-1", "soon", "-1h"} {
+			resp, _ := do("PUT", "/keys/invalid", "value", http.Header{diskcache.TTLHeader: {ttl}})
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status %d for TTL %q, got %d", http.StatusBadRequest, ttl, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("TestNotFound", func(t *testing.T) {
+		resp, _ := do("GET", "/keys/missing", "", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+		}
+		err := cache.Set("expired", []byte("value"), -time.Second)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		resp, _ = do("GET", "/keys/expired", "", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status %d for expired entry, got %d", http.StatusNotFound, resp.StatusCode)
+		}
+		resp, _ = do("DELETE", "/keys/missing", "", nil)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+
+	t.Run("TestList", func(t *testing.T) {
+		resp, body := do("GET", "/keys?prefix=users/", "", nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		var entries []struct {
+			Key    string
+			Expiry *time.Time
+			Size   int64
+		}
+		err := json.Unmarshal([]byte(body), &entries)
+		if err != nil {
+			t.Fatalf("Error unmarshaling list: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Key != "users/1" || entries[0].Expiry == nil || entries[0].Size == 0 {
+			t.Fatalf("Expected users/1, got %s", body)
+		}
+		_, body = do("GET", "/keys", "", nil)
+		if strings.Contains(body, `"expired"`) || !strings.Contains(body, `"forever"`) {
+			t.Fatalf("Expected only unexpired entries, got %s", body)
+		}
+	})
+
+	t.Run("TestDelete", func(t *testing.T) {
+		resp, _ := do("DELETE", "/keys/users/1", "", nil)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+		}
+		if cache.Has("users/1") {
+			t.Fatalf("Expected users/1 to be removed")
+		}
+	})
+
+	t.Run("TestReadOnly", func(t *testing.T) {
+		readOnly, err := diskcache.New(cacheDir, diskcache.WithReadOnly())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		req := httptest.NewRequest("PUT", "/keys/key", strings.NewReader("value"))
+		rec := httptest.NewRecorder()
+		diskcache.Handler(readOnly).ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("Expected status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+	})
+}
+
+func TestHandlerEncoding(t *testing.T) {
+	put := func(t *testing.T, cache diskcache.Cache) {
+		t.Helper()
+		handler := diskcache.Handler(cache)
+		req := httptest.NewRequest("PUT", "/keys/key", strings.NewReader("value"))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+		}
+		req = httptest.NewRequest("GET", "/keys/key", nil)
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Body.String() != "value" {
+			t.Fatalf("Expected value, got %d %q", rec.Code, rec.Body.String())
+		}
+		if _, err := os.Stat(cache.ValueFilepath("key")); !os.IsNotExist(err) {
+			t.Fatalf("Expected the value to be encoded in the entry, not a sidecar")
+		}
+	}
+
+	t.Run("TestEncrypted", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithEncryption(bytes.Repeat([]byte("k"), 32)))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		put(t, cache)
+	})
+
+	t.Run("TestCompressed", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithCompression(diskcache.Gzip))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		put(t, cache)
+	})
+}
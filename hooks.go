@@ -0,0 +1,49 @@
+package diskcache
+
+// WithOnEvict calls fn with the key, expiry, and metadata of each entry
+// removed from the cache by Remove, Flush, Clean, Prune, or eviction.
+// The data has no value, because the entry may be too large to read.
+// The hook is called after the entry is removed,
+// on the goroutine that removed it, so it must be safe for concurrent use.
+func WithOnEvict(fn func(Data)) Option {
+	return func(c *Cache) {
+		c.onEvict = fn
+	}
+}
+
+// WithOnExpire calls fn with the key, expiry, and metadata of each entry
+// removed from the cache because it expired, by Clean, the janitor, or Prune,
+// like WithOnEvict.
+// An entry removed because it expired is passed to both hooks.
+func WithOnExpire(fn func(Data)) Option {
+	return func(c *Cache) {
+		c.onExpire = fn
+	}
+}
+
+// hookData reads the data of a cache entry that is about to be removed,
+// without its value, if any hook will be called for it.
+// If the entry can't be read, the data has only the key.
+func (c Cache) hookData(filename string, key string, expired bool) Data {
+	if c.onEvict == nil && (!expired || c.onExpire == nil) {
+		return Data{}
+	}
+	rec, err := c.readHeader(filename)
+	if err != nil {
+		return Data{Key: key}
+	}
+	data := rec.Data
+	data.Value = nil
+	return data
+}
+
+// callHooks calls the removal hooks for a removed cache entry,
+// including the expiry hook if the entry was removed because it expired.
+func (c Cache) callHooks(data Data, expired bool) {
+	if c.onEvict != nil {
+		c.onEvict(data)
+	}
+	if expired && c.onExpire != nil {
+		c.onExpire(data)
+	}
+}
@@ -0,0 +1,101 @@
+package diskcache_test
+
+import (
+	"path"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestHooks(t *testing.T) {
+	var mu sync.Mutex
+	var evicted, expired []string
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir,
+		diskcache.WithMaxEntries(3),
+		diskcache.WithOnEvict(func(data diskcache.Data) {
+			mu.Lock()
+			defer mu.Unlock()
+			evicted = append(evicted, data.Key)
+		}),
+		diskcache.WithOnExpire(func(data diskcache.Data) {
+			mu.Lock()
+			defer mu.Unlock()
+			expired = append(expired, data.Key)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		evicted, expired = nil, nil
+	}
+
+	t.Run("TestRemove", func(t *testing.T) {
+		reset()
+		err := cache.SetWithMeta("removed", []byte("value"), map[string]string{"tmp": "/tmp/file"}, 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		var got diskcache.Data
+		hooked, err := diskcache.New(cacheDir, diskcache.WithOnEvict(func(data diskcache.Data) {
+			got = data
+		}))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = hooked.Remove("removed")
+		if err != nil {
+			t.Fatalf("Error removing cache: %v", err)
+		}
+		if got.Key != "removed" || got.Meta["tmp"] != "/tmp/file" {
+			t.Fatalf("Expected hook to get the removed entry, got %v", got)
+		}
+	})
+
+	t.Run("TestClean", func(t *testing.T) {
+		reset()
+		err := cache.Set("expired", []byte("value"), 1*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+		err = cache.Clean()
+		if err != nil {
+			t.Fatalf("Error cleaning cache: %v", err)
+		}
+		if !slices.Equal(evicted, []string{"expired"}) || !slices.Equal(expired, []string{"expired"}) {
+			t.Fatalf("Expected both hooks to get the expired entry, got %v and %v", evicted, expired)
+		}
+	})
+
+	t.Run("TestEvict", func(t *testing.T) {
+		reset()
+		for _, key := range []string{"a", "b", "c", "d"} {
+			err := cache.Set(key, []byte("value"), 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		if !slices.Equal(evicted, []string{"a"}) || expired != nil {
+			t.Fatalf("Expected only the evict hook to get a, got %v and %v", evicted, expired)
+		}
+	})
+
+	t.Run("TestFlush", func(t *testing.T) {
+		reset()
+		err := cache.Flush()
+		if err != nil {
+			t.Fatalf("Error flushing cache: %v", err)
+		}
+		slices.Sort(evicted)
+		if !slices.Equal(evicted, []string{"b", "c", "d"}) {
+			t.Fatalf("Expected the evict hook to get b, c, and d, got %v", evicted)
+		}
+	})
+}
@@ -0,0 +1,283 @@
+package diskcache
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// index is an in-memory index of cache entry metadata.
+// It maps keys to their expiry, size, and filename,
+// so metadata queries don't require disk reads.
+// The index assumes the cache is the only writer to its directory.
+// A nil index is valid and all its methods are no-ops.
+type index struct {
+	mu      sync.RWMutex
+	entries map[string]indexEntry
+	// manifest persists the index, if the cache has a manifest.
+	manifest *manifest
+}
+
+// indexEntry is the metadata of a cache entry held in the index.
+type indexEntry struct {
+	expiry   time.Time
+	created  time.Time
+	accessed time.Time
+	size     int64
+	filename string
+	// modified is when the entry file was last written, if the cache
+	// has a manifest, to check the manifest against the directory.
+	modified time.Time
+}
+
+// WithIndex enables the in-memory index.
+// The index is loaded from disk when the cache is created
+// and kept in sync on Set, Remove, Flush, and Clean.
+func WithIndex() Option {
+	return func(c *Cache) {
+		c.index = &index{entries: make(map[string]indexEntry)}
+	}
+}
+
+// load populates the index from the manifest, if any,
+// or from the entries on disk.
+func (idx *index) load(c Cache) error {
+	if idx.manifest != nil {
+		return idx.loadManifest(c)
+	}
+	list, err := c.listDir()
+	if err != nil {
+		return err
+	}
+	sizes, err := c.sizes()
+	if err != nil {
+		return err
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries = make(map[string]indexEntry, len(list))
+	for _, data := range list {
+		filename := c.Filename(data.Key)
+		idx.entries[data.Key] = indexEntry{
+			expiry:   data.Expiry,
+			created:  data.CreatedAt,
+			accessed: data.LastAccessed,
+			size:     sizes[filename],
+			filename: filename,
+		}
+	}
+	return nil
+}
+
+// get returns the index entry for a key.
+func (idx *index) get(key string) (indexEntry, bool) {
+	if idx == nil {
+		return indexEntry{}, false
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	entry, ok := idx.entries[key]
+	return entry, ok
+}
+
+// set adds or updates the index entry for the key of a cache entry.
+// It ignores the value and metadata of the entry.
+// The manifest, if any, is updated after the index is unlocked,
+// so readers of the index don't wait for its file.
+func (idx *index) set(data Data, size int64, filename string) {
+	if idx == nil {
+		return
+	}
+	entry := indexEntry{
+		expiry:   data.Expiry,
+		created:  data.CreatedAt,
+		accessed: data.LastAccessed,
+		size:     size,
+		filename: filename,
+		modified: idx.manifest.modified(filename),
+	}
+	idx.mu.Lock()
+	idx.entries[data.Key] = entry
+	idx.mu.Unlock()
+	idx.manifest.set(data.Key, entry)
+	idx.compactManifest()
+}
+
+// remove deletes the index entry for a key.
+func (idx *index) remove(key string) {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	_, ok := idx.entries[key]
+	delete(idx.entries, key)
+	idx.mu.Unlock()
+	if !ok {
+		return
+	}
+	idx.manifest.remove(key)
+	idx.compactManifest()
+}
+
+// removeFilename deletes the index entry whose file has a name, if any.
+func (idx *index) removeFilename(filename string) {
+	if idx == nil {
+		return
+	}
+	idx.mu.RLock()
+	var key string
+	var ok bool
+	for k, entry := range idx.entries {
+		if entry.filename == filename {
+			key, ok = k, true
+			break
+		}
+	}
+	idx.mu.RUnlock()
+	if ok {
+		idx.remove(key)
+	}
+}
+
+// compactManifest rewrites the manifest, if any, with one record
+// per index entry, if it holds more than twice as many records.
+// Records appended by writes racing with the compaction may be lost,
+// which loading the manifest detects from the entry files.
+func (idx *index) compactManifest() {
+	if !idx.manifest.needsCompaction(idx.len()) {
+		return
+	}
+	idx.manifest.rewrite(idx.snapshot())
+}
+
+// len returns the number of index entries.
+func (idx *index) len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.entries)
+}
+
+// snapshot returns a copy of the index entries.
+func (idx *index) snapshot() map[string]indexEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return maps.Clone(idx.entries)
+}
+
+// reset deletes all index entries.
+func (idx *index) reset() {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	idx.entries = make(map[string]indexEntry)
+	idx.mu.Unlock()
+	idx.manifest.rewrite(nil)
+}
+
+// filenames returns the filenames of all index entries.
+func (idx *index) filenames() []string {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	filenames := make([]string, 0, len(idx.entries))
+	for _, entry := range idx.entries {
+		filenames = append(filenames, entry.filename)
+	}
+	return filenames
+}
+
+// filenamesMatching returns the filenames of the index entries whose keys match.
+func (idx *index) filenamesMatching(match func(key string) bool) []string {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var filenames []string
+	for key, entry := range idx.entries {
+		if match(key) {
+			filenames = append(filenames, entry.filename)
+		}
+	}
+	return filenames
+}
+
+// list returns the keys and expiries of all index entries.
+// The returned data has no values.
+func (idx *index) list() []Data {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	list := make([]Data, 0, len(idx.entries))
+	for key, entry := range idx.entries {
+		list = append(list, Data{Key: key, Expiry: entry.expiry, CreatedAt: entry.created, LastAccessed: entry.accessed})
+	}
+	return list
+}
+
+// info returns the key, expiry, and size of all index entries.
+func (idx *index) info() []EntryInfo {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	infos := make([]EntryInfo, 0, len(idx.entries))
+	for key, entry := range idx.entries {
+		infos = append(infos, EntryInfo{Key: key, Expiry: entry.expiry, Size: entry.size})
+	}
+	return infos
+}
+
+// usage returns the number of index entries and their total size in bytes.
+func (idx *index) usage() (int64, int64, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var size int64
+	for _, entry := range idx.entries {
+		size += entry.size
+	}
+	return int64(len(idx.entries)), size, nil
+}
+
+// expiredEntries returns the expired index entries.
+func (idx *index) expiredEntries(now time.Time) []ExpiredEntry {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var entries []ExpiredEntry
+	for key, entry := range idx.entries {
+		if expired(entry.expiry, now) {
+			entries = append(entries, ExpiredEntry{
+				Key:        key,
+				Expiry:     entry.expiry,
+				Size:       entry.size,
+				ExpiredFor: now.Sub(entry.expiry),
+			})
+		}
+	}
+	return entries
+}
+
+// expired returns the keys of the expired index entries.
+func (idx *index) expired(now time.Time) []string {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var keys []string
+	for key, entry := range idx.entries {
+		if expired(entry.expiry, now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package diskcache_test
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestIndex(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+
+	// Populate the cache without an index.
+	plain, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = plain.Set("existing", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = plain.Set("stale", []byte("value"), -1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	cache, err := diskcache.New(cacheDir, diskcache.WithIndex())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestLoad", func(t *testing.T) {
+		if !cache.Has("existing") {
+			t.Fatalf("Expected key to exist")
+		}
+		if cache.Expiry("existing") != plain.Expiry("existing") {
+			t.Fatalf("Want expiry to be %s, got %s", plain.Expiry("existing"), cache.Expiry("existing"))
+		}
+		if !cache.IsExpired("stale") {
+			t.Fatalf("Expected cache to be expired")
+		}
+	})
+
+	t.Run("TestSetRemove", func(t *testing.T) {
+		key := "indexed"
+		err := cache.Set(key, []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		if !cache.Has(key) {
+			t.Fatalf("Expected key to exist")
+		}
+		if cache.IsExpired(key) {
+			t.Fatalf("Expected cache to not be expired")
+		}
+		err = cache.Remove(key)
+		if err != nil {
+			t.Fatalf("Error deleting cache: %v", err)
+		}
+		if cache.Has(key) {
+			t.Fatalf("Expected key to not exist")
+		}
+		if !cache.Expiry(key).IsZero() {
+			t.Fatalf("Expected cache expiry to be zero")
+		}
+	})
+
+	t.Run("TestList", func(t *testing.T) {
+		data, err := cache.List(diskcache.SortByKey)
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(data) != 2 {
+			t.Fatalf("Expected 2 keys, got %d", len(data))
+		}
+		if data[0].Key != "existing" {
+			t.Fatalf("Expected existing to be first, got %s", data[0].Key)
+		}
+	})
+
+	t.Run("TestClean", func(t *testing.T) {
+		err := cache.Clean()
+		if err != nil {
+			t.Fatalf("Error cleaning cache: %v", err)
+		}
+		if cache.Has("stale") {
+			t.Fatalf("Expected key to not exist")
+		}
+		if plain.Has("stale") {
+			t.Fatalf("Expected file to be removed")
+		}
+		if !cache.Has("existing") {
+			t.Fatalf("Expected key to exist")
+		}
+	})
+
+	t.Run("TestFlush", func(t *testing.T) {
+		err := cache.Flush()
+		if err != nil {
+			t.Fatalf("Error flushing cache: %v", err)
+		}
+		if cache.Has("existing") {
+			t.Fatalf("Expected key to not exist")
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package diskcache
+
+import "iter"
+
+// All returns an iterator over the cache entries, keyed by key.
+// It reads one entry at a time, so it doesn't hold the whole cache in memory.
+// Entries that can't be read, such as entries removed during the iteration,
+// are skipped.
+func (c Cache) All() iter.Seq2[string, Data] {
+	return func(yield func(string, Data) bool) {
+		filenames, err := c.filenames()
+		if err != nil {
+			return
+		}
+		for _, filename := range filenames {
+			entry, err := c.readFile(filename)
+			if err != nil {
+				continue
+			}
+			if !yield(entry.Key, entry) {
+				return
+			}
+		}
+	}
+}
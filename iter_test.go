@@ -0,0 +1,53 @@
+package diskcache_test
+
+import (
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestAll(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		err := cache.Set(fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+
+	t.Run("TestRange", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for key, data := range cache.All() {
+			if key != data.Key {
+				t.Fatalf("Expected key %s to match data key %s", key, data.Key)
+			}
+			if string(data.Value) != "value"+key[len("key"):] {
+				t.Fatalf("Unexpected value %s for key %s", data.Value, key)
+			}
+			seen[key] = true
+		}
+		if len(seen) != 10 {
+			t.Fatalf("Expected 10 keys, got %d", len(seen))
+		}
+	})
+
+	t.Run("TestBreak", func(t *testing.T) {
+		count := 0
+		for range cache.All() {
+			count++
+			if count == 3 {
+				break
+			}
+		}
+		if count != 3 {
+			t.Fatalf("Expected 3 iterations, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package diskcache
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// janitor periodically cleans expired entries from a cache.
+// A nil janitor is valid and stopping it is a no-op.
+type janitor struct {
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+// WithCleanupInterval starts a janitor that runs Clean on the given interval.
+// Call Close to stop it.
+// An interval of zero or less disables the janitor.
+func WithCleanupInterval(d time.Duration) Option {
+	return func(c *Cache) {
+		if d <= 0 {
+			c.janitor = nil
+			return
+		}
+		c.janitor = &janitor{
+			interval: d,
+			stop:     make(chan struct{}),
+			done:     make(chan struct{}),
+		}
+	}
+}
+
+// Close stops the janitor, if any, and waits for it to finish.
+// If the cache has a bloom filter, Close saves it for the next open.
+// It is safe to call Close more than once.
+func (c Cache) Close() error {
+	c.janitor.close()
+	return c.saveBloom()
+}
+
+// run cleans the cache on every tick until the janitor is stopped.
+// Errors are ignored because the next tick retries the clean.
+func (j *janitor) run(c Cache) {
+	defer close(j.done)
+	// Cancel a clean in progress when the janitor is stopped.
+	ctx, cancel := context.WithCancel(c.context())
+	defer cancel()
+	go func() {
+		select {
+		case <-j.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			err := c.CleanContext(ctx)
+			if err != nil && ctx.Err() == nil {
+				c.log(slog.LevelWarn, "error cleaning cache", "error", err)
+			}
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// close stops the janitor and waits for it to finish.
+func (j *janitor) close() {
+	if j == nil {
+		return
+	}
+	j.once.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+}
@@ -0,0 +1,58 @@
+package diskcache_test
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestJanitor(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithCleanupInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	defer cache.Close()
+
+	err = cache.Set("fresh", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = cache.Set("stale", []byte("value"), -1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	deadline := time.Now().Add(1 * time.Second)
+	for cache.Has("stale") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected janitor to remove expired entry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !cache.Has("fresh") {
+		t.Fatalf("Expected key to exist")
+	}
+
+	err = cache.Close()
+	if err != nil {
+		t.Fatalf("Error closing cache: %v", err)
+	}
+	// Closing twice is a no-op.
+	err = cache.Close()
+	if err != nil {
+		t.Fatalf("Error closing cache: %v", err)
+	}
+
+	// The janitor no longer runs after Close.
+	err = cache.Set("stale", []byte("value"), -1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !cache.Has("stale") {
+		t.Fatalf("Expected expired entry to remain after Close")
+	}
+}
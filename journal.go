@@ -0,0 +1,232 @@
+package diskcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// journalPrefix is the prefix of the names of journal blobs,
+// which are stored alongside the entries.
+const journalPrefix = "journal."
+
+// journalExt is the extension of the names of journal blobs.
+const journalExt = ".wal"
+
+// WithJournal records each transaction in a journal before applying it,
+// so a transaction interrupted by a crash or power loss is completed
+// when the cache is opened again, and a transaction whose journal
+// wasn't completely written is discarded, since none of it was applied.
+// Other writes replace a single entry file atomically,
+// so they don't need the journal.
+// The journal, and the entries it applies, are flushed to stable storage
+// before the journal is acted on, even without WithSync,
+// so the file system of the cache must implement SyncFS.
+// Recovery on open assumes no other process is committing a transaction
+// to the same directory.
+// A read-only cache can't have a journal.
+func WithJournal() Option {
+	return func(c *Cache) {
+		c.journal = true
+	}
+}
+
+// journalOp is an operation on a blob recorded in a journal.
+type journalOp struct {
+	Name string
+	// Contents is the contents to write to the blob.
+	Contents []byte `json:",omitempty"`
+	// Remove is true if the blob is removed instead.
+	Remove bool `json:",omitempty"`
+}
+
+// journalRecord is the contents of a journal blob.
+type journalRecord struct {
+	// Ops is the JSON encoding of the operations.
+	Ops json.RawMessage
+	// Checksum is the hex-encoded SHA-256 hash of Ops,
+	// to detect a journal that wasn't completely written.
+	Checksum string
+}
+
+// writeJournal records operations in a new journal blob
+// and returns its name.
+func (c Cache) writeJournal(ops []journalOp) (string, error) {
+	encoded, err := json.Marshal(ops)
+	if err != nil {
+		return "", err
+	}
+	contents, err := json.Marshal(journalRecord{Ops: encoded, Checksum: checksum(encoded)})
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s%016x%s", journalPrefix, rand.Uint64(), journalExt)
+	_, err = c.store.WriteEntry(name, bytes.NewReader(contents))
+	if err == nil {
+		err = c.syncBlobs([]string{name})
+	}
+	if err != nil {
+		return "", fmt.Errorf("error writing journal: %w", err)
+	}
+	return name, nil
+}
+
+// syncBlobs flushes the named blobs of a directory store,
+// and the directories holding them, to stable storage.
+// Blobs that were removed only have their directories flushed.
+// Other stores are left to flush their own writes.
+func (c Cache) syncBlobs(names []string) error {
+	s, err := c.dirStore()
+	if err != nil {
+		return nil
+	}
+	fsys := s.fsys.(SyncFS)
+	dirs := make(map[string]bool)
+	for _, name := range names {
+		err := fsys.Sync(filepath.Join(s.dir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		dirs[filepath.Dir(name)] = true
+	}
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		err := fsys.Sync(filepath.Join(s.dir, dir))
+		if err == nil {
+			err = s.syncParents(dir)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// journalNames returns the names of the blobs changed by
+// the operations of a journal.
+func journalNames(ops []journalOp) []string {
+	names := make([]string, len(ops))
+	for i, op := range ops {
+		names[i] = op.Name
+	}
+	return names
+}
+
+// removeJournal removes a journal blob, if the name isn't empty.
+func (c Cache) removeJournal(name string) error {
+	if name == "" {
+		return nil
+	}
+	err := c.store.RemoveEntry(name)
+	if err != nil {
+		return fmt.Errorf("error removing journal: %w", err)
+	}
+	return nil
+}
+
+// finishJournal flushes the blobs changed by a committed transaction
+// and removes its journal.
+// If they can't be flushed, the journal is kept,
+// so the transaction is applied again on open.
+func (c Cache) finishJournal(name string, ops []journalOp) {
+	err := c.syncBlobs(journalNames(ops))
+	if err != nil {
+		c.log(slog.LevelWarn, "error syncing transaction", "name", name, "error", err)
+		return
+	}
+	err = c.removeJournal(name)
+	if err != nil {
+		c.log(slog.LevelWarn, "error removing journal", "name", name, "error", err)
+	}
+}
+
+// journalOps returns the blob operations of a transaction,
+// including removing the sidecars of replaced entries.
+func (c Cache) journalOps(ops []txOp, undos []txUndo) []journalOp {
+	var jops []journalOp
+	for i, op := range ops {
+		filename := undos[i].filename
+		if op.contents != nil {
+			jops = append(jops, journalOp{Name: filename, Contents: op.contents})
+		} else {
+			jops = append(jops, journalOp{Name: filename, Remove: true})
+		}
+		jops = append(jops, journalOp{Name: c.sidecar(filename), Remove: true})
+	}
+	return jops
+}
+
+// isJournal returns true if the name of a blob in the store
+// is the name of a journal.
+func isJournal(name string) bool {
+	base := filepath.Base(name)
+	return name == base && strings.HasPrefix(base, journalPrefix) && filepath.Ext(base) == journalExt
+}
+
+// recoverJournals completes the transactions recorded in complete journals,
+// discards incomplete journals, and removes them.
+func (c Cache) recoverJournals() error {
+	entries, err := c.store.ListEntries()
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !isJournal(entry.Name) {
+			continue
+		}
+		contents, err := c.readBlob(entry.Name)
+		if err != nil {
+			return fmt.Errorf("error reading journal: %w", err)
+		}
+		var rec journalRecord
+		var ops []journalOp
+		err = json.Unmarshal(contents, &rec)
+		if err == nil && checksum(rec.Ops) == rec.Checksum {
+			err = json.Unmarshal(rec.Ops, &ops)
+		} else {
+			err = errors.New("journal is incomplete")
+		}
+		if err != nil {
+			c.log(slog.LevelWarn, "discarding journal", "name", entry.Name, "error", err)
+		} else {
+			err = c.replay(ops)
+			if err == nil {
+				err = c.syncBlobs(journalNames(ops))
+			}
+			if err != nil {
+				return fmt.Errorf("error replaying journal %s: %w", entry.Name, err)
+			}
+			c.log(slog.LevelInfo, "replayed journal", "name", entry.Name, "operations", len(ops))
+		}
+		err = c.store.RemoveEntry(entry.Name)
+		if err != nil {
+			return fmt.Errorf("error removing journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// replay applies the operations recorded in a journal.
+// The operations set blobs to their final contents,
+// so replaying them again has no further effect.
+func (c Cache) replay(ops []journalOp) error {
+	for _, op := range ops {
+		var err error
+		if op.Remove {
+			err = c.removeEntryIfExists(op.Name)
+		} else {
+			_, err = c.store.WriteEntry(op.Name, bytes.NewReader(op.Contents))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package diskcache_test
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// assertNoJournals fails the test if a cache directory holds journals.
+func assertNoJournals(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "journal.*"))
+	if err != nil {
+		t.Fatalf("Error listing journals: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("Expected no journals, got %v", matches)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	t.Run("TestCommit", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithJournal())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Tx(func(tx *diskcache.Txn) error {
+			tx.Set("a", []byte("a"), time.Minute)
+			return tx.Set("b", []byte("b"), time.Minute)
+		})
+		if err != nil {
+			t.Fatalf("Error committing transaction: %v", err)
+		}
+		assertNoJournals(t, cacheDir)
+		keys, err := cache.Keys()
+		if err != nil || len(keys) != 2 {
+			t.Fatalf("Expected 2 keys, got %v, %v", keys, err)
+		}
+	})
+
+	t.Run("TestReplay", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		fsys := &faultFS{}
+		cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys), diskcache.WithJournal())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"a", "b", "c"} {
+			err = cache.Set(key, []byte("old "+key), time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		// Crash while committing: once b is written,
+		// the file system stops accepting changes.
+		crashed := false
+		fsys.fail = func(op string, name string) error {
+			if crashed && (op == "rename" || op == "remove") {
+				return syscall.EIO
+			}
+			if op == "rename" && name == cache.Filepath("b") {
+				crashed = true
+			}
+			return nil
+		}
+		err = cache.Tx(func(tx *diskcache.Txn) error {
+			tx.Set("a", []byte("new a"), time.Minute)
+			tx.Set("b", []byte("new b"), time.Minute)
+			tx.Set("c", []byte("new c"), time.Minute)
+			tx.Remove("old")
+			return nil
+		})
+		if !errors.Is(err, syscall.EIO) {
+			t.Fatalf("Expected EIO, got %v", err)
+		}
+		fsys.fail = nil
+		value, _ := cache.Get("c")
+		if string(value) != "old c" {
+			t.Fatalf("Expected c to be unchanged before recovery, got %q", value)
+		}
+
+		reopened, err := diskcache.New(cacheDir, diskcache.WithJournal())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"a", "b", "c"} {
+			value, err := reopened.Get(key)
+			if err != nil || string(value) != "new "+key {
+				t.Fatalf("Expected %s to be replayed, got %q, %v", key, value, err)
+			}
+		}
+		assertNoJournals(t, cacheDir)
+	})
+
+	t.Run("TestSync", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		var ops []string
+		fsys := &faultFS{fail: func(op string, name string) error {
+			if strings.HasPrefix(filepath.Base(name), "journal.") {
+				name = "journal"
+			}
+			ops = append(ops, op+" "+name)
+			return nil
+		}}
+		cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys), diskcache.WithJournal())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		ops = nil
+		err = cache.Tx(func(tx *diskcache.Txn) error {
+			return tx.Set("key", []byte("value"), time.Minute)
+		})
+		if err != nil {
+			t.Fatalf("Error committing transaction: %v", err)
+		}
+		// The journal is flushed before the entry is written,
+		// and the entry before the journal is removed.
+		order := []string{
+			"sync journal",
+			"sync " + cacheDir,
+			"rename " + cache.Filepath("key"),
+			"sync " + cache.Filepath("key"),
+			"sync " + cacheDir,
+			"remove journal",
+		}
+		i := 0
+		for _, op := range ops {
+			if i < len(order) && op == order[i] {
+				i++
+			}
+		}
+		if i != len(order) {
+			t.Fatalf("Expected %v in order, got %v", order, ops)
+		}
+	})
+
+	t.Run("TestIncompleteJournal", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		journal := filepath.Join(cacheDir, "journal.0000000000000001.wal")
+		err = os.WriteFile(journal, []byte(`{"Ops":[{"Name":"`), 0o644)
+		if err != nil {
+			t.Fatalf("Error writing journal: %v", err)
+		}
+		// Without a journal, the cache ignores journals.
+		keys, err := cache.Keys()
+		if err != nil || len(keys) != 1 {
+			t.Fatalf("Expected 1 key, got %v, %v", keys, err)
+		}
+		reopened, err := diskcache.New(cacheDir, diskcache.WithJournal())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		assertNoJournals(t, cacheDir)
+		value, err := reopened.Get("key")
+		if err != nil || string(value) != "value" {
+			t.Fatalf("Expected key to be unchanged, got %q, %v", value, err)
+		}
+	})
+
+	t.Run("TestReadOnly", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		_, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		_, err = diskcache.New(cacheDir, diskcache.WithReadOnly(), diskcache.WithJournal())
+		if err == nil {
+			t.Fatalf("Expected error for read-only cache with a journal")
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package diskcache
+
+import (
+	"time"
+)
+
+// SetJSON saves a cache entry whose value is the JSON encoding of v,
+// with a key and duration.
+// It uses JSONCodec regardless of the codec of the cache;
+// use SetValue for the codec of the cache.
+func (c Cache) SetJSON(key string, v any, duration time.Duration) error {
+	return c.setEncoded(JSONCodec, key, v, duration)
+}
+
+// GetJSON gets a cache entry and decodes its value as JSON into out,
+// which must be a pointer.
+// It returns an error if the entry is expired, like Get.
+func (c Cache) GetJSON(key string, out any) error {
+	return c.getDecoded(JSONCodec, key, out)
+}
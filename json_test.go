@@ -0,0 +1,82 @@
+package diskcache_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestJSON(t *testing.T) {
+	type user struct {
+		Name  string
+		Roles []string
+	}
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestRoundTrip", func(t *testing.T) {
+		err := cache.SetJSON("user", user{Name: "alice", Roles: []string{"admin"}}, time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		value, err := cache.Get("user")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(value) != `{"Name":"alice","Roles":["admin"]}` {
+			t.Fatalf("Expected JSON value, got %s", value)
+		}
+		var got user
+		err = cache.GetJSON("user", &got)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if got.Name != "alice" || len(got.Roles) != 1 || got.Roles[0] != "admin" {
+			t.Fatalf("Expected alice, got %+v", got)
+		}
+	})
+
+	t.Run("TestMarshalError", func(t *testing.T) {
+		err := cache.SetJSON("func", func() {}, time.Hour)
+		if err == nil {
+			t.Fatalf("Expected error marshaling a function")
+		}
+		if cache.Has("func") {
+			t.Fatalf("Expected no entry to be saved")
+		}
+	})
+
+	t.Run("TestUnmarshalError", func(t *testing.T) {
+		err := cache.Set("text", []byte("not json"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		var got user
+		err = cache.GetJSON("text", &got)
+		if err == nil {
+			t.Fatalf("Expected error unmarshaling invalid JSON")
+		}
+	})
+
+	t.Run("TestMissingAndExpired", func(t *testing.T) {
+		var got user
+		err := cache.GetJSON("missing", &got)
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected fs.ErrNotExist, got %v", err)
+		}
+		err = cache.SetJSON("expired", user{Name: "bob"}, -time.Second)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.GetJSON("expired", &got)
+		if !errors.Is(err, diskcache.ErrExpired) {
+			t.Fatalf("Expected ErrExpired, got %v", err)
+		}
+	})
+}
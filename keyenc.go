@@ -0,0 +1,54 @@
+package diskcache
+
+import (
+	"encoding/base32"
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// WithKeyEncoding names entry files with the lowercase base32 encoding of their keys
+// instead of a hash, so filenames can be mapped back to keys without opening files.
+// The encoding uses only lowercase letters and digits, so keys that differ
+// only in case get distinct files on case-insensitive file systems.
+// Because most file systems limit filenames to 255 bytes,
+// keys longer than about 150 bytes can't be saved.
+func WithKeyEncoding() Option {
+	return func(c *Cache) {
+		c.filenameFunc = encodeKey
+		c.keyFunc = decodeKey
+	}
+}
+
+// keyEncoding is the base32 encoding of keys in filenames,
+// with the extended hex alphabet so filenames sort like their keys.
+var keyEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// encodeKey returns the lowercase base32 encoding of a key.
+func encodeKey(key string) string {
+	return strings.ToLower(keyEncoding.EncodeToString([]byte(key)))
+}
+
+// decodeKey returns the key encoded in a filename, without the extension.
+func decodeKey(name string) (string, error) {
+	if name != strings.ToLower(name) {
+		return "", errors.New("filename isn't lowercase")
+	}
+	key, err := keyEncoding.DecodeString(strings.ToUpper(name))
+	return string(key), err
+}
+
+// keyOf returns the key of an entry file from its filename
+// if the cache encodes keys in filenames.
+// It returns false if the cache hashes keys or the filename isn't an encoded key.
+func (c Cache) keyOf(filename string) (string, bool) {
+	if c.keyFunc == nil {
+		return "", false
+	}
+	name := strings.TrimSuffix(filepath.Base(filename), c.ext)
+	key, err := c.keyFunc(name)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
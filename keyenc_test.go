@@ -0,0 +1,61 @@
+package diskcache_test
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestKeyEncoding(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithKeyEncoding())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "api:/users?id=1"
+
+	t.Run("TestFilename", func(t *testing.T) {
+		want := "c5o6iehfelpmasjj7tkm8f9h.json"
+		if got := cache.Filename(key); got != want {
+			t.Fatalf("Want filename to be %s, got %s", want, got)
+		}
+		if got := cache.Filename("other"); got == want {
+			t.Fatalf("Expected distinct filenames for distinct keys")
+		}
+		if strings.EqualFold(cache.Filename("Key"), cache.Filename("kEY")) {
+			t.Fatalf("Expected keys differing in case to have filenames differing beyond case")
+		}
+		err := cache.Set(key, []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", string(got))
+		}
+	})
+
+	t.Run("TestWatchRemove", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events, err := cache.Watch(ctx)
+		if err != nil {
+			t.Fatalf("Error watching cache: %v", err)
+		}
+		err = cache.Remove(key)
+		if err != nil {
+			t.Fatalf("Error deleting cache: %v", err)
+		}
+		waitForEvent(t, events, diskcache.EventRemove, key)
+		cancel()
+		for range events {
+		}
+	})
+}
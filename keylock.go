@@ -0,0 +1,63 @@
+package diskcache
+
+import (
+	"slices"
+	"sync"
+)
+
+// keyLocks holds a mutex for each key being locked,
+// shared by all copies of a cache.
+// A nil keyLocks is valid and locking it is a no-op.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+// keyLock is the mutex of a key and the number of callers holding
+// or waiting for it, so it can be deleted when unused.
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks a key and returns a function to unlock it.
+func (l *keyLocks) lock(key string) func() {
+	if l == nil {
+		return func() {}
+	}
+	l.mu.Lock()
+	kl, ok := l.locks[key]
+	if !ok {
+		kl = &keyLock{}
+		l.locks[key] = kl
+	}
+	kl.refs++
+	l.mu.Unlock()
+	kl.mu.Lock()
+	return func() {
+		kl.mu.Unlock()
+		l.mu.Lock()
+		kl.refs--
+		if kl.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// lockAll locks several keys in sorted order, so callers locking
+// overlapping keys can't deadlock, and returns a function to unlock them.
+func (l *keyLocks) lockAll(keys ...string) func() {
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	unlocks := make([]func(), 0, len(keys))
+	for _, key := range keys {
+		unlocks = append(unlocks, l.lock(key))
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package diskcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Keys returns the sorted keys of all cache entries, including expired ones.
+// It reads the keys from the index, if any, or from the filenames,
+// if the cache encodes keys in filenames.
+// Otherwise it reads the entry files without decoding their values,
+// unless they are encrypted.
+func (c Cache) Keys() ([]string, error) {
+	var keys []string
+	if c.index != nil {
+		for _, data := range c.index.list() {
+			keys = append(keys, data.Key)
+		}
+		slices.Sort(keys)
+		return keys, nil
+	}
+	filenames, err := c.readDir()
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	for _, filename := range filenames {
+		if key, ok := c.keyOf(filename); ok {
+			keys = append(keys, key)
+			continue
+		}
+		data, err := c.readMeta(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error reading entry: %w", err)
+		}
+		keys = append(keys, data.Key)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// EntryInfo is the key, expiry, and size of a cache entry.
+type EntryInfo struct {
+	Key    string
+	Expiry time.Time
+	// Size is the size in bytes of the entry, including its sidecar, if any.
+	Size int64
+}
+
+// ListInfo returns the key, expiry, and size of each cache entry,
+// including expired ones, sorted by key.
+// It reads them from the index, if any,
+// or from the entry files without decoding their values,
+// unless they are encrypted, so it is much faster than List
+// for large values.
+func (c Cache) ListInfo() ([]EntryInfo, error) {
+	var infos []EntryInfo
+	if c.index != nil {
+		infos = c.index.info()
+	} else {
+		filenames, err := c.readDir()
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory: %w", err)
+		}
+		sizes, err := c.sizes()
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory: %w", err)
+		}
+		for _, filename := range filenames {
+			data, err := c.readMeta(filename)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading entry: %w", err)
+			}
+			infos = append(infos, EntryInfo{Key: data.Key, Expiry: data.Expiry, Size: sizes[filename]})
+		}
+	}
+	slices.SortFunc(infos, func(a, b EntryInfo) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return infos, nil
+}
+
+// Count returns the number of cache entries and how many of them are expired.
+// It reads the expiries from the index, if any,
+// or from the entry files without decoding their values.
+func (c Cache) Count() (int, int, error) {
+	now := c.now()
+	if c.index != nil {
+		list := c.index.list()
+		return len(list), len(c.index.expired(now)), nil
+	}
+	filenames, err := c.readDir()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading directory: %w", err)
+	}
+	var total, expiredTotal int
+	for _, filename := range filenames {
+		data, err := c.readMeta(filename)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error reading entry: %w", err)
+		}
+		total++
+		if expired(data.Expiry, now) {
+			expiredTotal++
+		}
+	}
+	return total, expiredTotal, nil
+}
+
+// metaHeader is the part of a JSON entry file decoded by readMeta.
+type metaHeader struct {
+	Key          string
+	Expiry       time.Time
+	CreatedAt    time.Time
+	LastAccessed time.Time
+	Sealed       []byte
+	Version      int
+}
+
+// readMeta reads the key, expiry, creation time, and last access time
+// of a cache entry.
+// It doesn't decode the value or read the sidecar,
+// unless the entry is encrypted and the value holds the key.
+// The returned data has no value or metadata.
+func (c Cache) readMeta(filename string) (Data, error) {
+	contents, err := c.readEntry(filename)
+	if err != nil {
+		return Data{}, fmt.Errorf("error reading data: %w", err)
+	}
+	c.stats.read(len(contents))
+	if formatOf(contents) == JSON {
+		var header metaHeader
+		err := json.Unmarshal(contents, &header)
+		if err != nil {
+			return Data{}, fmt.Errorf("error unmarshaling data: %w", err)
+		}
+		if header.Sealed == nil && header.Version <= schemaVersion {
+			return Data{Key: header.Key, Expiry: header.Expiry, CreatedAt: header.CreatedAt, LastAccessed: header.LastAccessed}, nil
+		}
+	}
+	rec, err := unmarshal(contents)
+	if err != nil {
+		return Data{}, fmt.Errorf("error unmarshaling data: %w", err)
+	}
+	rec, err = upgrade(rec)
+	if err != nil {
+		return Data{}, err
+	}
+	if rec.Sealed != nil {
+		rec, err = c.decrypt(rec)
+		if err != nil {
+			return Data{}, fmt.Errorf("error decrypting entry: %w", err)
+		}
+	}
+	return Data{Key: rec.Key, Expiry: rec.Expiry, CreatedAt: rec.CreatedAt, LastAccessed: rec.LastAccessed}, nil
+}
@@ -0,0 +1,122 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestKeys(t *testing.T) {
+	secret := bytes.Repeat([]byte("k"), 32)
+	for name, options := range map[string][]diskcache.Option{
+		"TestJSON":        nil,
+		"TestBinary":      {diskcache.WithFormat(diskcache.Binary)},
+		"TestIndexed":     {diskcache.WithIndex()},
+		"TestKeyEncoding": {diskcache.WithKeyEncoding()},
+		"TestEncrypted":   {diskcache.WithEncryption(secret)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			want := []string{"a", "b", "c"}
+			for _, key := range []string{"c", "a", "b"} {
+				err := cache.Set(key, []byte("value"), 1*time.Minute)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+			got, err := cache.Keys()
+			if err != nil {
+				t.Fatalf("Error listing keys: %v", err)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("Expected keys %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	for name, options := range map[string][]diskcache.Option{
+		"TestUnindexed": nil,
+		"TestIndexed":   {diskcache.WithIndex()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			for key, duration := range map[string]time.Duration{
+				"expired": 1 * time.Millisecond,
+				"fresh":   1 * time.Minute,
+				"forever": diskcache.NoExpiry,
+			} {
+				err := cache.Set(key, []byte("value"), duration)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			total, expired, err := cache.Count()
+			if err != nil {
+				t.Fatalf("Error counting cache: %v", err)
+			}
+			if total != 3 || expired != 1 {
+				t.Fatalf("Expected 3 entries with 1 expired, got %d with %d expired", total, expired)
+			}
+		})
+	}
+}
+
+func TestListInfo(t *testing.T) {
+	for name, options := range map[string][]diskcache.Option{
+		"TestJSON":      nil,
+		"TestBinary":    {diskcache.WithFormat(diskcache.Binary)},
+		"TestIndexed":   {diskcache.WithIndex()},
+		"TestRawValues": {diskcache.WithRawValues()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			err = cache.Set("large", bytes.Repeat([]byte("x"), 1<<20), 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			err = cache.Set("small", []byte("value"), diskcache.NoExpiry)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			infos, err := cache.ListInfo()
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			if len(infos) != 2 || infos[0].Key != "large" || infos[1].Key != "small" {
+				t.Fatalf("Expected large and small, got %v", infos)
+			}
+			if infos[0].Size < 1<<20 {
+				t.Fatalf("Expected size of at least 1 MiB, got %d", infos[0].Size)
+			}
+			if infos[0].Expiry.IsZero() || !infos[1].Expiry.IsZero() {
+				t.Fatalf("Expected expiry for large only, got %v", infos)
+			}
+			size, err := cache.Size()
+			if err != nil {
+				t.Fatalf("Error getting size: %v", err)
+			}
+			if infos[0].Size+infos[1].Size != size {
+				t.Fatalf("Expected sizes to add up to %d, got %d", size, infos[0].Size+infos[1].Size)
+			}
+		})
+	}
+}
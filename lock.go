@@ -0,0 +1,135 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// lockExt is the extension of lock files,
+// which are named after the entry files of their keys.
+const lockExt = ".lock"
+
+// Lock locks a key across processes sharing the cache directory,
+// such as to let only one process refresh an expired entry,
+// and returns a function to unlock it.
+// It blocks until the key is unlocked by any other holder,
+// including another call in the same process.
+// The lock is advisory: Set and the other methods don't take it.
+// It is held on a lock file next to the entry file,
+// which is removed when the key is unlocked,
+// and is released by the operating system if the process exits.
+// Locking requires a cache in a directory on the operating system's
+// file system, and isn't supported on read-only caches.
+func (c Cache) Lock(key string) (func(), error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+	if c.readOnly {
+		return nil, errReadOnly("lock", c.dir)
+	}
+	if !c.lockable() {
+		return nil, errors.New("locking requires a cache directory on the operating system's file system")
+	}
+	name := c.filepath(c.Filename(key)) + lockExt
+	err := os.MkdirAll(filepath.Dir(name), c.dirMode)
+	if err != nil {
+		return nil, fmt.Errorf("error creating lock directory: %w", err)
+	}
+	for {
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, c.fileMode)
+		if err != nil {
+			return nil, fmt.Errorf("error opening lock file: %w", err)
+		}
+		err = lockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error locking key %q: %w", key, err)
+		}
+		// The previous holder may have removed the lock file
+		// after it was opened, in which case another caller
+		// can lock a new one, so lock it instead.
+		locked, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error locking key %q: %w", key, err)
+		}
+		current, err := os.Stat(name)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			f.Close()
+			return nil, fmt.Errorf("error locking key %q: %w", key, err)
+		}
+		if err != nil || !os.SameFile(locked, current) {
+			f.Close()
+			continue
+		}
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				// Remove the lock file before closing it releases the lock,
+				// so no other caller locks a removed file and keeps it.
+				// Removing an open file may fail on some systems,
+				// in which case the lock file is reused.
+				os.Remove(name)
+				f.Close()
+			})
+		}, nil
+	}
+}
+
+// lockable returns true if the cache supports Lock.
+func (c Cache) lockable() bool {
+	_, err := c.dirStore()
+	_, ok := c.fsys.(osFS)
+	return err == nil && ok && !c.readOnly
+}
+
+// lockEntry locks a key for a read-modify-write, such as CAS,
+// within the process and, if the cache supports it, across processes
+// with Lock, and returns a function to unlock it.
+// Holding Lock on the key in the same process would deadlock.
+func (c Cache) lockEntry(key string) (func(), error) {
+	unlock := c.locks.lock(key)
+	if !c.lockable() {
+		return unlock, nil
+	}
+	unlockFile, err := c.Lock(key)
+	if errors.Is(err, errors.ErrUnsupported) {
+		return unlock, nil
+	}
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	return func() {
+		unlockFile()
+		unlock()
+	}, nil
+}
+
+// lockEntries locks several keys like lockEntry, in sorted order,
+// so callers locking overlapping keys can't deadlock,
+// and returns a function to unlock them.
+func (c Cache) lockEntries(keys ...string) (func(), error) {
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	unlocks := make([]func(), 0, len(keys))
+	unlockAll := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for _, key := range keys {
+		unlock, err := c.lockEntry(key)
+		if err != nil {
+			unlockAll()
+			return nil, err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+	return unlockAll, nil
+}
@@ -0,0 +1,14 @@
+//go:build !unix && !windows
+
+package diskcache
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile returns an error, since locking files isn't supported
+// on this operating system.
+func lockFile(f *os.File) error {
+	return errors.ErrUnsupported
+}
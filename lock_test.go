@@ -0,0 +1,147 @@
+package diskcache_test
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestLock(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithSharding())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestExclusive", func(t *testing.T) {
+		unlock, err := cache.Lock("key")
+		if err != nil {
+			t.Fatalf("Error locking key: %v", err)
+		}
+		locked := make(chan struct{})
+		go func() {
+			unlock, err := cache.Lock("key")
+			if err != nil {
+				t.Errorf("Error locking key: %v", err)
+				close(locked)
+				return
+			}
+			close(locked)
+			unlock()
+		}()
+		select {
+		case <-locked:
+			t.Fatalf("Expected second Lock to block")
+		case <-time.After(50 * time.Millisecond):
+		}
+		unlock()
+		select {
+		case <-locked:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected second Lock to succeed after unlock")
+		}
+		// Unlocking twice is a no-op.
+		unlock()
+	})
+
+	t.Run("TestOtherKeys", func(t *testing.T) {
+		unlock, err := cache.Lock("a")
+		if err != nil {
+			t.Fatalf("Error locking key: %v", err)
+		}
+		defer unlock()
+		unlockB, err := cache.Lock("b")
+		if err != nil {
+			t.Fatalf("Error locking key: %v", err)
+		}
+		unlockB()
+	})
+
+	t.Run("TestMutualExclusion", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var holders, maxHolders int
+		var mu sync.Mutex
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 20 {
+					unlock, err := cache.Lock("counter")
+					if err != nil {
+						t.Errorf("Error locking key: %v", err)
+						return
+					}
+					mu.Lock()
+					holders++
+					maxHolders = max(maxHolders, holders)
+					mu.Unlock()
+					time.Sleep(100 * time.Microsecond)
+					mu.Lock()
+					holders--
+					mu.Unlock()
+					unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if maxHolders != 1 {
+			t.Fatalf("Expected one holder at a time, got %d", maxHolders)
+		}
+	})
+
+	t.Run("TestLockFiles", func(t *testing.T) {
+		err := cache.Set("key", []byte("value"), time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		unlock, err := cache.Lock("key")
+		if err != nil {
+			t.Fatalf("Error locking key: %v", err)
+		}
+		lockFile := cache.Filepath("key") + ".lock"
+		if _, err := os.Stat(lockFile); err != nil {
+			t.Fatalf("Expected lock file next to the entry file: %v", err)
+		}
+		keys, err := cache.Keys()
+		if err != nil || len(keys) != 1 {
+			t.Fatalf("Expected lock file not to be listed, got %v, %v", keys, err)
+		}
+		unlock()
+		if _, err := os.Stat(lockFile); !os.IsNotExist(err) {
+			t.Fatalf("Expected lock file to be removed, got %v", err)
+		}
+		matches, _ := filepath.Glob(filepath.Join(cacheDir, "*", "*", "*.lock"))
+		if len(matches) != 0 {
+			t.Fatalf("Expected no lock files, got %v", matches)
+		}
+	})
+
+	t.Run("TestUnsupported", func(t *testing.T) {
+		_, err := cache.Lock("")
+		if err == nil {
+			t.Fatalf("Expected error for empty key")
+		}
+		readOnly, err := diskcache.New(cacheDir, diskcache.WithReadOnly())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		_, err = readOnly.Lock("key")
+		if err == nil {
+			t.Fatalf("Expected error locking a read-only cache")
+		}
+		fromFS, err := diskcache.NewFromFS(fstest.MapFS{})
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		_, err = fromFS.Lock("key")
+		if err == nil {
+			t.Fatalf("Expected error locking a cache without a directory")
+		}
+	})
+}
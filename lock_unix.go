@@ -0,0 +1,19 @@
+//go:build unix
+
+package diskcache
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile locks an open file exclusively, blocking until it is unlocked.
+// Closing the file unlocks it.
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package diskcache
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile locks an open file exclusively, blocking until it is unlocked.
+// Closing the file unlocks it.
+func lockFile(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
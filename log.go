@@ -0,0 +1,22 @@
+package diskcache
+
+import "log/slog"
+
+// WithLogger logs cache activity to a structured logger:
+// writes, reads, and removals at debug level, with the reason for removals,
+// and errors that aren't returned to a caller, such as the janitor's,
+// or that indicate a damaged entry, at warn level.
+// Without a logger, the cache doesn't log.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Cache) {
+		c.logger = logger
+	}
+}
+
+// log logs a message at a level, if the cache has a logger.
+func (c Cache) log(level slog.Level, msg string, args ...any) {
+	if c.logger == nil {
+		return
+	}
+	c.logger.Log(c.context(), level, msg, args...)
+}
@@ -0,0 +1,67 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// syncBuffer is a buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogger(t *testing.T) {
+	var buf syncBuffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.Set("logged", []byte("value"), 1*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	_, err = cache.Get("logged")
+	if err != nil {
+		t.Fatalf("Error getting cache: %v", err)
+	}
+	_, _ = cache.Get("missing")
+	time.Sleep(2 * time.Millisecond)
+	err = cache.Clean()
+	if err != nil {
+		t.Fatalf("Error cleaning cache: %v", err)
+	}
+
+	logs := buf.String()
+	for _, want := range []string{
+		`msg="wrote entry" key=logged`,
+		`msg="cache hit" key=logged`,
+		`msg="cache miss" key=missing`,
+		`msg="removed entry" key=logged reason=expired`,
+	} {
+		if !strings.Contains(logs, want) {
+			t.Fatalf("Expected logs to contain %s, got %s", want, logs)
+		}
+	}
+}
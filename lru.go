@@ -0,0 +1,70 @@
+package diskcache
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RemovalOrder is the order in which Prune and eviction remove
+// entries that aren't expired.
+type RemovalOrder int
+
+const (
+	// RemoveDefault removes the oldest entries first when pruning
+	// and the soonest-to-expire entries first when evicting.
+	RemoveDefault RemovalOrder = iota
+	// RemoveLeastRecentlyUsed removes the least recently read entries
+	// first, so entries read often survive.
+	// An entry that hasn't been read since it was written counts as
+	// read when it was written.
+	// It needs WithAccessTracking to know when entries were read;
+	// without it, entries are removed oldest first.
+	RemoveLeastRecentlyUsed
+)
+
+// String returns the name of the removal order.
+func (o RemovalOrder) String() string {
+	switch o {
+	case RemoveDefault:
+		return "default"
+	case RemoveLeastRecentlyUsed:
+		return "lru"
+	default:
+		return fmt.Sprintf("RemovalOrder(%d)", int(o))
+	}
+}
+
+// WithEvictionOrder sets the order in which entries are evicted
+// when Set exceeds the cap of WithMaxEntries.
+func WithEvictionOrder(order RemovalOrder) Option {
+	return func(c *Cache) {
+		if order != RemoveDefault && order != RemoveLeastRecentlyUsed {
+			c.err = fmt.Errorf("invalid eviction order %v", order)
+			return
+		}
+		c.evictOrder = order
+	}
+}
+
+// lastUsed returns when an entry was last read,
+// or when it was written if it hasn't been read since.
+// It returns the zero time if neither is known.
+func lastUsed(accessed, created time.Time) time.Time {
+	if accessed.IsZero() {
+		return created
+	}
+	return accessed
+}
+
+// SortByLastUsed is a sort function to sort cache entries
+// least recently used first, as RemoveLeastRecentlyUsed removes them.
+// An entry that hasn't been read since it was written counts as
+// read when it was written.
+// The sort is stable, so it can follow another sort function.
+func SortByLastUsed(entries []Data) []Data {
+	slices.SortStableFunc(entries, func(a, b Data) int {
+		return lastUsed(a.LastAccessed, a.CreatedAt).Compare(lastUsed(b.LastAccessed, b.CreatedAt))
+	})
+	return entries
+}
@@ -0,0 +1,121 @@
+package diskcache_test
+
+import (
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestLeastRecentlyUsed(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// newCache returns a cache with entries a, b, c, and d written in order,
+	// of which a and c were read since, c last.
+	newCache := func(t *testing.T, options ...diskcache.Option) diskcache.Cache {
+		t.Helper()
+		clock := &fakeClock{now: start}
+		options = append([]diskcache.Option{diskcache.WithClock(clock), diskcache.WithAccessTracking(0)}, options...)
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), options...)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"a", "b", "c", "d"} {
+			clock.Advance(time.Second)
+			err := cache.Set(key, []byte("value of "+key), time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		for _, key := range []string{"a", "c"} {
+			clock.Advance(time.Second)
+			_, err := cache.Get(key)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+		}
+		return cache
+	}
+	keys := func(t *testing.T, cache diskcache.Cache) []string {
+		t.Helper()
+		list, err := cache.List(diskcache.SortByKey)
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		var keys []string
+		for _, data := range list {
+			keys = append(keys, data.Key)
+		}
+		return keys
+	}
+
+	t.Run("TestSortByLastUsed", func(t *testing.T) {
+		cache := newCache(t)
+		list, err := cache.List(diskcache.SortByLastUsed)
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		var got []string
+		for _, data := range list {
+			got = append(got, data.Key)
+		}
+		if want := []string{"b", "d", "a", "c"}; !slices.Equal(got, want) {
+			t.Fatalf("Expected keys %v, got %v", want, got)
+		}
+	})
+
+	t.Run("TestPrune", func(t *testing.T) {
+		cache := newCache(t)
+		report, err := cache.Prune(diskcache.PrunePolicy{MaxEntries: 2, Order: diskcache.RemoveLeastRecentlyUsed})
+		if err != nil {
+			t.Fatalf("Error pruning cache: %v", err)
+		}
+		if want := []string{"b", "d"}; !slices.Equal(report.Removed, want) {
+			t.Fatalf("Expected %v to be removed, got %v", want, report.Removed)
+		}
+		if want := []string{"a", "c"}; !slices.Equal(keys(t, cache), want) {
+			t.Fatalf("Expected %v to be kept, got %v", want, keys(t, cache))
+		}
+	})
+
+	t.Run("TestPruneDefaultOrder", func(t *testing.T) {
+		cache := newCache(t)
+		report, err := cache.Prune(diskcache.PrunePolicy{MaxEntries: 2})
+		if err != nil {
+			t.Fatalf("Error pruning cache: %v", err)
+		}
+		if want := []string{"a", "b"}; !slices.Equal(report.Removed, want) {
+			t.Fatalf("Expected %v to be removed, got %v", want, report.Removed)
+		}
+	})
+
+	t.Run("TestInvalidPruneOrder", func(t *testing.T) {
+		cache := newCache(t)
+		_, err := cache.Prune(diskcache.PrunePolicy{MaxEntries: 2, Order: diskcache.RemovalOrder(42)})
+		if err == nil {
+			t.Fatalf("Expected error for invalid order")
+		}
+	})
+
+	t.Run("TestEviction", func(t *testing.T) {
+		for _, options := range [][]diskcache.Option{nil, {diskcache.WithIndex()}} {
+			options = append(options, diskcache.WithMaxEntries(4), diskcache.WithEvictionOrder(diskcache.RemoveLeastRecentlyUsed))
+			cache := newCache(t, options...)
+			err := cache.Set("e", []byte("value of e"), time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			if want := []string{"a", "c", "d", "e"}; !slices.Equal(keys(t, cache), want) {
+				t.Fatalf("Expected keys %v, got %v", want, keys(t, cache))
+			}
+		}
+	})
+
+	t.Run("TestInvalidEvictionOrder", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithEvictionOrder(diskcache.RemovalOrder(42)))
+		if err == nil {
+			t.Fatalf("Expected error for invalid order")
+		}
+	})
+}
@@ -0,0 +1,283 @@
+package diskcache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// manifestName is the name of the manifest file in the cache directory.
+const manifestName = "manifest.jsonl"
+
+// minCompactRecords is the number of manifest records below which
+// the manifest isn't compacted, however few entries the cache holds.
+const minCompactRecords = 1000
+
+// WithManifest enables the index and persists it in a manifest file
+// in the cache directory, so opening the cache reads the metadata
+// of the entries from one small file instead of parsing every entry file.
+// Set, Remove, and the other writes append a record of their change
+// to the manifest, which is compacted when it holds more than twice
+// as many records as the cache holds entries.
+// On open, the manifest is checked against the directory listing,
+// so entries written or removed without it are still found.
+// If the manifest can't be updated, it is removed,
+// and the next open scans the entry files instead.
+// The manifest requires a cache directory on the operating system's
+// file system, and holds the keys in plain text,
+// so it isn't supported on encrypted caches.
+func WithManifest() Option {
+	return func(c *Cache) {
+		c.manifestFile = true
+		if c.index == nil {
+			WithIndex()(c)
+		}
+	}
+}
+
+// manifest is the file in which an index is persisted.
+// A nil manifest is valid and all its methods are no-ops.
+// Its own lock serializes the changes to the file,
+// so the index isn't locked during file I/O.
+type manifest struct {
+	path     string
+	fileMode fs.FileMode
+	mu       sync.Mutex
+	// records is the number of records in the file.
+	records int
+	// discarded is true if the manifest was removed because it
+	// couldn't be updated, until it is rewritten.
+	discarded bool
+}
+
+// manifestRecord is a line of the manifest:
+// the metadata of an entry that was set, or a key that was removed.
+type manifestRecord struct {
+	Key      string
+	Removed  bool   `json:",omitempty"`
+	Filename string `json:",omitempty"`
+	Expiry   time.Time
+	Created  time.Time
+	Accessed time.Time
+	Size     int64 `json:",omitempty"`
+	Modified time.Time
+}
+
+// newManifest returns the manifest of a cache.
+func (c Cache) newManifest() (*manifest, error) {
+	if c.aead != nil {
+		return nil, errors.New("encrypted cache can't have a manifest")
+	}
+	_, err := c.dirStore()
+	if _, ok := c.fsys.(osFS); err != nil || !ok {
+		return nil, errors.New("manifest requires a cache directory on the operating system's file system")
+	}
+	return &manifest{path: filepath.Join(c.dir, manifestName), fileMode: c.fileMode}, nil
+}
+
+// read returns the index entries recorded in the manifest.
+// A last line that was only partly written, such as during a crash,
+// is skipped.
+func (m *manifest) read() (map[string]indexEntry, error) {
+	contents, err := os.ReadFile(m.path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]indexEntry)
+	m.records = 0
+	lines := bytes.Split(contents, []byte("\n"))
+	// The last line is empty, or partly written if it has no newline.
+	for _, line := range lines[:len(lines)-1] {
+		var rec manifestRecord
+		err := json.Unmarshal(line, &rec)
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest: %w", err)
+		}
+		m.records++
+		if rec.Removed {
+			delete(entries, rec.Key)
+			continue
+		}
+		entries[rec.Key] = indexEntry{
+			expiry:   rec.Expiry,
+			created:  rec.Created,
+			accessed: rec.Accessed,
+			size:     rec.Size,
+			filename: rec.Filename,
+			modified: rec.Modified,
+		}
+	}
+	return entries, nil
+}
+
+// appendRecord appends a record to the manifest.
+// If it can't, it removes the manifest, so it isn't trusted when stale.
+func (m *manifest) appendRecord(rec manifestRecord) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.discarded {
+		return
+	}
+	line, err := json.Marshal(rec)
+	if err == nil {
+		var f *os.File
+		f, err = os.OpenFile(m.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, m.fileMode)
+		if err == nil {
+			_, err = f.Write(append(line, '\n'))
+			err = errors.Join(err, f.Close())
+		}
+	}
+	if err != nil {
+		m.discard()
+		return
+	}
+	m.records++
+}
+
+// set records the index entry of a key.
+func (m *manifest) set(key string, entry indexEntry) {
+	m.appendRecord(manifestRecord{
+		Key:      key,
+		Filename: entry.filename,
+		Expiry:   entry.expiry,
+		Created:  entry.created,
+		Accessed: entry.accessed,
+		Size:     entry.size,
+		Modified: entry.modified,
+	})
+}
+
+// remove records that a key was removed.
+func (m *manifest) remove(key string) {
+	m.appendRecord(manifestRecord{Key: key, Removed: true})
+}
+
+// needsCompaction returns true if the manifest holds more than
+// twice as many records as an index with n entries.
+func (m *manifest) needsCompaction(n int) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.records > max(2*n, minCompactRecords)
+}
+
+// rewrite atomically replaces the manifest with one record per index entry.
+func (m *manifest) rewrite(entries map[string]indexEntry) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for key, entry := range entries {
+		err := enc.Encode(manifestRecord{
+			Key:      key,
+			Filename: entry.filename,
+			Expiry:   entry.expiry,
+			Created:  entry.created,
+			Accessed: entry.accessed,
+			Size:     entry.size,
+			Modified: entry.modified,
+		})
+		if err != nil {
+			m.discard()
+			return
+		}
+	}
+	_, err := writeFileAtomic(osFS{}, m.path, &buf, m.fileMode, false)
+	if err != nil {
+		m.discard()
+		return
+	}
+	m.records = len(entries)
+	m.discarded = false
+}
+
+// modified returns when an entry file was last written,
+// or the zero time if the cache has no manifest or it can't tell.
+func (m *manifest) modified(filename string) time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	info, err := os.Stat(filepath.Join(filepath.Dir(m.path), filename))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// discard removes the manifest and stops updating it,
+// since it would be missing the changes it couldn't record.
+// It must be called with the lock held.
+func (m *manifest) discard() {
+	_ = os.Remove(m.path)
+	m.records = 0
+	m.discarded = true
+}
+
+// loadManifest populates the index from the manifest,
+// checked against the entry files on disk, and compacts the manifest.
+// Entry files that aren't in the manifest, or whose size or
+// modification time changed, are read without decoding their values.
+// If there is no manifest, it reads all the entry files.
+func (idx *index) loadManifest(c Cache) error {
+	files, err := c.entryFiles()
+	if err != nil {
+		return err
+	}
+	entries, err := idx.manifest.read()
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.log(slog.LevelWarn, "error reading manifest", "error", err)
+		}
+		entries = make(map[string]indexEntry)
+	}
+	found := make(map[string]bool, len(entries))
+	for key, entry := range entries {
+		file, ok := files[entry.filename]
+		if !ok || file.Size != entry.size || !file.ModTime.Equal(entry.modified) || entry.filename != c.Filename(key) {
+			// The entry was removed or replaced without the manifest.
+			delete(entries, key)
+			continue
+		}
+		found[entry.filename] = true
+	}
+	for filename, file := range files {
+		if found[filename] {
+			continue
+		}
+		data, err := c.readMeta(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading entry: %w", err)
+		}
+		entries[data.Key] = indexEntry{
+			expiry:   data.Expiry,
+			created:  data.CreatedAt,
+			accessed: data.LastAccessed,
+			size:     file.Size,
+			filename: filename,
+			modified: file.ModTime,
+		}
+	}
+	idx.manifest.rewrite(entries)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries = entries
+	return nil
+}
@@ -0,0 +1,247 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestManifest(t *testing.T) {
+	// manifestLines returns the lines of the manifest of a cache directory.
+	manifestLines := func(t *testing.T, dir string) []string {
+		t.Helper()
+		contents, err := os.ReadFile(filepath.Join(dir, "manifest.jsonl"))
+		if err != nil {
+			t.Fatalf("Error reading manifest: %v", err)
+		}
+		return strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	}
+
+	t.Run("TestReopen", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"a", "b", "c"} {
+			err = cache.Set(key, []byte("value of "+key), time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		err = cache.Remove("b")
+		if err != nil {
+			t.Fatalf("Error removing cache: %v", err)
+		}
+		if lines := manifestLines(t, cacheDir); len(lines) != 4 {
+			t.Fatalf("Expected 4 manifest records, got %d", len(lines))
+		}
+		want, err := cache.ListInfo()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+
+		reopened, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		got, err := reopened.ListInfo()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(got) != 2 || len(want) != 2 {
+			t.Fatalf("Expected 2 entries, got %v and %v", got, want)
+		}
+		for i := range got {
+			if got[i].Key != want[i].Key || !got[i].Expiry.Equal(want[i].Expiry) || got[i].Size != want[i].Size {
+				t.Fatalf("Expected %v, got %v", want[i], got[i])
+			}
+		}
+		// Opening compacts the manifest.
+		if lines := manifestLines(t, cacheDir); len(lines) != 2 {
+			t.Fatalf("Expected 2 manifest records, got %d", len(lines))
+		}
+	})
+
+	t.Run("TestReadsManifest", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		// Change the expiry in the manifest only,
+		// so the next open can only see it in the manifest.
+		name := filepath.Join(cacheDir, "manifest.jsonl")
+		contents, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Error reading manifest: %v", err)
+		}
+		year := []byte(`"Expiry":"` + cache.Expiry("key").Format("2006"))
+		if !bytes.Contains(contents, year) {
+			t.Fatalf("Expected manifest to contain %s, got %s", year, contents)
+		}
+		contents = bytes.Replace(contents, year, []byte(`"Expiry":"2001`), 1)
+		err = os.WriteFile(name, contents, 0o644)
+		if err != nil {
+			t.Fatalf("Error writing manifest: %v", err)
+		}
+		reopened, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		if got := reopened.Expiry("key").Year(); got != 2001 {
+			t.Fatalf("Expected expiry from the manifest, got year %d", got)
+		}
+	})
+
+	t.Run("TestReconcile", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"kept", "removed", "replaced"} {
+			err = cache.Set(key, []byte("value"), time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		// Change the directory without the manifest.
+		plain, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = plain.Set("added", []byte("value"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = plain.Remove("removed")
+		if err != nil {
+			t.Fatalf("Error removing cache: %v", err)
+		}
+		err = plain.Set("replaced", []byte("longer value"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		reopened, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		keys, err := reopened.Keys()
+		if err != nil {
+			t.Fatalf("Error listing keys: %v", err)
+		}
+		if strings.Join(keys, ",") != "added,kept,replaced" {
+			t.Fatalf("Expected added, kept, and replaced, got %v", keys)
+		}
+		if !reopened.Expiry("replaced").IsZero() {
+			t.Fatalf("Expected replaced entry to never expire, got %s", reopened.Expiry("replaced"))
+		}
+	})
+
+	t.Run("TestAccessTimes", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		options := []diskcache.Option{
+			diskcache.WithFormat(diskcache.Binary),
+			diskcache.WithManifest(),
+			diskcache.WithAccessTracking(0),
+			diskcache.WithMaxEntries(2),
+			diskcache.WithEvictionOrder(diskcache.RemoveLeastRecentlyUsed),
+		}
+		cache, err := diskcache.New(cacheDir, options...)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"read", "unread"} {
+			err = cache.Set(key, []byte("value"), time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		_, err = cache.Get("read")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		// Without the manifest, the entry files are read on open.
+		err = os.Remove(filepath.Join(cacheDir, "manifest.jsonl"))
+		if err != nil {
+			t.Fatalf("Error removing manifest: %v", err)
+		}
+		reopened, err := diskcache.New(cacheDir, options...)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = reopened.Set("new", []byte("value"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		if !reopened.Has("read") || reopened.Has("unread") {
+			t.Fatalf("Expected the unread entry to be evicted")
+		}
+	})
+
+	t.Run("TestPartialRecord", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("key", []byte("value"), time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		f, err := os.OpenFile(filepath.Join(cacheDir, "manifest.jsonl"), os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			t.Fatalf("Error opening manifest: %v", err)
+		}
+		f.WriteString(`{"Key":"tru`)
+		f.Close()
+		reopened, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		keys, err := reopened.Keys()
+		if err != nil || len(keys) != 1 || keys[0] != "key" {
+			t.Fatalf("Expected key, got %v, %v", keys, err)
+		}
+	})
+
+	t.Run("TestCompaction", func(t *testing.T) {
+		cacheDir := path.Join(t.TempDir(), "testcache")
+		cache, err := diskcache.New(cacheDir, diskcache.WithManifest())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for range 1500 {
+			err = cache.Set("key", []byte("value"), time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		if lines := manifestLines(t, cacheDir); len(lines) > 1000 {
+			t.Fatalf("Expected manifest to be compacted, got %d records", len(lines))
+		}
+	})
+
+	t.Run("TestUnsupported", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithManifest(),
+			diskcache.WithEncryption(bytes.Repeat([]byte("k"), 32)))
+		if err == nil {
+			t.Fatalf("Expected error for encrypted cache with a manifest")
+		}
+		_, err = diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithManifest(), diskcache.WithFS(&faultFS{}))
+		if err == nil {
+			t.Fatalf("Expected error for a manifest on another file system")
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ListPrefix returns a list of the cache entries whose keys start with a prefix.
+// It accepts sorting and filtering options.
+// If the cache is indexed or encodes keys in filenames,
+// it reads only the matching entries.
+func (c Cache) ListPrefix(prefix string, options ...ListOption) ([]Data, error) {
+	return c.listMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	}, options)
+}
+
+// FlushPrefix deletes the cache entries whose keys start with a prefix,
+// such as the entries of one tenant.
+// It returns the number of entries deleted, even if some couldn't be deleted.
+// To delete the entries of a namespace, call Flush on the namespace.
+func (c Cache) FlushPrefix(prefix string) (int, error) {
+	if c.readOnly {
+		return 0, errReadOnly("flush", c.dir)
+	}
+	list, err := c.metaMatching(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+	if err != nil {
+		return 0, err
+	}
+	var removed int
+	var errs error
+	for _, data := range list {
+		err := c.remove(data.Key, false)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if err == nil {
+			removed++
+		}
+	}
+	return removed, errs
+}
+
+// Match returns a list of the cache entries whose keys match a glob pattern,
+// in which * matches any sequence of characters, including none,
+// ? matches any single character, [...] matches a character in a set,
+// such as [abc] or [a-z], [^...] matches a character not in it,
+// and \ escapes the next character.
+// Unlike path.Match, * and ? match slashes, since keys aren't paths.
+// It accepts sorting and filtering options.
+func (c Cache) Match(pattern string, options ...ListOption) ([]Data, error) {
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return c.listMatching(re.MatchString, options)
+}
+
+// MatchKeys returns the sorted keys of the unexpired cache entries
+// whose keys match a glob pattern, as in Match.
+// It reads the keys and expiries from the index, if any,
+// or from the entry files without decoding their values,
+// and skips entries removed while it reads them.
+func (c Cache) MatchKeys(pattern string) ([]string, error) {
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	list, err := c.metaMatching(re.MatchString)
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	var keys []string
+	for _, data := range list {
+		if !expired(data.Expiry, now) {
+			keys = append(keys, data.Key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// MatchRegexp returns a list of the cache entries whose keys match
+// a regular expression, which isn't anchored unless it says so.
+// It accepts sorting and filtering options.
+func (c Cache) MatchRegexp(expr string, options ...ListOption) ([]Data, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return c.listMatching(re.MatchString, options)
+}
+
+// globRegexp compiles a glob pattern to an anchored regular expression.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^(?s:")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '\\':
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("invalid pattern: %q ends with an escape", pattern)
+			}
+			expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			class, n, ok := globClass(runes[i+1:])
+			if !ok {
+				return nil, fmt.Errorf("invalid pattern: %q has an unclosed [", pattern)
+			}
+			expr.WriteString(class)
+			i += n
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString(")$")
+	return regexp.Compile(expr.String())
+}
+
+// globClass returns the regular expression of the glob character set
+// at the start of runes, which follow its opening bracket,
+// and the number of runes up to and including its closing bracket.
+// It returns false if the set isn't closed.
+// In the set, a leading ^ negates it, a leading ] is a literal,
+// a-z matches a range of characters, and \ escapes the next character.
+func globClass(runes []rune) (string, int, bool) {
+	var expr strings.Builder
+	expr.WriteString("[")
+	i := 0
+	if i < len(runes) && runes[i] == '^' {
+		expr.WriteString("^")
+		i++
+	}
+	for start := i; i < len(runes); i++ {
+		if runes[i] == ']' && i > start {
+			expr.WriteString("]")
+			return expr.String(), i + 1, true
+		}
+		lo, n := unescapeClassRune(runes[i:])
+		i += n
+		if i+2 < len(runes) && runes[i+1] == '-' && runes[i+2] != ']' {
+			hi, n := unescapeClassRune(runes[i+2:])
+			i += 2 + n
+			expr.WriteString(escapeClassRune(min(lo, hi)) + "-" + escapeClassRune(max(lo, hi)))
+			continue
+		}
+		expr.WriteString(escapeClassRune(lo))
+	}
+	return "", 0, false
+}
+
+// unescapeClassRune returns the rune at the start of a glob character set,
+// unescaping it, and the number of runes after it that it spans.
+func unescapeClassRune(runes []rune) (rune, int) {
+	if runes[0] == '\\' && len(runes) > 1 {
+		return runes[1], 1
+	}
+	return runes[0], 0
+}
+
+// escapeClassRune escapes a rune for a regular expression character class.
+func escapeClassRune(r rune) string {
+	if strings.ContainsRune(`\]-^[`, r) {
+		return `\` + string(r)
+	}
+	return string(r)
+}
+
+// metaMatching returns the keys and expiries of the cache entries
+// whose keys match, from the index, if any, or from the entry files
+// without decoding their values.
+// Entries removed while reading are skipped.
+func (c Cache) metaMatching(match func(key string) bool) ([]Data, error) {
+	var list []Data
+	err := c.walkMeta(match, func(data Data) bool {
+		list = append(list, data)
+		return true
+	})
+	return list, err
+}
+
+// walkMeta calls fn with the key and expiry of each cache entry
+// whose key matches, like metaMatching, until fn returns false.
+func (c Cache) walkMeta(match func(key string) bool, fn func(data Data) bool) error {
+	if c.index != nil {
+		for _, data := range c.index.list() {
+			if match(data.Key) && !fn(data) {
+				return nil
+			}
+		}
+		return nil
+	}
+	filenames, err := c.readDir()
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+	for _, filename := range filenames {
+		if key, ok := c.keyOf(filename); ok && !match(key) {
+			continue
+		}
+		data, err := c.readMeta(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading entry: %w", err)
+		}
+		if match(data.Key) && !fn(data) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// listMatching returns a list of the cache entries whose keys match,
+// with the sorting and filtering options applied.
+func (c Cache) listMatching(match func(key string) bool, options []ListOption) ([]Data, error) {
+	var filenames []string
+	if c.index != nil {
+		filenames = c.index.filenamesMatching(match)
+	} else {
+		all, err := c.readDir()
+		if err != nil {
+			return nil, fmt.Errorf("error reading directory: %w", err)
+		}
+		for _, filename := range all {
+			if key, ok := c.keyOf(filename); ok && !match(key) {
+				continue
+			}
+			filenames = append(filenames, filename)
+		}
+	}
+	var list []Data
+	for _, filename := range filenames {
+		entry, err := c.readFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("error reading entry: %w", err)
+		}
+		if match(entry.Key) {
+			list = append(list, entry)
+		}
+	}
+	return applyListOptions(list, options), nil
+}
@@ -0,0 +1,156 @@
+package diskcache_test
+
+import (
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestListPrefix(t *testing.T) {
+	for name, options := range map[string][]diskcache.Option{
+		"TestHashed":      nil,
+		"TestIndexed":     {diskcache.WithIndex()},
+		"TestKeyEncoding": {diskcache.WithKeyEncoding()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			for _, key := range []string{"user:2", "user:1", "order:1", "users"} {
+				err := cache.Set(key, []byte(key), 1*time.Minute)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+			list, err := cache.ListPrefix("user:", diskcache.SortByKey)
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			if len(list) != 2 || list[0].Key != "user:1" || list[1].Key != "user:2" {
+				t.Fatalf("Expected user:1 and user:2, got %v", list)
+			}
+			if string(list[0].Value) != "user:1" {
+				t.Fatalf("Expected cache value to be user:1, got %s", list[0].Value)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, key := range []string{"report:a:2023-01", "report:b/c:2023-02", "report:a:2024-01", "report*", "reports", "report-"} {
+		err := cache.Set(key, []byte(key), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+
+	t.Run("TestGlob", func(t *testing.T) {
+		for pattern, want := range map[string][]string{
+			"report:*:2023*":      {"report:a:2023-01", "report:b/c:2023-02"},
+			"report:?:2024-*":     {"report:a:2024-01"},
+			`report\*`:            {"report*"},
+			"report":              nil,
+			"report:[ab]:*-01":    {"report:a:2023-01", "report:a:2024-01"},
+			"report:?:202[3-3]-*": {"report:a:2023-01"},
+			"report[^s-]":         {"report*"},
+			`report[s\-]`:         {"report-", "reports"},
+			"report[]*]":          {"report*"},
+		} {
+			list, err := cache.Match(pattern, diskcache.SortByKey)
+			if err != nil {
+				t.Fatalf("Error matching %s: %v", pattern, err)
+			}
+			var got []string
+			for _, data := range list {
+				got = append(got, data.Key)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("Expected %s to match %v, got %v", pattern, want, got)
+			}
+		}
+		for _, pattern := range []string{`report\`, "report[a-"} {
+			_, err := cache.Match(pattern)
+			if err == nil {
+				t.Fatalf("Expected error matching invalid pattern %s", pattern)
+			}
+		}
+	})
+
+	t.Run("TestKeys", func(t *testing.T) {
+		err := cache.Set("report:c:2023-03", []byte("expired"), -1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		keys, err := cache.MatchKeys("report:*:2023*")
+		if err != nil {
+			t.Fatalf("Error matching: %v", err)
+		}
+		if !slices.Equal(keys, []string{"report:a:2023-01", "report:b/c:2023-02"}) {
+			t.Fatalf("Expected the unexpired 2023 reports, got %v", keys)
+		}
+		_, err = cache.MatchKeys("report[a-")
+		if err == nil {
+			t.Fatalf("Expected error matching invalid pattern")
+		}
+	})
+
+	t.Run("TestRegexp", func(t *testing.T) {
+		list, err := cache.MatchRegexp(`:202[34]-01$`, diskcache.SortByKey)
+		if err != nil {
+			t.Fatalf("Error matching: %v", err)
+		}
+		if len(list) != 2 || list[0].Key != "report:a:2023-01" || list[1].Key != "report:a:2024-01" {
+			t.Fatalf("Expected the January reports, got %v", list)
+		}
+		_, err = cache.MatchRegexp(`(`)
+		if err == nil {
+			t.Fatalf("Expected error matching invalid regular expression")
+		}
+	})
+}
+
+func TestFlushPrefix(t *testing.T) {
+	for name, options := range map[string][]diskcache.Option{
+		"TestHashed":      nil,
+		"TestIndexed":     {diskcache.WithIndex()},
+		"TestKeyEncoding": {diskcache.WithKeyEncoding()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			for _, key := range []string{"tenant:a:1", "tenant:a:2", "tenant:b:1", "other"} {
+				err := cache.Set(key, []byte(key), 1*time.Minute)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+			removed, err := cache.FlushPrefix("tenant:a:")
+			if err != nil {
+				t.Fatalf("Error flushing cache: %v", err)
+			}
+			if removed != 2 {
+				t.Fatalf("Expected 2 entries removed, got %d", removed)
+			}
+			keys, err := cache.Keys()
+			if err != nil {
+				t.Fatalf("Error listing keys: %v", err)
+			}
+			if !slices.Equal(keys, []string{"other", "tenant:b:1"}) {
+				t.Fatalf("Expected other and tenant:b:1, got %v", keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// schemaVersion is the schema version of the records the cache writes.
+// Changing the record layout requires incrementing it
+// and appending a migration from the previous version.
+const schemaVersion = 1
+
+// migrations upgrade a record from the version at their index to the next.
+var migrations = []func(record) (record, error){
+	// Version 0 records have no checksum or creation time,
+	// which are optional, so they need no changes.
+	func(rec record) (record, error) {
+		return rec, nil
+	},
+}
+
+// upgrade migrates a record to the current schema version.
+// It returns an error if the record was written by a newer version.
+func upgrade(rec record) (record, error) {
+	if rec.Version > schemaVersion {
+		return record{}, fmt.Errorf("unsupported schema version %d", rec.Version)
+	}
+	for version := rec.Version; version < schemaVersion; version++ {
+		var err error
+		rec, err = migrations[version](rec)
+		if err != nil {
+			return record{}, fmt.Errorf("error migrating from schema version %d: %w", version, err)
+		}
+		rec.Version = version + 1
+	}
+	return rec, nil
+}
+
+// Migrate rewrites the entries written with an older schema version
+// in the current one and returns the number of entries rewritten.
+// Entries are migrated when they are read, so Migrate isn't required,
+// but it saves migrating them on every read.
+// Rewritten entries are encoded with the cache's options
+// and keep their expiry and creation times.
+func (c Cache) Migrate() (int, error) {
+	filenames, err := c.readDir()
+	if err != nil {
+		return 0, fmt.Errorf("error reading directory: %w", err)
+	}
+	var n int
+	var errs error
+	for _, filename := range filenames {
+		contents, err := c.readEntry(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error reading entry %s: %w", filename, err))
+			continue
+		}
+		rec, err := unmarshal(contents)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error unmarshaling entry %s: %w", filename, err))
+			continue
+		}
+		if rec.Version == schemaVersion {
+			continue
+		}
+		rec, err = c.readDecoded(filename)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error reading entry %s: %w", filename, err))
+			continue
+		}
+		err = c.write(rec.Data)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error writing entry %s: %w", filename, err))
+			continue
+		}
+		n++
+	}
+	return n, errs
+}
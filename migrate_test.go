@@ -0,0 +1,82 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestMigrate(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	// Write an entry in the layout used before schema versions.
+	legacy, err := json.Marshal(diskcache.Data{
+		Key:    "legacy",
+		Value:  []byte("legacy value"),
+		Expiry: time.Now().Add(1 * time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("Error marshaling data: %v", err)
+	}
+	err = os.WriteFile(cache.Filepath("legacy"), legacy, 0644)
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	err = cache.Set("current", []byte("current value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestReadLegacy", func(t *testing.T) {
+		got, err := cache.Get("legacy")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "legacy value" {
+			t.Fatalf("Expected cache value to be legacy value, got %s", got)
+		}
+	})
+
+	t.Run("TestMigrate", func(t *testing.T) {
+		n, err := cache.Migrate()
+		if err != nil {
+			t.Fatalf("Error migrating cache: %v", err)
+		}
+		if n != 1 {
+			t.Fatalf("Expected 1 entry to be migrated, got %d", n)
+		}
+		contents, err := os.ReadFile(cache.Filepath("legacy"))
+		if err != nil {
+			t.Fatalf("Error reading file: %v", err)
+		}
+		if !bytes.Contains(contents, []byte(`"Version":1`)) {
+			t.Fatalf("Expected entry to have a schema version, got %s", contents)
+		}
+		n, err = cache.Migrate()
+		if err != nil {
+			t.Fatalf("Error migrating cache: %v", err)
+		}
+		if n != 0 {
+			t.Fatalf("Expected no entries to be migrated, got %d", n)
+		}
+	})
+
+	t.Run("TestNewerVersion", func(t *testing.T) {
+		err := os.WriteFile(cache.Filepath("future"), []byte(`{"Key":"future","Version":99}`), 0644)
+		if err != nil {
+			t.Fatalf("Error writing file: %v", err)
+		}
+		_, err = cache.Get("future")
+		if err == nil {
+			t.Fatalf("Expected error reading newer schema version")
+		}
+	})
+}
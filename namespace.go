@@ -0,0 +1,94 @@
+package diskcache
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Namespace returns a cache scoped to a subdirectory of the cache directory.
+// Its keys are isolated from the parent cache and from other namespaces,
+// so Flush, Clean, and List only affect the namespace.
+// The namespace shares the parent's configuration, except that it has
+// its own index and statistics and doesn't inherit the parent's janitor.
+// It accepts options to configure the namespace further.
+// In a sharded cache, the name can't look like a shard subdirectory.
+// The namespaces of a read-only cache created by NewFromFS are read-only.
+// Caches backed by another store don't support namespaces.
+func (c Cache) Namespace(name string, options ...Option) (Cache, error) {
+	if !validNamespace(name) || (c.sharded && isShard(name)) {
+		return Cache{}, fmt.Errorf("invalid namespace: %q", name)
+	}
+	if s, ok := c.baseStore().(fsStore); ok {
+		return c.fsNamespace(s, name, options)
+	}
+	_, err := c.dirStore()
+	if err != nil {
+		return Cache{}, err
+	}
+	ns := c
+	ns.dir = filepath.Join(c.dir, name)
+	ns.store = nil
+	ns.janitor = nil
+	ns.bloom = nil
+	if c.index != nil {
+		ns.index = &index{entries: make(map[string]indexEntry)}
+	}
+	return ns.open(options)
+}
+
+// fsNamespace returns a read-only namespace of a cache created by NewFromFS.
+func (c Cache) fsNamespace(s fsStore, name string, options []Option) (Cache, error) {
+	sub, err := fs.Sub(s.fsys, name)
+	if err != nil {
+		return Cache{}, fmt.Errorf("error opening namespace: %w", err)
+	}
+	ns := c
+	ns.store = fsStore{fsys: sub}
+	ns.janitor = nil
+	ns.bloom = nil
+	if c.index != nil {
+		ns.index = &index{entries: make(map[string]indexEntry)}
+	}
+	return ns.open(options)
+}
+
+// NamespaceSizes returns the total size in bytes of the entries
+// in each namespace of the cache, by name.
+// Namespaces are read from the directory, so they don't need to be open.
+// Nested namespaces are counted separately, not in their parent.
+func (c Cache) NamespaceSizes() (map[string]int64, error) {
+	_, err := c.dirStore()
+	if err != nil {
+		return nil, err
+	}
+	dirEntries, err := c.fsys.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	sizes := make(map[string]int64)
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if !dirEntry.IsDir() || (c.sharded && isShard(name)) {
+			continue
+		}
+		ns := c
+		ns.dir = filepath.Join(c.dir, name)
+		ns.store = c.newDirStore(ns.dir)
+		ns.index = nil
+		sizes[name], err = ns.Size()
+		if err != nil {
+			return nil, fmt.Errorf("error reading namespace %s: %w", name, err)
+		}
+	}
+	return sizes, nil
+}
+
+// validNamespace returns true if the name is a single path element.
+func validNamespace(name string) bool {
+	if len(name) == 0 || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,131 @@
+package diskcache_test
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestNamespace(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	users, err := cache.Namespace("users")
+	if err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+	if users.Dir() != path.Join(cacheDir, "users") {
+		t.Fatalf("Want namespace dir to be %s, got %s", path.Join(cacheDir, "users"), users.Dir())
+	}
+	orders, err := cache.Namespace("orders", diskcache.WithIndex())
+	if err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+
+	for _, c := range []diskcache.Cache{cache, users, orders} {
+		err := c.Set("key", []byte(c.Dir()), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+
+	t.Run("TestIsolation", func(t *testing.T) {
+		for _, c := range []diskcache.Cache{cache, users, orders} {
+			got, err := c.Get("key")
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			if string(got) != c.Dir() {
+				t.Fatalf("Expected cache value to be %s, got %s", c.Dir(), string(got))
+			}
+			data, err := c.List()
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			if len(data) != 1 {
+				t.Fatalf("Expected 1 key, got %d", len(data))
+			}
+		}
+	})
+
+	t.Run("TestFlush", func(t *testing.T) {
+		err := users.Flush()
+		if err != nil {
+			t.Fatalf("Error flushing cache: %v", err)
+		}
+		if users.Has("key") {
+			t.Fatalf("Expected key to not exist")
+		}
+		if !cache.Has("key") || !orders.Has("key") {
+			t.Fatalf("Expected other namespaces to keep their keys")
+		}
+		err = cache.Flush()
+		if err != nil {
+			t.Fatalf("Error flushing cache: %v", err)
+		}
+		if !orders.Has("key") {
+			t.Fatalf("Expected namespace to keep its keys")
+		}
+	})
+
+	t.Run("TestInvalidName", func(t *testing.T) {
+		for _, name := range []string{"", ".", "..", "a/b"} {
+			_, err := cache.Namespace(name)
+			if err == nil {
+				t.Errorf("Expected error for namespace %q, but got nil", name)
+			}
+		}
+	})
+}
+
+func TestNamespaceSizes(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	users, err := cache.Namespace("users")
+	if err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+	err = cache.Set("key", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = users.Set("key", []byte("a longer value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestSize", func(t *testing.T) {
+		size, err := cache.Size()
+		if err != nil {
+			t.Fatalf("Error getting size: %v", err)
+		}
+		usersSize, err := users.Size()
+		if err != nil {
+			t.Fatalf("Error getting size: %v", err)
+		}
+		if size == 0 || usersSize <= size {
+			t.Fatalf("Expected namespace size %d to exceed cache size %d", usersSize, size)
+		}
+	})
+
+	t.Run("TestNamespaceSizes", func(t *testing.T) {
+		sizes, err := cache.NamespaceSizes()
+		if err != nil {
+			t.Fatalf("Error getting namespace sizes: %v", err)
+		}
+		usersSize, err := users.Size()
+		if err != nil {
+			t.Fatalf("Error getting size: %v", err)
+		}
+		if len(sizes) != 1 || sizes["users"] != usersSize {
+			t.Fatalf("Expected sizes to be map[users:%d], got %v", usersSize, sizes)
+		}
+	})
+}
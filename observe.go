@@ -0,0 +1,61 @@
+package diskcache
+
+import "context"
+
+// Operation is a cache operation reported to an operation hook.
+type Operation struct {
+	// Name is the name of the method, such as Get.
+	Name string
+	// Key is the key of the entry, or empty for operations on the whole cache.
+	Key string
+}
+
+// OperationResult is the outcome of a cache operation reported to an operation hook.
+type OperationResult struct {
+	// Bytes is the size in bytes of the value read or written.
+	Bytes int
+	// Hit is true if Get returned a value.
+	Hit bool
+	// Err is the error returned by the operation, if any.
+	Err error
+}
+
+// OperationHook is called with the cache's context when a Get, Set,
+// Remove, or Clean operation starts, with SetWithMeta reported as Set,
+// and returns a function that is called when it ends.
+// It can be used to instrument the cache, for example with tracing spans.
+// The hook must be safe for concurrent use.
+type OperationHook func(ctx context.Context, op Operation) func(OperationResult)
+
+// WithOperationHook calls the hook around cache operations.
+func WithOperationHook(hook OperationHook) Option {
+	return func(c *Cache) {
+		c.hook = hook
+	}
+}
+
+// WithContext returns a copy of the cache whose operations
+// pass the context to the operation hook and the logger,
+// so they can be attributed to a request or a trace.
+// The context doesn't cancel operations.
+func (c Cache) WithContext(ctx context.Context) Cache {
+	c.ctx = ctx
+	return c
+}
+
+// context returns the cache's context, or the background context.
+func (c Cache) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// observe calls the operation hook, if any, for an operation that is starting,
+// and returns the function to call when it ends.
+func (c Cache) observe(name string, key string) func(OperationResult) {
+	if c.hook == nil {
+		return func(OperationResult) {}
+	}
+	return c.hook(c.context(), Operation{Name: name, Key: key})
+}
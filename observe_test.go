@@ -0,0 +1,57 @@
+package diskcache_test
+
+import (
+	"context"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+type ctxKey struct{}
+
+func TestOperationHook(t *testing.T) {
+	var ops []string
+	var results []diskcache.OperationResult
+	hook := func(ctx context.Context, op diskcache.Operation) func(diskcache.OperationResult) {
+		if ctx.Value(ctxKey{}) != "request" {
+			t.Errorf("Expected hook to get the cache context")
+		}
+		ops = append(ops, op.Name+" "+op.Key)
+		return func(result diskcache.OperationResult) {
+			results = append(results, result)
+		}
+	}
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithOperationHook(hook))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	cache = cache.WithContext(context.WithValue(context.Background(), ctxKey{}, "request"))
+	err = cache.Set("key", []byte("value"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	_, err = cache.Get("key")
+	if err != nil {
+		t.Fatalf("Error getting cache: %v", err)
+	}
+	err = cache.Remove("key")
+	if err != nil {
+		t.Fatalf("Error removing cache: %v", err)
+	}
+	err = cache.Clean()
+	if err != nil {
+		t.Fatalf("Error cleaning cache: %v", err)
+	}
+
+	want := []string{"Set key", "Get key", "Remove key", "Clean "}
+	if !slices.Equal(ops, want) {
+		t.Fatalf("Expected operations %v, got %v", want, ops)
+	}
+	if results[1].Bytes != 5 || !results[1].Hit {
+		t.Fatalf("Expected Get to hit 5 bytes, got %v", results[1])
+	}
+}
@@ -0,0 +1,49 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// ListPage returns a page of at most limit cache entries, including
+// expired ones, skipping the first offset entries, and the total number
+// of entries, so a UI or API can page through a large cache.
+// Entries are sorted by key, then sorted and filtered by the options,
+// so entries that sort equally stay in key order across pages,
+// and the total counts the entries that pass the filters.
+// It reads the keys and expiries of all entries, from the index, if any,
+// or from the entry files without decoding their values,
+// but reads the values of the entries on the page only.
+// So the options see no values, and SortByValue and SortBySize have no effect.
+// Entries removed while paging are skipped, so a page may be short.
+func (c Cache) ListPage(offset int, limit int, options ...ListOption) ([]Data, int, error) {
+	if offset < 0 || limit < 0 {
+		return nil, 0, fmt.Errorf("invalid page: offset %d, limit %d", offset, limit)
+	}
+	all, err := c.metaMatching(func(string) bool { return true })
+	if err != nil {
+		return nil, 0, err
+	}
+	slices.SortFunc(all, func(a, b Data) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	all = applyListOptions(all, options)
+	total := len(all)
+	start := min(offset, total)
+	end := min(start+limit, total)
+	var page []Data
+	for _, meta := range all[start:end] {
+		entry, err := c.Read(meta.Key)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("error reading entry: %w", err)
+		}
+		page = append(page, entry)
+	}
+	return page, total, nil
+}
@@ -0,0 +1,81 @@
+package diskcache_test
+
+import (
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestListPage(t *testing.T) {
+	for name, options := range map[string][]diskcache.Option{
+		"TestHashed":  nil,
+		"TestIndexed": {diskcache.WithIndex()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			for i := range 10 {
+				duration := diskcache.NoExpiry
+				if i%2 == 0 {
+					duration = time.Duration(10-i) * time.Minute
+				}
+				err := cache.Set(fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)), duration)
+				if err != nil {
+					t.Fatalf("Error saving cache: %v", err)
+				}
+			}
+
+			t.Run("TestKeyOrder", func(t *testing.T) {
+				page, total, err := cache.ListPage(3, 4)
+				if err != nil {
+					t.Fatalf("Error listing page: %v", err)
+				}
+				if total != 10 {
+					t.Fatalf("Expected 10 entries in total, got %d", total)
+				}
+				if len(page) != 4 || page[0].Key != "key3" || page[3].Key != "key6" {
+					t.Fatalf("Expected key3 to key6, got %v", page)
+				}
+				if string(page[0].Value) != "value3" {
+					t.Fatalf("Expected cache value to be value3, got %s", page[0].Value)
+				}
+			})
+
+			t.Run("TestSorted", func(t *testing.T) {
+				var keys []string
+				for offset := 0; offset < 10; offset += 3 {
+					page, _, err := cache.ListPage(offset, 3, diskcache.SortByExpiry)
+					if err != nil {
+						t.Fatalf("Error listing page: %v", err)
+					}
+					for _, entry := range page {
+						keys = append(keys, entry.Key)
+					}
+				}
+				want := "[key8 key6 key4 key2 key0 key1 key3 key5 key7 key9]"
+				if fmt.Sprint(keys) != want {
+					t.Fatalf("Expected %s, got %v", want, keys)
+				}
+			})
+
+			t.Run("TestPastEnd", func(t *testing.T) {
+				page, total, err := cache.ListPage(20, 5)
+				if err != nil {
+					t.Fatalf("Error listing page: %v", err)
+				}
+				if len(page) != 0 || total != 10 {
+					t.Fatalf("Expected empty page of 10 entries, got %d of %d", len(page), total)
+				}
+				_, _, err = cache.ListPage(-1, 5)
+				if err == nil {
+					t.Fatalf("Expected error for negative offset")
+				}
+			})
+		})
+	}
+}
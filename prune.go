@@ -0,0 +1,168 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"time"
+)
+
+// PrunePolicy is the set of limits Prune enforces.
+// A zero limit means no limit.
+type PrunePolicy struct {
+	// MaxBytes is the maximum total size in bytes of the entries.
+	MaxBytes int64
+	// MaxAge is the maximum time since an entry was written.
+	// Entries written before creation times were recorded have no age
+	// and are never removed for it.
+	MaxAge time.Duration
+	// MaxEntries is the maximum number of entries.
+	MaxEntries int
+	// Order is the order in which entries are removed to stay within
+	// MaxEntries and MaxBytes.
+	// The default removes the oldest entries first.
+	Order RemovalOrder
+}
+
+// PruneReport describes the entries removed by Prune.
+type PruneReport struct {
+	// Removed is the keys of the removed entries.
+	Removed []string
+	// Bytes is the total size in bytes of the removed entries.
+	Bytes int64
+}
+
+// Prune deletes entries until the cache satisfies the policy.
+// It deletes expired entries first, then entries older than MaxAge,
+// then the oldest or least recently used entries, depending on Order,
+// until the cache is within MaxEntries and MaxBytes.
+// It returns a report of the removed entries,
+// even if some entries couldn't be removed.
+func (c Cache) Prune(policy PrunePolicy) (PruneReport, error) {
+	if policy.Order != RemoveDefault && policy.Order != RemoveLeastRecentlyUsed {
+		return PruneReport{}, fmt.Errorf("invalid prune order %v", policy.Order)
+	}
+	candidates, err := c.pruneCandidates()
+	if err != nil {
+		return PruneReport{}, err
+	}
+	now := c.now()
+	var entries int
+	var size int64
+	for _, candidate := range candidates {
+		entries++
+		size += candidate.size
+	}
+	// Oldest or least recently used first,
+	// with entries of unknown age before all others.
+	slices.SortStableFunc(candidates, func(a, b pruneCandidate) int {
+		if policy.Order == RemoveLeastRecentlyUsed {
+			return lastUsed(a.accessed, a.created).Compare(lastUsed(b.accessed, b.created))
+		}
+		return a.created.Compare(b.created)
+	})
+	var report PruneReport
+	var errs error
+	remove := func(candidate pruneCandidate) {
+		err := c.remove(candidate.key, expired(candidate.expiry, now))
+		if err != nil {
+			errs = errors.Join(errs, err)
+			return
+		}
+		report.Removed = append(report.Removed, candidate.key)
+		report.Bytes += candidate.size
+		entries--
+		size -= candidate.size
+	}
+	var kept []pruneCandidate
+	for _, candidate := range candidates {
+		tooOld := policy.MaxAge > 0 && !candidate.created.IsZero() && now.Sub(candidate.created) > policy.MaxAge
+		if expired(candidate.expiry, now) || tooOld {
+			remove(candidate)
+			continue
+		}
+		kept = append(kept, candidate)
+	}
+	for _, candidate := range kept {
+		overEntries := policy.MaxEntries > 0 && entries > policy.MaxEntries
+		overBytes := policy.MaxBytes > 0 && size > policy.MaxBytes
+		if !overEntries && !overBytes {
+			break
+		}
+		remove(candidate)
+	}
+	return report, errs
+}
+
+// RemoveOlderThan deletes the entries written before a time,
+// regardless of their expiry, such as to enforce a retention period.
+// Entries written before creation times were recorded have no age,
+// so they are deleted too.
+// Touch doesn't change when an entry was written; Set does.
+// It returns the keys of the removed entries,
+// even if some entries couldn't be removed.
+func (c Cache) RemoveOlderThan(t time.Time) ([]string, error) {
+	if c.readOnly {
+		return nil, errReadOnly("remove", c.dir)
+	}
+	candidates, err := c.pruneCandidates()
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	var removed []string
+	var errs error
+	for _, candidate := range candidates {
+		if !candidate.created.Before(t) {
+			continue
+		}
+		err := c.remove(candidate.key, expired(candidate.expiry, now))
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		removed = append(removed, candidate.key)
+	}
+	slices.Sort(removed)
+	return removed, errs
+}
+
+// pruneCandidate is the metadata of a cache entry that Prune may remove.
+type pruneCandidate struct {
+	key      string
+	expiry   time.Time
+	created  time.Time
+	accessed time.Time
+	size     int64
+}
+
+// pruneCandidates returns the metadata of all cache entries.
+func (c Cache) pruneCandidates() ([]pruneCandidate, error) {
+	filenames, err := c.readDir()
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	sizes, err := c.sizes()
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	var candidates []pruneCandidate
+	for _, filename := range filenames {
+		rec, err := c.readHeader(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading entry: %w", err)
+		}
+		candidates = append(candidates, pruneCandidate{
+			key:      rec.Key,
+			expiry:   rec.Expiry,
+			created:  rec.CreatedAt,
+			accessed: rec.LastAccessed,
+			size:     sizes[filename],
+		})
+	}
+	return candidates, nil
+}
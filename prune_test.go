@@ -0,0 +1,166 @@
+package diskcache_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestPrune(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithIndex())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	set := func(key string, duration time.Duration) {
+		t.Helper()
+		err := cache.Set(key, []byte("value of "+key), duration)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		// Keep creation times distinct.
+		time.Sleep(2 * time.Millisecond)
+	}
+	set("expired", 1*time.Millisecond)
+	set("oldest", diskcache.NoExpiry)
+	set("older", 1*time.Minute)
+	set("newer", 1*time.Minute)
+	set("newest", 1*time.Minute)
+
+	t.Run("TestNoLimits", func(t *testing.T) {
+		report, err := cache.Prune(diskcache.PrunePolicy{})
+		if err != nil {
+			t.Fatalf("Error pruning cache: %v", err)
+		}
+		if !slices.Equal(report.Removed, []string{"expired"}) || report.Bytes == 0 {
+			t.Fatalf("Expected only the expired entry to be removed, got %v", report)
+		}
+	})
+
+	t.Run("TestMaxEntries", func(t *testing.T) {
+		report, err := cache.Prune(diskcache.PrunePolicy{MaxEntries: 3})
+		if err != nil {
+			t.Fatalf("Error pruning cache: %v", err)
+		}
+		if !slices.Equal(report.Removed, []string{"oldest"}) {
+			t.Fatalf("Expected the oldest entry to be removed, got %v", report.Removed)
+		}
+	})
+
+	t.Run("TestMaxBytes", func(t *testing.T) {
+		size, err := cache.Size()
+		if err != nil {
+			t.Fatalf("Error getting size: %v", err)
+		}
+		report, err := cache.Prune(diskcache.PrunePolicy{MaxBytes: size - 1})
+		if err != nil {
+			t.Fatalf("Error pruning cache: %v", err)
+		}
+		if !slices.Equal(report.Removed, []string{"older"}) {
+			t.Fatalf("Expected the older entry to be removed, got %v", report.Removed)
+		}
+		got, err := cache.Size()
+		if err != nil {
+			t.Fatalf("Error getting size: %v", err)
+		}
+		if got != size-report.Bytes {
+			t.Fatalf("Expected size to be %d, got %d", size-report.Bytes, got)
+		}
+	})
+
+	t.Run("TestMaxAge", func(t *testing.T) {
+		time.Sleep(10 * time.Millisecond)
+		set("fresh", 1*time.Minute)
+		report, err := cache.Prune(diskcache.PrunePolicy{MaxAge: 5 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("Error pruning cache: %v", err)
+		}
+		if !slices.Equal(report.Removed, []string{"newer", "newest"}) {
+			t.Fatalf("Expected the old entries to be removed, got %v", report.Removed)
+		}
+		if !cache.Has("fresh") {
+			t.Fatalf("Expected fresh entry to be kept")
+		}
+	})
+}
+
+func TestRemoveOlderThan(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.Set("old", []byte("value"), diskcache.NoExpiry)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	legacy, err := json.Marshal(diskcache.Data{Key: "legacy", Value: []byte("value")})
+	if err != nil {
+		t.Fatalf("Error marshaling data: %v", err)
+	}
+	err = os.WriteFile(cache.Filepath("legacy"), legacy, 0644)
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	err = cache.Set("new", []byte("value"), time.Nanosecond)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	removed, err := cache.RemoveOlderThan(cutoff)
+	if err != nil {
+		t.Fatalf("Error removing entries: %v", err)
+	}
+	if !slices.Equal(removed, []string{"legacy", "old"}) {
+		t.Fatalf("Expected legacy and old removed, got %v", removed)
+	}
+	keys, err := cache.Keys()
+	if err != nil {
+		t.Fatalf("Error listing keys: %v", err)
+	}
+	if !slices.Equal(keys, []string{"new"}) {
+		t.Fatalf("Expected new to remain, got %v", keys)
+	}
+}
+
+func TestPruneRemovedEntry(t *testing.T) {
+	fsys := &faultFS{}
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithFS(fsys))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, key := range []string{"kept", "removed"} {
+		err = cache.Set(key, []byte("value of "+key), 1*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+	// Another process removes the entry after the directory is read.
+	removed := cache.Filepath("removed")
+	fsys.fail = func(op string, name string) error {
+		if op == "open" && name == removed {
+			return fs.ErrNotExist
+		}
+		return nil
+	}
+	report, err := cache.Prune(diskcache.PrunePolicy{})
+	if err != nil {
+		t.Fatalf("Error pruning cache: %v", err)
+	}
+	if !slices.Equal(report.Removed, []string{"kept"}) {
+		t.Fatalf("Expected kept to be removed, got %v", report.Removed)
+	}
+	_, err = cache.RemoveOlderThan(time.Now())
+	if err != nil {
+		t.Fatalf("Error removing entries: %v", err)
+	}
+}
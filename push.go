@@ -0,0 +1,266 @@
+package diskcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// remoteSumExt is the extension of the blobs in a remote store that
+// describe the entries pushed to it, one per entry, so pushes from
+// several caches don't overwrite a shared manifest.
+const remoteSumExt = ".sum"
+
+// remoteSum is the blob in a remote store that describes a pushed entry,
+// so Push and Pull can compare entries without reading them.
+type remoteSum struct {
+	Key string
+	// Hash is the hex-encoded SHA-256 hash of the blob of the entry.
+	Hash      string
+	CreatedAt time.Time
+	Expiry    time.Time
+}
+
+// TransferReport describes the entries copied by Push or Pull.
+type TransferReport struct {
+	// Copied is the number of entries copied.
+	Copied int
+	// Skipped is the number of entries already up to date,
+	// or older than the entries they would replace.
+	Skipped int
+	// Bytes is the number of bytes copied.
+	Bytes int64
+}
+
+// Push copies the unexpired cache entries to a remote store,
+// such as a bucket in object storage, so other caches can Pull them.
+// Each entry is stored decoded in a JSON blob, like Export,
+// alongside a blob with its hash, expiry, and creation time,
+// so entries already in the store aren't copied again.
+// When the store has an entry with different contents,
+// the one that expires last wins, then the newer one, like a
+// bidirectional Sync. Entries in the store that aren't in the cache
+// are kept, so several caches can push to the same store.
+// Push copies the other entries when some fail,
+// and returns their errors joined.
+// Push stops when the context is done.
+func (c Cache) Push(ctx context.Context, remote Store) (TransferReport, error) {
+	var report TransferReport
+	filenames, err := c.filenames()
+	if err != nil {
+		return report, fmt.Errorf("error reading directory: %w", err)
+	}
+	now := c.now()
+	var errs error
+	for _, filename := range filenames {
+		if err := ctx.Err(); err != nil {
+			errs = errors.Join(fmt.Errorf("push canceled: %w", err), errs)
+			break
+		}
+		rec, err := c.readDecoded(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error reading entry: %w", err))
+			continue
+		}
+		if expired(rec.Expiry, now) {
+			continue
+		}
+		contents, sum, err := marshalRemote(rec.Data)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		pushed, err := readRemoteSum(remote, remoteSumName(rec.Key))
+		if err == nil && (pushed.Hash == sum || !syncWins(rec.Data, pushed.data())) {
+			report.Skipped++
+			continue
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		n, err := pushRemote(remote, rec.Data, contents, sum)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		report.Copied++
+		report.Bytes += n
+	}
+	return report, errs
+}
+
+// Pull copies the unexpired entries pushed to a remote store into the cache,
+// replacing entries with the same keys that differ,
+// unless the entry of the cache expires later, or at the same time
+// and is newer, like a bidirectional Sync.
+// The entries keep their expiry and creation times.
+// Entries whose hash matches the entry of the cache aren't copied again.
+// Pull copies the other entries when some fail,
+// and returns their errors joined.
+// Pull stops when the context is done.
+func (c Cache) Pull(ctx context.Context, remote Store) (TransferReport, error) {
+	var report TransferReport
+	if c.readOnly {
+		return report, errReadOnly("pull", c.dir)
+	}
+	entries, err := remote.ListEntries()
+	if err != nil {
+		return report, fmt.Errorf("error listing remote store: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name, remoteSumExt) {
+			names = append(names, entry.Name)
+		}
+	}
+	slices.Sort(names)
+	now := c.now()
+	var errs error
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			errs = errors.Join(fmt.Errorf("pull canceled: %w", err), errs)
+			break
+		}
+		pushed, err := readRemoteSum(remote, name)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if expired(pushed.Expiry, now) {
+			continue
+		}
+		local, err := c.readDecoded(c.Filename(pushed.Key))
+		hasLocal := err == nil && !expired(local.Expiry, now)
+		if hasLocal {
+			_, sum, err := marshalRemote(local.Data)
+			if err != nil {
+				errs = errors.Join(errs, err)
+				continue
+			}
+			if sum == pushed.Hash || !syncWins(pushed.data(), local.Data) {
+				report.Skipped++
+				continue
+			}
+		}
+		entry, n, err := readRemote(remote, pushed.Key)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if entry.Key != pushed.Key || expired(entry.Expiry, now) {
+			continue
+		}
+		// The entry may have been pushed again since its sum was read.
+		if hasLocal && !syncWins(entry, local.Data) {
+			report.Skipped++
+			continue
+		}
+		err = c.write(entry)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error copying %q: %w", entry.Key, err))
+			continue
+		}
+		report.Copied++
+		report.Bytes += n
+	}
+	err = c.evict("")
+	if err != nil {
+		errs = errors.Join(errs, fmt.Errorf("error evicting entries: %w", err))
+	}
+	return report, errs
+}
+
+// data returns the entry described by a sum, without its value,
+// to compare with syncWins.
+func (s remoteSum) data() Data {
+	return Data{Key: s.Key, CreatedAt: s.CreatedAt, Expiry: s.Expiry}
+}
+
+// pushRemote writes the blob of an entry and then its sum to a remote store,
+// and returns the size of the blob.
+func pushRemote(remote Store, entry Data, contents []byte, sum string) (int64, error) {
+	n, err := remote.WriteEntry(remoteName(entry.Key), bytes.NewReader(contents))
+	if err != nil {
+		return 0, fmt.Errorf("error pushing %q: %w", entry.Key, err)
+	}
+	pushed, err := json.Marshal(remoteSum{Key: entry.Key, Hash: sum, CreatedAt: entry.CreatedAt, Expiry: entry.Expiry})
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling sum: %w", err)
+	}
+	_, err = remote.WriteEntry(remoteSumName(entry.Key), bytes.NewReader(pushed))
+	if err != nil {
+		return 0, fmt.Errorf("error pushing %q: %w", entry.Key, err)
+	}
+	return n, nil
+}
+
+// remoteName returns the name of the blob of a key in a remote store.
+func remoteName(key string) string {
+	return hashName(key) + ".json"
+}
+
+// remoteSumName returns the name of the sum blob of a key in a remote store.
+func remoteSumName(key string) string {
+	return hashName(key) + remoteSumExt
+}
+
+// marshalRemote returns the blob of a cache entry in a remote store
+// and its hex-encoded SHA-256 hash.
+// The access time is left out, since it changes on every read.
+func marshalRemote(entry Data) ([]byte, string, error) {
+	entry.LastAccessed = time.Time{}
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return nil, "", fmt.Errorf("error marshaling entry: %w", err)
+	}
+	sum := sha256.Sum256(contents)
+	return contents, hex.EncodeToString(sum[:]), nil
+}
+
+// readRemote reads the blob of a key from a remote store
+// and returns its entry and size.
+func readRemote(remote Store, key string) (Data, int64, error) {
+	r, err := remote.ReadEntry(remoteName(key))
+	if err != nil {
+		return Data{}, 0, fmt.Errorf("error pulling %q: %w", key, err)
+	}
+	defer r.Close()
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return Data{}, 0, fmt.Errorf("error pulling %q: %w", key, err)
+	}
+	var entry Data
+	err = json.Unmarshal(contents, &entry)
+	if err != nil {
+		return Data{}, 0, fmt.Errorf("error unmarshaling %q: %w", key, err)
+	}
+	return entry, int64(len(contents)), nil
+}
+
+// readRemoteSum reads a sum blob from a remote store.
+func readRemoteSum(remote Store, name string) (remoteSum, error) {
+	r, err := remote.ReadEntry(name)
+	if err != nil {
+		return remoteSum{}, fmt.Errorf("error reading sum: %w", err)
+	}
+	defer r.Close()
+	var sum remoteSum
+	err = json.NewDecoder(r).Decode(&sum)
+	if err != nil {
+		return remoteSum{}, fmt.Errorf("error unmarshaling sum %s: %w", name, err)
+	}
+	return sum, nil
+}
@@ -0,0 +1,262 @@
+package diskcache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestPush(t *testing.T) {
+	remote := &memStore{blobs: make(map[string][]byte)}
+	src, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithCompression(diskcache.Gzip))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	dst, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithRawValues())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, key := range []string{"key1", "key2"} {
+		err := src.Set(key, []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	err = src.Set("expired", []byte("value"), 1*time.Nanosecond)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	time.Sleep(1 * time.Millisecond)
+	ctx := context.Background()
+
+	t.Run("TestPush", func(t *testing.T) {
+		report, err := src.Push(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pushing cache: %v", err)
+		}
+		if report.Copied != 2 || report.Skipped != 0 || report.Bytes == 0 {
+			t.Fatalf("Expected 2 entries to be copied, got %+v", report)
+		}
+		report, err = src.Push(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pushing cache: %v", err)
+		}
+		if report.Copied != 0 || report.Skipped != 2 {
+			t.Fatalf("Expected 2 entries to be skipped, got %+v", report)
+		}
+	})
+
+	t.Run("TestPull", func(t *testing.T) {
+		report, err := dst.Pull(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pulling cache: %v", err)
+		}
+		if report.Copied != 2 {
+			t.Fatalf("Expected 2 entries to be copied, got %+v", report)
+		}
+		value, ttl, err := dst.GetWithTTL("key1")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(value) != "value" || ttl <= 59*time.Minute {
+			t.Fatalf("Expected value for about 1h, got %s for %v", value, ttl)
+		}
+		if dst.Has("expired") {
+			t.Fatalf("Expected expired entry not to be pulled")
+		}
+		report, err = dst.Pull(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pulling cache: %v", err)
+		}
+		if report.Copied != 0 || report.Skipped != 2 {
+			t.Fatalf("Expected 2 entries to be skipped, got %+v", report)
+		}
+	})
+
+	t.Run("TestIncremental", func(t *testing.T) {
+		err := src.Set("key1", []byte("updated"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		report, err := src.Push(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pushing cache: %v", err)
+		}
+		if report.Copied != 1 || report.Skipped != 1 {
+			t.Fatalf("Expected 1 entry to be copied, got %+v", report)
+		}
+		report, err = dst.Pull(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pulling cache: %v", err)
+		}
+		if report.Copied != 1 || report.Skipped != 1 {
+			t.Fatalf("Expected 1 entry to be copied, got %+v", report)
+		}
+		value, err := dst.Get("key1")
+		if err != nil || string(value) != "updated" {
+			t.Fatalf("Expected updated, got %s, %v", value, err)
+		}
+	})
+
+	t.Run("TestKeepsRemoteEntries", func(t *testing.T) {
+		err := dst.Set("other", []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, err = dst.Push(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pushing cache: %v", err)
+		}
+		_, err = src.Pull(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pulling cache: %v", err)
+		}
+		keys, err := src.Keys()
+		if err != nil {
+			t.Fatalf("Error listing keys: %v", err)
+		}
+		if len(keys) != 4 {
+			t.Fatalf("Expected 4 keys, got %v", keys)
+		}
+	})
+
+	t.Run("TestKeepsNewerEntries", func(t *testing.T) {
+		err := dst.Set("key1", []byte("local"), 2*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		report, err := dst.Pull(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pulling cache: %v", err)
+		}
+		if report.Copied != 0 {
+			t.Fatalf("Expected no entries to be copied, got %+v", report)
+		}
+		value, err := dst.Get("key1")
+		if err != nil || string(value) != "local" {
+			t.Fatalf("Expected the newer local entry to be kept, got %s, %v", value, err)
+		}
+		err = src.Set("key1", []byte("stale"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, err = dst.Push(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pushing cache: %v", err)
+		}
+		report, err = src.Push(ctx, remote)
+		if err != nil {
+			t.Fatalf("Error pushing cache: %v", err)
+		}
+		if report.Copied != 0 {
+			t.Fatalf("Expected the older entry not to be pushed, got %+v", report)
+		}
+	})
+
+	t.Run("TestCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := src.Set("key2", []byte("updated"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, err = src.Push(ctx, remote)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+// failStore is a store that fails to write the blobs of a key.
+type failStore struct {
+	*memStore
+	name string
+}
+
+func (s failStore) WriteEntry(name string, r io.Reader) (int64, error) {
+	if strings.HasPrefix(name, s.name) {
+		return 0, syscall.EIO
+	}
+	return s.memStore.WriteEntry(name, r)
+}
+
+func TestPushConcurrent(t *testing.T) {
+	remote := &memStore{blobs: make(map[string][]byte)}
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := range 2 {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for j := range 10 {
+			err := cache.Set(fmt.Sprintf("key%d-%d", i, j), []byte("value"), 1*time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Push(ctx, remote)
+			if err != nil {
+				t.Errorf("Error pushing cache: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	dst, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	report, err := dst.Pull(ctx, remote)
+	if err != nil {
+		t.Fatalf("Error pulling cache: %v", err)
+	}
+	if report.Copied != 20 {
+		t.Fatalf("Expected 20 entries to be copied, got %+v", report)
+	}
+}
+
+func TestPushError(t *testing.T) {
+	mem := &memStore{blobs: make(map[string][]byte)}
+	ctx := context.Background()
+	src, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		err := src.Set(key, []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	remote := failStore{memStore: mem, name: strings.TrimSuffix(src.Filename("b"), ".json")}
+	report, err := src.Push(ctx, remote)
+	if !errors.Is(err, syscall.EIO) {
+		t.Fatalf("Expected EIO, got %v", err)
+	}
+	if report.Copied != 2 {
+		t.Fatalf("Expected 2 entries to be copied, got %+v", report)
+	}
+	dst, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	_, err = dst.Pull(ctx, mem)
+	if err != nil {
+		t.Fatalf("Error pulling cache: %v", err)
+	}
+	if !dst.Has("a") || dst.Has("b") || !dst.Has("c") {
+		t.Fatalf("Expected a and c to be pulled")
+	}
+}
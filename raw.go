@@ -0,0 +1,68 @@
+package diskcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// rawExt is the extension of sidecar files holding raw values.
+const rawExt = ".bin"
+
+// WithRawValues stores every value verbatim in a sidecar file,
+// such as <hash>.bin, next to the entry file holding its key and expiry.
+// Other tools can use the sidecar files directly,
+// and a web server can serve them without unwrapping entries.
+// Raw values can't be compressed or encrypted.
+func WithRawValues() Option {
+	return func(c *Cache) {
+		c.rawValues = true
+	}
+}
+
+// ValueFilepath returns the full path of the sidecar file holding
+// the raw value of a cache entry.
+// The sidecar exists only for entries saved with SetReader
+// or by a cache with raw values.
+func (c Cache) ValueFilepath(key string) string {
+	return c.filepath(c.sidecar(c.Filename(key)))
+}
+
+// writeRaw saves a cache entry with its value read from r to a sidecar file.
+// It ignores the value of the entry.
+func (c Cache) writeRaw(entry Data, r io.Reader) error {
+	filename := c.Filename(entry.Key)
+	unlock := c.files.lock(filename)
+	defer unlock()
+	hash := sha256.New()
+	n, err := c.store.WriteEntry(c.sidecar(filename), io.TeeReader(r, hash))
+	if err != nil {
+		return err
+	}
+	entry.Value = nil
+	contents, err := c.marshal(record{
+		Data:     entry,
+		Version:  schemaVersion,
+		Raw:      true,
+		Checksum: hex.EncodeToString(hash.Sum(nil)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.store.WriteEntry(filename, bytes.NewReader(contents))
+	if err != nil {
+		return err
+	}
+	c.index.set(entry, n+int64(len(contents)), filename)
+	c.stats.write(int(n) + len(contents))
+	c.log(slog.LevelDebug, "wrote entry", "key", entry.Key, "bytes", n+int64(len(contents)), "expiry", entry.Expiry)
+	return nil
+}
+
+// sidecar returns the filename of the sidecar file of an entry file.
+func (c Cache) sidecar(filename string) string {
+	return strings.TrimSuffix(filename, c.ext) + rawExt
+}
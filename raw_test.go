@@ -0,0 +1,95 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestRawValues(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithRawValues())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "image.png"
+	value := []byte("\x89PNG\r\n\x1a\n")
+	err = cache.Set(key, value, 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestSidecar", func(t *testing.T) {
+		raw, err := os.ReadFile(cache.ValueFilepath(key))
+		if err != nil {
+			t.Fatalf("Error reading raw value: %v", err)
+		}
+		if !bytes.Equal(raw, value) {
+			t.Fatalf("Expected raw value to be stored verbatim")
+		}
+		meta, err := os.ReadFile(cache.Filepath(key))
+		if err != nil {
+			t.Fatalf("Error reading metadata: %v", err)
+		}
+		if bytes.Contains(meta, value) {
+			t.Fatalf("Expected metadata to not contain the value")
+		}
+	})
+
+	t.Run("TestGet", func(t *testing.T) {
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected cache value to round trip")
+		}
+		// A cache without raw values reads raw entries.
+		plain, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		got, err = plain.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected cache value to round trip")
+		}
+	})
+
+	t.Run("TestTouch", func(t *testing.T) {
+		err := cache.Touch(key, 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error touching cache: %v", err)
+		}
+		raw, err := os.ReadFile(cache.ValueFilepath(key))
+		if err != nil {
+			t.Fatalf("Error reading raw value: %v", err)
+		}
+		if !bytes.Equal(raw, value) {
+			t.Fatalf("Expected raw value to be stored verbatim")
+		}
+	})
+
+	t.Run("TestRemove", func(t *testing.T) {
+		err := cache.Remove(key)
+		if err != nil {
+			t.Fatalf("Error deleting cache: %v", err)
+		}
+		if _, err := os.Stat(cache.ValueFilepath(key)); !os.IsNotExist(err) {
+			t.Fatalf("Expected sidecar to be removed")
+		}
+	})
+
+	t.Run("TestConflictingOptions", func(t *testing.T) {
+		_, err := diskcache.New(cacheDir, diskcache.WithRawValues(), diskcache.WithCompression(diskcache.Gzip))
+		if err == nil {
+			t.Fatalf("Expected error for compressed raw values, but got nil")
+		}
+	})
+}
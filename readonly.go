@@ -0,0 +1,28 @@
+package diskcache
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// ErrReadOnly is returned when writing to or removing from a read-only cache.
+var ErrReadOnly = errors.New("cache is read-only")
+
+// WithReadOnly opens an existing cache directory for reading only.
+// Get, Read, List, Has, and other reads work as usual.
+// Flush, Clean, and Delete return an error wrapping ErrReadOnly,
+// as do Set, Remove, and other operations that write or remove an entry.
+// New doesn't create the directory, and the cache can't have a janitor.
+// Namespaces of a read-only cache are read-only.
+// Read-only mode is enforced by the cache, not the operating system,
+// so other processes can still change the directory.
+func WithReadOnly() Option {
+	return func(c *Cache) {
+		c.readOnly = true
+	}
+}
+
+// errReadOnly returns an error wrapping ErrReadOnly for an operation on a path.
+func errReadOnly(op string, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: ErrReadOnly}
+}
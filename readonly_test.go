@@ -0,0 +1,124 @@
+package diskcache_test
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestReadOnly(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	built, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = built.Set("key", []byte("value"), diskcache.NoExpiry)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = built.Set("expired", []byte("value"), time.Nanosecond)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	ns, err := built.Namespace("users")
+	if err != nil {
+		t.Fatalf("Error creating namespace: %v", err)
+	}
+	err = ns.Set("user", []byte("alice"), diskcache.NoExpiry)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	cache, err := diskcache.New(cacheDir, diskcache.WithReadOnly())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestRead", func(t *testing.T) {
+		got, err := cache.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", got)
+		}
+		if !cache.Has("key") {
+			t.Fatalf("Expected cache to have key")
+		}
+		list, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(list))
+		}
+	})
+
+	t.Run("TestWrite", func(t *testing.T) {
+		err := cache.Set("key", []byte("other"), diskcache.NoExpiry)
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		err = cache.Remove("key")
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		err = cache.Flush()
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		err = cache.Clean()
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		err = cache.Delete()
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		list, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(list))
+		}
+	})
+
+	t.Run("TestNamespace", func(t *testing.T) {
+		users, err := cache.Namespace("users")
+		if err != nil {
+			t.Fatalf("Error creating namespace: %v", err)
+		}
+		got, err := users.Get("user")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "alice" {
+			t.Fatalf("Expected cache value to be alice, got %s", got)
+		}
+		err = users.Set("user", []byte("bob"), diskcache.NoExpiry)
+		if !errors.Is(err, diskcache.ErrReadOnly) {
+			t.Fatalf("Expected ErrReadOnly, got %v", err)
+		}
+		_, err = cache.Namespace("missing")
+		if err == nil {
+			t.Fatalf("Expected error for missing namespace")
+		}
+	})
+
+	t.Run("TestMissingDir", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "missing"), diskcache.WithReadOnly())
+		if err == nil {
+			t.Fatalf("Expected error for missing directory")
+		}
+	})
+
+	t.Run("TestJanitor", func(t *testing.T) {
+		_, err := diskcache.New(cacheDir, diskcache.WithReadOnly(), diskcache.WithCleanupInterval(time.Minute))
+		if err == nil {
+			t.Fatalf("Expected error for read-only cache with janitor")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package diskcache
+
+import "fmt"
+
+// record is the on-disk form of a cache entry.
+// It embeds the entry data so the JSON fields of entries
+// written before records existed still unmarshal.
+// The other fields describe how the value is encoded.
+type record struct {
+	Data
+	Compression Compression `json:",omitempty"`
+	Nonce       []byte      `json:",omitempty"`
+	Sealed      []byte      `json:",omitempty"`
+	// Raw is true if the value is stored verbatim in a sidecar file.
+	Raw bool `json:",omitempty"`
+	// Checksum is the hex-encoded SHA-256 hash of the decoded value.
+	// Encrypted entries have no checksum because AES-GCM authenticates them.
+	Checksum string `json:",omitempty"`
+	// Version is the schema version of the record.
+	// Records written before versions were recorded are version 0.
+	Version int `json:",omitempty"`
+}
+
+// encode converts a cache entry to its on-disk form.
+func (c Cache) encode(entry Data) (record, error) {
+	rec := record{Data: entry, Version: schemaVersion}
+	if c.aead == nil {
+		rec.Checksum = checksum(entry.Value)
+	}
+	if c.compression != CompressionNone {
+		value, err := compress(c.compression, rec.Value)
+		if err != nil {
+			return record{}, fmt.Errorf("error compressing value: %w", err)
+		}
+		rec.Value = value
+		rec.Compression = c.compression
+	}
+	if c.aead != nil {
+		var err error
+		rec, err = c.encrypt(rec)
+		if err != nil {
+			return record{}, fmt.Errorf("error encrypting entry: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+// decode converts an on-disk record to a cache entry.
+// It decodes the value according to the record, not the cache options,
+// so caches with mixed encodings still read correctly.
+func (c Cache) decode(rec record) (Data, error) {
+	if rec.Sealed != nil {
+		var err error
+		rec, err = c.decrypt(rec)
+		if err != nil {
+			return Data{}, fmt.Errorf("error decrypting entry: %w", err)
+		}
+	}
+	entry := rec.Data
+	if rec.Compression != CompressionNone {
+		value, err := decompress(rec.Compression, entry.Value)
+		if err != nil {
+			return Data{}, fmt.Errorf("error decompressing value: %w", err)
+		}
+		entry.Value = value
+	}
+	if c.verify && rec.Checksum != "" && rec.Checksum != checksum(entry.Value) {
+		return Data{}, fmt.Errorf("%w: checksum mismatch for key %q", ErrCorrupt, entry.Key)
+	}
+	return entry, nil
+}
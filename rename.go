@@ -0,0 +1,143 @@
+package diskcache
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Rename moves a cache entry to a new key, keeping its value, expiry,
+// metadata, and creation time, and replacing any entry with the new key.
+// Rename locks both keys, so it isn't interleaved with other Rename,
+// Copy, CAS, Increment, and Append calls on the cache, its copies,
+// and, if the cache supports Lock, other processes.
+// Holding Lock on either key in the same process would deadlock.
+// Rename writes the new entry and removes the old one in a single commit,
+// like Tx, so reads from the cache and its copies see the entry under
+// exactly one of the keys, and, with WithJournal, a crash leaves it
+// under one of them.
+// The only exception is an entry whose value is in a sidecar file,
+// saved with SetReader or WithRawValues, which is written under
+// the new key before it's removed under the old one.
+func (c Cache) Rename(oldKey string, newKey string) error {
+	if len(oldKey) == 0 || len(newKey) == 0 {
+		return fmt.Errorf("key cannot be empty")
+	}
+	unlock, err := c.lockEntries(oldKey, newKey)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	oldFilename := c.Filename(oldKey)
+	rec, err := c.readDecoded(oldFilename)
+	if err != nil {
+		return err
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	rec.Key = newKey
+	newFilename := c.Filename(newKey)
+	err = c.keepVersion(newFilename)
+	if err != nil {
+		return fmt.Errorf("error keeping version: %w", err)
+	}
+	if rec.Raw || c.rawValues {
+		err = c.writeRecord(rec)
+		if err != nil {
+			return err
+		}
+		err = c.removeFile(oldFilename)
+		if err != nil {
+			return err
+		}
+		c.index.remove(oldKey)
+	} else {
+		encoded, err := c.encode(rec.Data)
+		if err != nil {
+			return err
+		}
+		contents, err := c.marshal(encoded)
+		if err != nil {
+			return err
+		}
+		ops := []txOp{{key: newKey, contents: contents, entry: rec.Data}, {key: oldKey}}
+		undos := []txUndo{{filename: newFilename}, {filename: oldFilename}}
+		err = c.apply(ops, undos)
+		if err != nil {
+			return err
+		}
+		unlockFiles := c.files.lock(oldFilename)
+		err = c.removeVersions(oldFilename)
+		unlockFiles()
+		if err != nil {
+			return err
+		}
+	}
+	c.log(slog.LevelDebug, "renamed entry", "key", oldKey, "new_key", newKey)
+	return nil
+}
+
+// Copy saves a copy of a cache entry under a new key,
+// replacing any entry with the new key.
+// The copy has the value, expiry, and metadata of the entry,
+// or, if a duration is given, expires after it instead.
+// A duration of NoExpiry makes the copy never expire.
+// It returns an error if the entry doesn't exist or is expired.
+// Copy is atomic with respect to other Copy, Rename, CAS, Increment,
+// and Append calls on the cache, its copies, and, if the cache
+// supports Lock, other processes.
+// Holding Lock on either key in the same process would deadlock.
+func (c Cache) Copy(srcKey string, dstKey string, duration ...time.Duration) error {
+	if len(srcKey) == 0 || len(dstKey) == 0 {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if len(duration) > 1 {
+		return fmt.Errorf("too many durations: %d", len(duration))
+	}
+	unlock, err := c.lockEntries(srcKey, dstKey)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	rec, err := c.readDecoded(c.Filename(srcKey))
+	if err != nil {
+		return err
+	}
+	now := c.now()
+	if expired(rec.Expiry, now) {
+		return ErrExpired
+	}
+	if srcKey == dstKey && len(duration) == 0 {
+		return nil
+	}
+	rec.Key = dstKey
+	rec.CreatedAt = now
+	rec.LastAccessed = time.Time{}
+	if len(duration) == 1 {
+		rec.Expiry = c.expiryFrom(now, duration[0])
+	}
+	err = c.keepVersion(c.Filename(dstKey))
+	if err != nil {
+		return fmt.Errorf("error keeping version: %w", err)
+	}
+	err = c.writeRecord(rec)
+	if err != nil {
+		return err
+	}
+	err = c.evict(dstKey)
+	if err != nil {
+		return fmt.Errorf("error evicting entries: %w", err)
+	}
+	return nil
+}
+
+// writeRecord saves a decoded cache entry with its creation time,
+// in a sidecar file if the record was or the cache stores raw values.
+func (c Cache) writeRecord(rec record) error {
+	if rec.Raw && !c.rawValues {
+		return c.writeRaw(rec.Data, bytes.NewReader(rec.Value))
+	}
+	return c.write(rec.Data)
+}
@@ -0,0 +1,240 @@
+package diskcache_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestRename(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithIndex())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestRename", func(t *testing.T) {
+		err := cache.SetWithMeta("v1:user", []byte("alice"), map[string]string{"type": "user"}, 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		before, err := cache.Read("v1:user")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		err = cache.Rename("v1:user", "v2:user")
+		if err != nil {
+			t.Fatalf("Error renaming entry: %v", err)
+		}
+		if cache.Has("v1:user") {
+			t.Fatalf("Expected old key to be removed")
+		}
+		after, err := cache.Read("v2:user")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		if after.Key != "v2:user" || string(after.Value) != "alice" || after.Meta["type"] != "user" {
+			t.Fatalf("Expected renamed entry, got %+v", after)
+		}
+		if !after.Expiry.Equal(before.Expiry) {
+			t.Fatalf("Expected expiry %s, got %s", before.Expiry, after.Expiry)
+		}
+	})
+
+	t.Run("TestReplace", func(t *testing.T) {
+		for key, value := range map[string]string{"src": "new", "dst": "old"} {
+			err := cache.Set(key, []byte(value), diskcache.NoExpiry)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		err := cache.Rename("src", "dst")
+		if err != nil {
+			t.Fatalf("Error renaming entry: %v", err)
+		}
+		got, err := cache.Get("dst")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "new" {
+			t.Fatalf("Expected cache value to be new, got %s", got)
+		}
+	})
+
+	t.Run("TestSidecar", func(t *testing.T) {
+		err := cache.SetReader("stream", strings.NewReader("streamed"), diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.Rename("stream", "stream2")
+		if err != nil {
+			t.Fatalf("Error renaming entry: %v", err)
+		}
+		r, err := cache.GetReader("stream2")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Error reading value: %v", err)
+		}
+		if string(got) != "streamed" {
+			t.Fatalf("Expected cache value to be streamed, got %s", got)
+		}
+	})
+
+	t.Run("TestMissing", func(t *testing.T) {
+		err := cache.Rename("missing", "other")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected ErrNotExist, got %v", err)
+		}
+		err = cache.Rename("dst", "")
+		if err == nil {
+			t.Fatalf("Expected error for empty key")
+		}
+	})
+
+	t.Run("TestAtomic", func(t *testing.T) {
+		err := cache.Set("tick", []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for j := range 50 {
+				from, to := "tick", "tock"
+				if j%2 == 1 {
+					from, to = to, from
+				}
+				err := cache.Rename(from, to)
+				if err != nil {
+					t.Errorf("Error renaming entry: %v", err)
+					return
+				}
+			}
+		}()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			err := cache.View(func(c diskcache.Cache) error {
+				if c.Has("tick") == c.Has("tock") {
+					return errors.New("expected the entry under exactly one key")
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Error viewing cache: %v", err)
+			}
+		}
+	})
+
+	t.Run("TestSeparateCaches", func(t *testing.T) {
+		// Caches opened separately share only the directory,
+		// like caches in different processes.
+		other, err := diskcache.New(cacheDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("ping", []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		caches := []diskcache.Cache{cache, other}
+		var wg sync.WaitGroup
+		for i := range 20 {
+			wg.Add(1)
+			go func(c diskcache.Cache) {
+				defer wg.Done()
+				for j := range 10 {
+					from, to := "ping", "pong"
+					if j%2 == 1 {
+						from, to = to, from
+					}
+					err := c.Rename(from, to)
+					if err != nil && !errors.Is(err, fs.ErrNotExist) {
+						t.Errorf("Error renaming entry: %v", err)
+						return
+					}
+				}
+			}(caches[i%2])
+		}
+		wg.Wait()
+		if cache.Has("ping") == cache.Has("pong") {
+			t.Fatalf("Expected the entry under exactly one key")
+		}
+	})
+}
+
+func TestCopy(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.SetWithMeta("staging", []byte("v2"), map[string]string{"build": "42"}, 1*time.Hour)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestCopy", func(t *testing.T) {
+		err := cache.Copy("staging", "current")
+		if err != nil {
+			t.Fatalf("Error copying entry: %v", err)
+		}
+		src, err := cache.Read("staging")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		dst, err := cache.Read("current")
+		if err != nil {
+			t.Fatalf("Error reading cache: %v", err)
+		}
+		if dst.Key != "current" || string(dst.Value) != "v2" || dst.Meta["build"] != "42" {
+			t.Fatalf("Expected copied entry, got %+v", dst)
+		}
+		if !dst.Expiry.Equal(src.Expiry) {
+			t.Fatalf("Expected expiry %s, got %s", src.Expiry, dst.Expiry)
+		}
+	})
+
+	t.Run("TestDuration", func(t *testing.T) {
+		err := cache.Copy("staging", "current", diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error copying entry: %v", err)
+		}
+		if !cache.Expiry("current").IsZero() {
+			t.Fatalf("Expected no expiry, got %s", cache.Expiry("current"))
+		}
+		err = cache.Copy("staging", "current", 1*time.Minute, 2*time.Minute)
+		if err == nil {
+			t.Fatalf("Expected error for too many durations")
+		}
+	})
+
+	t.Run("TestExpired", func(t *testing.T) {
+		err := cache.Set("expired", []byte("value"), -1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.Copy("expired", "other")
+		if err == nil {
+			t.Fatalf("Expected error copying expired entry")
+		}
+		err = cache.Copy("missing", "other")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected ErrNotExist, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package diskcache
+
+import "path/filepath"
+
+// shardWidth is the number of hash characters in each shard subdirectory name.
+const shardWidth = 2
+
+// shardLevels is the number of shard subdirectories above each entry file.
+const shardLevels = 2
+
+// WithSharding stores entry files in shard subdirectories
+// named after the first bytes of the hash, such as aa/bb/<hash>.json,
+// to keep directories small in large caches.
+// List, Clean, and Flush walk the shard subdirectories.
+// Sharded and unsharded caches can't share a directory.
+func WithSharding() Option {
+	return func(c *Cache) {
+		c.sharded = true
+	}
+}
+
+// shardPath returns the filename prefixed with its shard subdirectories.
+// Filenames that don't start with enough hex characters aren't sharded
+// and stay in the cache directory.
+func shardPath(filename string) string {
+	if len(filename) < shardLevels*shardWidth {
+		return filename
+	}
+	for i := range shardLevels {
+		if !isShard(filename[i*shardWidth : (i+1)*shardWidth]) {
+			return filename
+		}
+	}
+	parts := make([]string, 0, shardLevels+1)
+	for i := range shardLevels {
+		parts = append(parts, filename[i*shardWidth:(i+1)*shardWidth])
+	}
+	parts = append(parts, filename)
+	return filepath.Join(parts...)
+}
+
+// isShard returns true if the name looks like a shard subdirectory.
+func isShard(name string) bool {
+	if len(name) != shardWidth {
+		return false
+	}
+	for _, r := range name {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package diskcache_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestSharding(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithSharding())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+
+	t.Run("TestFilename", func(t *testing.T) {
+		key := "testkey"
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(key)))
+		want := path.Join(hash[0:2], hash[2:4], hash+".json")
+		got := cache.Filename(key)
+		if got != want {
+			t.Fatalf("Want filename to be %s, got %s", want, got)
+		}
+	})
+
+	t.Run("TestSetGet", func(t *testing.T) {
+		key := "testkey"
+		err := cache.Set(key, []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		if _, err := os.Stat(cache.Filepath(key)); err != nil {
+			t.Fatalf("Expected sharded file to exist: %v", err)
+		}
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", string(got))
+		}
+	})
+
+	t.Run("TestListCleanFlush", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			expiry := 1 * time.Minute
+			if i%2 == 0 {
+				expiry = -1 * time.Minute
+			}
+			err := cache.Set(fmt.Sprintf("key%d", i), []byte("value"), expiry)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		// A namespace is not a shard.
+		ns, err := cache.Namespace("users")
+		if err != nil {
+			t.Fatalf("Error creating namespace: %v", err)
+		}
+		err = ns.Set("key", []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+
+		data, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(data) != 11 {
+			t.Fatalf("Expected 11 keys, got %d", len(data))
+		}
+		err = cache.Clean()
+		if err != nil {
+			t.Fatalf("Error cleaning cache: %v", err)
+		}
+		data, err = cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(data) != 6 {
+			t.Fatalf("Expected 6 keys, got %d", len(data))
+		}
+		err = cache.Flush()
+		if err != nil {
+			t.Fatalf("Error flushing cache: %v", err)
+		}
+		data, err = cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(data) != 0 {
+			t.Fatalf("Expected 0 keys, got %d", len(data))
+		}
+		if !ns.Has("key") {
+			t.Fatalf("Expected namespace to keep its keys")
+		}
+	})
+
+	t.Run("TestShardNamespace", func(t *testing.T) {
+		_, err := cache.Namespace("ab")
+		if err == nil {
+			t.Fatalf("Expected error for shard-like namespace, but got nil")
+		}
+	})
+}
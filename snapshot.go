@@ -0,0 +1,94 @@
+package diskcache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Snapshot copies the cache entries to a directory,
+// which is created if it doesn't exist, keeping their filenames.
+// The copy can be opened with New and the same options as the cache.
+// It doesn't copy namespaces.
+// Snapshot is safe while writers are active. Like View, it waits for
+// transactions and holds off new ones until it finishes, so it copies
+// all or none of the operations of each transaction.
+// Each entry is copied with its sidecar and previous versions
+// either before or after each write of it in the same process.
+// Entries removed during the snapshot are skipped.
+func (c Cache) Snapshot(destDir string) error {
+	if len(destDir) == 0 {
+		return fmt.Errorf("directory path is empty")
+	}
+	err := c.fsys.MkdirAll(destDir, c.dirMode)
+	if err != nil {
+		return fmt.Errorf("error creating snapshot directory: %w", err)
+	}
+	dst := c.newDirStore(destDir)
+	return c.View(func(c Cache) error {
+		filenames, err := c.readDir()
+		if err != nil {
+			return fmt.Errorf("error reading directory: %w", err)
+		}
+		for _, filename := range filenames {
+			err := c.snapshotEntry(dst, filename)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("error copying entry %s: %w", filename, err)
+			}
+		}
+		return nil
+	})
+}
+
+// snapshotEntry copies a cache entry file, its sidecar, if any,
+// and its previous versions to a store.
+// It holds the files of the entry locked, so they aren't replaced
+// while they're copied.
+func (c Cache) snapshotEntry(dst Store, filename string) error {
+	unlock := c.files.lock(filename)
+	defer unlock()
+	err := c.snapshotFile(dst, filename)
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= c.versions; i++ {
+		err := c.snapshotFile(dst, c.versionName(filename, i))
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotFile copies an entry file and its sidecar, if any, to a store,
+// the sidecar first, as writeRaw writes them.
+func (c Cache) snapshotFile(dst Store, filename string) error {
+	contents, err := c.readBlob(filename)
+	if err != nil {
+		return err
+	}
+	err = copyBlob(dst, c.store, c.sidecar(filename))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	_, err = dst.WriteEntry(filename, bytes.NewReader(contents))
+	return err
+}
+
+// copyBlob copies the named blob from one store to another.
+func copyBlob(dst Store, src Store, name string) error {
+	r, err := src.ReadEntry(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = dst.WriteEntry(name, r)
+	return err
+}
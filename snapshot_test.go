@@ -0,0 +1,194 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestSnapshot(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithSharding())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for i := range 10 {
+		err := cache.Set(fmt.Sprintf("key%d", i), []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	err = cache.SetReader("stream", strings.NewReader("streamed"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestCopy", func(t *testing.T) {
+		snapshotDir := path.Join(t.TempDir(), "snapshot")
+		err := cache.Snapshot(snapshotDir)
+		if err != nil {
+			t.Fatalf("Error taking snapshot: %v", err)
+		}
+		snapshot, err := diskcache.New(snapshotDir, diskcache.WithSharding())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		list, err := snapshot.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 11 {
+			t.Fatalf("Expected 11 entries, got %d", len(list))
+		}
+		got, err := snapshot.Get("stream")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "streamed" {
+			t.Fatalf("Expected cache value to be streamed, got %s", got)
+		}
+	})
+
+	t.Run("TestConcurrentWrites", func(t *testing.T) {
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_ = cache.SetReader("stream", strings.NewReader(fmt.Sprintf("streamed %d", i)), 1*time.Minute)
+				_ = cache.Set(fmt.Sprintf("key%d", i%10), []byte(fmt.Sprintf("value %d", i)), 1*time.Minute)
+			}
+		}()
+		snapshotDir := path.Join(t.TempDir(), "snapshot")
+		err := cache.Snapshot(snapshotDir)
+		close(stop)
+		wg.Wait()
+		if err != nil {
+			t.Fatalf("Error taking snapshot: %v", err)
+		}
+		snapshot, err := diskcache.New(snapshotDir, diskcache.WithSharding(), diskcache.WithVerify())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = snapshot.VerifyAll()
+		if err != nil {
+			t.Fatalf("Error verifying snapshot: %v", err)
+		}
+		got, err := snapshot.Get("stream")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.HasPrefix(got, []byte("streamed")) {
+			t.Fatalf("Expected cache value to be streamed, got %s", got)
+		}
+	})
+}
+
+func TestSnapshotVersions(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithVersions(2))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for _, value := range []string{"v1", "v2", "v3"} {
+		err := cache.Set("key", []byte(value), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	snapshotDir := path.Join(t.TempDir(), "snapshot")
+	err = cache.Snapshot(snapshotDir)
+	if err != nil {
+		t.Fatalf("Error taking snapshot: %v", err)
+	}
+	snapshot, err := diskcache.New(snapshotDir, diskcache.WithVersions(2))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for n, want := range []string{"v3", "v2", "v1"} {
+		got, err := snapshot.ReadVersion("key", n)
+		if err != nil {
+			t.Fatalf("Error reading version %d: %v", n, err)
+		}
+		if string(got.Value) != want {
+			t.Fatalf("Expected version %d to be %s, got %s", n, want, got.Value)
+		}
+	}
+}
+
+func TestSnapshotTx(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	keys := []string{"a", "b", "c", "d"}
+	setAll := func(value string) error {
+		return cache.Tx(func(tx *diskcache.Txn) error {
+			for _, key := range keys {
+				err := tx.Set(key, []byte(value), 1*time.Minute)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	err = setAll("0")
+	if err != nil {
+		t.Fatalf("Error committing transaction: %v", err)
+	}
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_ = setAll(fmt.Sprint(i))
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+	for range 20 {
+		snapshotDir := path.Join(t.TempDir(), "snapshot")
+		err := cache.Snapshot(snapshotDir)
+		if err != nil {
+			t.Fatalf("Error taking snapshot: %v", err)
+		}
+		snapshot, err := diskcache.New(snapshotDir)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		var values []string
+		for _, key := range keys {
+			value, err := snapshot.Get(key)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			values = append(values, string(value))
+		}
+		if slices.ContainsFunc(values, func(v string) bool { return v != values[0] }) {
+			t.Fatalf("Expected the values of one transaction, got %v", values)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package diskcache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of cache statistics.
+// The counters accumulate from when the cache was created
+// and are shared by all copies of the cache.
+type Stats struct {
+	// Hits is the number of Get calls that returned a value.
+	Hits int64
+	// Misses is the number of Get calls that found no entry.
+	Misses int64
+	// ExpiredHits is the number of Get calls that found an expired entry.
+	ExpiredHits int64
+	// Writes is the number of entries written.
+	Writes int64
+	// Removals is the number of entries removed.
+	Removals int64
+	// Evictions is the number of entries evicted to stay within the maximum entry count.
+	Evictions int64
+	// BytesWritten is the number of bytes written to disk.
+	BytesWritten int64
+	// BytesRead is the number of bytes read from disk.
+	BytesRead int64
+	// Cleans is the number of times Clean ran, including by the janitor.
+	Cleans int64
+	// CleanDuration is the total time spent in Clean.
+	CleanDuration time.Duration
+	// Entries is the current number of entries.
+	Entries int64
+	// Size is the current size of all entries in bytes.
+	Size int64
+}
+
+// stats holds the cache counters.
+// A nil stats is valid and all its methods are no-ops.
+type stats struct {
+	hits         atomic.Int64
+	misses       atomic.Int64
+	expiredHits  atomic.Int64
+	writes       atomic.Int64
+	removals     atomic.Int64
+	evictions    atomic.Int64
+	bytesWritten atomic.Int64
+	bytesRead    atomic.Int64
+	cleans       atomic.Int64
+	cleanNanos   atomic.Int64
+}
+
+// Stats returns a snapshot of the cache statistics.
+// It counts the current entries and their size from the index, if any,
+// or from the directory.
+func (c Cache) Stats() (Stats, error) {
+	entries, size, err := c.usage()
+	if err != nil {
+		return Stats{}, err
+	}
+	s := c.stats.snapshot()
+	s.Entries = entries
+	s.Size = size
+	return s, nil
+}
+
+// Size returns the total size in bytes of the cache entries, including sidecars.
+// It reads the size from the index, if any, or from the directory.
+// It doesn't include namespaces; see NamespaceSizes.
+func (c Cache) Size() (int64, error) {
+	_, size, err := c.usage()
+	return size, err
+}
+
+// usage returns the number of entries and their total size in bytes.
+func (c Cache) usage() (int64, int64, error) {
+	if c.index != nil {
+		return c.index.usage()
+	}
+	sizes, err := c.sizes()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading directory: %w", err)
+	}
+	var size int64
+	for _, n := range sizes {
+		size += n
+	}
+	return int64(len(sizes)), size, nil
+}
+
+// snapshot returns the current counters.
+func (s *stats) snapshot() Stats {
+	if s == nil {
+		return Stats{}
+	}
+	return Stats{
+		Hits:          s.hits.Load(),
+		Misses:        s.misses.Load(),
+		ExpiredHits:   s.expiredHits.Load(),
+		Writes:        s.writes.Load(),
+		Removals:      s.removals.Load(),
+		Evictions:     s.evictions.Load(),
+		BytesWritten:  s.bytesWritten.Load(),
+		BytesRead:     s.bytesRead.Load(),
+		Cleans:        s.cleans.Load(),
+		CleanDuration: time.Duration(s.cleanNanos.Load()),
+	}
+}
+
+// hit records a Get that returned a value.
+func (s *stats) hit() {
+	if s == nil {
+		return
+	}
+	s.hits.Add(1)
+}
+
+// miss records a Get that found no entry.
+func (s *stats) miss() {
+	if s == nil {
+		return
+	}
+	s.misses.Add(1)
+}
+
+// expiredHit records a Get that found an expired entry.
+func (s *stats) expiredHit() {
+	if s == nil {
+		return
+	}
+	s.expiredHits.Add(1)
+}
+
+// write records an entry written to disk.
+func (s *stats) write(n int) {
+	if s == nil {
+		return
+	}
+	s.writes.Add(1)
+	s.bytesWritten.Add(int64(n))
+}
+
+// read records bytes read from disk.
+func (s *stats) read(n int) {
+	if s == nil {
+		return
+	}
+	s.bytesRead.Add(int64(n))
+}
+
+// remove records entries removed from disk.
+func (s *stats) remove(n int) {
+	if s == nil {
+		return
+	}
+	s.removals.Add(int64(n))
+}
+
+// evict records an entry evicted from disk.
+func (s *stats) evict() {
+	if s == nil {
+		return
+	}
+	s.evictions.Add(1)
+}
+
+// clean records a Clean run that took the given duration.
+func (s *stats) clean(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.cleans.Add(1)
+	s.cleanNanos.Add(int64(d))
+}
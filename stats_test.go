@@ -0,0 +1,78 @@
+package diskcache_test
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestStats(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []diskcache.Option
+	}{
+		{"TestUnindexed", nil},
+		{"TestIndexed", []diskcache.Option{diskcache.WithIndex()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, tc.options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+
+			err = cache.Set("fresh", []byte("value"), 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			err = cache.Set("stale", []byte("value"), -1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			err = cache.Set("removed", []byte("value"), 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			err = cache.Remove("removed")
+			if err != nil {
+				t.Fatalf("Error deleting cache: %v", err)
+			}
+			_, _ = cache.Get("fresh")
+			_, _ = cache.Get("fresh")
+			_, _ = cache.Get("stale")
+			_, _ = cache.Get("missing")
+
+			stats, err := cache.Stats()
+			if err != nil {
+				t.Fatalf("Error getting stats: %v", err)
+			}
+			for _, tc := range []struct {
+				name string
+				got  int64
+				want int64
+			}{
+				{"hits", stats.Hits, 2},
+				{"misses", stats.Misses, 1},
+				{"expired hits", stats.ExpiredHits, 1},
+				{"writes", stats.Writes, 3},
+				{"removals", stats.Removals, 1},
+				{"entries", stats.Entries, 2},
+			} {
+				if tc.got != tc.want {
+					t.Errorf("Want %s to be %d, got %d", tc.name, tc.want, tc.got)
+				}
+			}
+			if stats.BytesWritten == 0 {
+				t.Errorf("Expected bytes written to be non-zero")
+			}
+			if stats.BytesRead == 0 {
+				t.Errorf("Expected bytes read to be non-zero")
+			}
+			if stats.Size == 0 {
+				t.Errorf("Expected size to be non-zero")
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Store is the storage backend of a cache.
+// It stores named blobs, such as entry files and their sidecars,
+// and knows nothing about keys, expiry, or encoding.
+// Names are relative paths, such as aa/bb/<hash>.json in a sharded cache.
+// The default store keeps each blob in a file in the cache directory.
+// A Store must be safe for concurrent use.
+type Store interface {
+	// WriteEntry atomically writes the contents of r to the named blob,
+	// replacing it if it exists, and returns the number of bytes written.
+	// Readers must never observe a partially written blob.
+	WriteEntry(name string, r io.Reader) (int64, error)
+	// ReadEntry opens the named blob for reading.
+	// It returns an error wrapping fs.ErrNotExist if the blob doesn't exist.
+	ReadEntry(name string) (io.ReadCloser, error)
+	// RemoveEntry deletes the named blob.
+	// It returns an error wrapping fs.ErrNotExist if the blob doesn't exist.
+	RemoveEntry(name string) error
+	// ListEntries returns the names and sizes of all blobs.
+	ListEntries() ([]StoreEntry, error)
+}
+
+// StoreEntry is the name and size in bytes of a blob in a Store.
+type StoreEntry struct {
+	Name string
+	Size int64
+	// ModTime is when the blob was last written,
+	// or the zero time if the store doesn't know.
+	ModTime time.Time
+}
+
+// NewWithStore creates a new cache backed by a store instead of a directory.
+// It accepts options to configure the cache.
+// The cache has no directory, so Namespace and Watch aren't supported.
+func NewWithStore(store Store, options ...Option) (Cache, error) {
+	if store == nil {
+		return Cache{}, errors.New("store is nil")
+	}
+	return Cache{store: store, ext: defaultExt}.open(options)
+}
+
+// dirStore is the default store.
+// It keeps each blob in a file in a directory,
+// descending into shard subdirectories if the cache is sharded.
+type dirStore struct {
+	fsys     FS
+	dir      string
+	sharded  bool
+	fileMode fs.FileMode
+	dirMode  fs.FileMode
+	readOnly bool
+	// durable is whether writes are flushed to stable storage.
+	durable bool
+}
+
+// newDirStore returns a directory store for a directory
+// with the file system, sharding, and permissions of the cache.
+func (c Cache) newDirStore(dir string) dirStore {
+	return dirStore{
+		fsys:     c.fsys,
+		dir:      dir,
+		sharded:  c.sharded,
+		fileMode: c.fileMode,
+		dirMode:  c.dirMode,
+		durable:  c.sync,
+	}
+}
+
+// WriteEntry atomically writes the contents of r to a file,
+// creating its shard subdirectories, if any.
+// It returns ErrReadOnly if the store is read-only.
+func (s dirStore) WriteEntry(name string, r io.Reader) (int64, error) {
+	if s.readOnly {
+		return 0, errReadOnly("write", name)
+	}
+	path := filepath.Join(s.dir, name)
+	if filepath.Dir(name) != "." {
+		err := s.fsys.MkdirAll(filepath.Dir(path), s.dirMode)
+		if err != nil {
+			return 0, err
+		}
+		if s.durable {
+			err = s.syncParents(filepath.Dir(name))
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return writeFileAtomic(s.fsys, path, r, s.fileMode, s.durable)
+}
+
+// syncParents flushes the directories holding a subdirectory
+// of the store, up to the store directory,
+// so the subdirectory survives a crash if it was just created.
+func (s dirStore) syncParents(subdir string) error {
+	for ; subdir != "."; subdir = filepath.Dir(subdir) {
+		err := s.fsys.(SyncFS).Sync(filepath.Join(s.dir, filepath.Dir(subdir)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadEntry opens a file for reading.
+func (s dirStore) ReadEntry(name string) (io.ReadCloser, error) {
+	return s.fsys.Open(filepath.Join(s.dir, name))
+}
+
+// RemoveEntry deletes a file.
+// It returns ErrReadOnly if the store is read-only.
+func (s dirStore) RemoveEntry(name string) error {
+	if s.readOnly {
+		return errReadOnly("remove", name)
+	}
+	return s.fsys.Remove(filepath.Join(s.dir, name))
+}
+
+// ListEntries returns the files in the directory and,
+// if the store is sharded, in its shard subdirectories.
+// It skips other subdirectories, such as namespaces.
+func (s dirStore) ListEntries() ([]StoreEntry, error) {
+	entries, err := listFS(dirFS{fsys: s.fsys, dir: s.dir}, s.sharded)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Name = filepath.FromSlash(entries[i].Name)
+	}
+	return entries, nil
+}
+
+// listFS returns the files in the root of a file system and,
+// if it is sharded, in its shard subdirectories,
+// with slash-separated names.
+// It skips other subdirectories, such as namespaces.
+func listFS(fsys fs.FS, sharded bool) ([]StoreEntry, error) {
+	entries, err := readDirFS(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	if !sharded {
+		return entries, nil
+	}
+	prefixes := []string{"."}
+	for range shardLevels {
+		var next []string
+		for _, prefix := range prefixes {
+			dirEntries, err := fs.ReadDir(fsys, prefix)
+			if err != nil {
+				return nil, err
+			}
+			for _, dirEntry := range dirEntries {
+				if dirEntry.IsDir() && isShard(dirEntry.Name()) {
+					next = append(next, path.Join(prefix, dirEntry.Name()))
+				}
+			}
+		}
+		prefixes = next
+	}
+	for _, prefix := range prefixes {
+		names, err := readDirFS(fsys, prefix)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, names...)
+	}
+	return entries, nil
+}
+
+// readDirFS returns the files in a directory of a file system,
+// joined to its name.
+// Files removed while reading are skipped.
+func readDirFS(fsys fs.FS, dir string) ([]StoreEntry, error) {
+	dirEntries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []StoreEntry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, StoreEntry{
+			Name:    path.Join(dir, dirEntry.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// readEntry reads the named blob from the store,
+// waiting for any transaction being committed.
+func (c Cache) readEntry(name string) ([]byte, error) {
+	unlock := c.commits.rlock()
+	defer unlock()
+	return c.readBlob(name)
+}
+
+// readBlob reads the named blob from the store.
+func (c Cache) readBlob(name string) ([]byte, error) {
+	r, err := c.store.ReadEntry(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// removeEntryIfExists deletes the named blob from the store, if it exists.
+func (c Cache) removeEntryIfExists(name string) error {
+	err := c.store.RemoveEntry(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// dirStore returns the directory store of the cache.
+// It returns an error if the cache is backed by another store.
+func (c Cache) dirStore() (dirStore, error) {
+	s, ok := c.baseStore().(dirStore)
+	if !ok {
+		return dirStore{}, fmt.Errorf("cache has no directory")
+	}
+	return s, nil
+}
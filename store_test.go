@@ -0,0 +1,138 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+// memStore is an in-memory store.
+type memStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *memStore) WriteEntry(name string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[name] = data
+	return int64(len(data)), nil
+}
+
+func (s *memStore) ReadEntry(name string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memStore) RemoveEntry(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.blobs[name]; !ok {
+		return fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+	}
+	delete(s.blobs, name)
+	return nil
+}
+
+func (s *memStore) ListEntries() ([]diskcache.StoreEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []diskcache.StoreEntry
+	for name, data := range s.blobs {
+		entries = append(entries, diskcache.StoreEntry{Name: name, Size: int64(len(data))})
+	}
+	return entries, nil
+}
+
+func TestStore(t *testing.T) {
+	store := &memStore{blobs: make(map[string][]byte)}
+	cache, err := diskcache.NewWithStore(store, diskcache.WithSharding(), diskcache.WithIndex())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "testkey"
+	value := []byte("testvalue")
+	err = cache.Set(key, value, 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestGet", func(t *testing.T) {
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected cache value to be %s, got %s", value, got)
+		}
+		if _, ok := store.blobs[cache.Filename(key)]; !ok {
+			t.Fatalf("Expected store to contain %s", cache.Filename(key))
+		}
+	})
+
+	t.Run("TestReader", func(t *testing.T) {
+		err := cache.SetReader("stream", strings.NewReader("streamed"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		got, err := cache.Get("stream")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(got) != "streamed" {
+			t.Fatalf("Expected cache value to be streamed, got %s", got)
+		}
+	})
+
+	t.Run("TestReopen", func(t *testing.T) {
+		reopened, err := diskcache.NewWithStore(store, diskcache.WithSharding())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		list, err := reopened.List(diskcache.SortByKey)
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 2 || list[0].Key != "stream" || list[1].Key != key {
+			t.Fatalf("Expected list to contain stream and %s, got %v", key, list)
+		}
+	})
+
+	t.Run("TestUnsupported", func(t *testing.T) {
+		_, err := cache.Namespace("ns")
+		if err == nil {
+			t.Fatalf("Expected error creating namespace")
+		}
+		_, err = cache.Watch(context.Background())
+		if err == nil {
+			t.Fatalf("Expected error watching cache")
+		}
+	})
+
+	t.Run("TestFlush", func(t *testing.T) {
+		err := cache.Flush()
+		if err != nil {
+			t.Fatalf("Error flushing cache: %v", err)
+		}
+		if len(store.blobs) != 0 {
+			t.Fatalf("Expected store to be empty, got %d blobs", len(store.blobs))
+		}
+	})
+}
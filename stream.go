@@ -0,0 +1,79 @@
+package diskcache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SetReader saves a cache entry with a key, a value read from r, and a duration.
+// The value is streamed verbatim to a sidecar file next to the entry file,
+// so it is never buffered in memory or encoded inside the entry.
+// If the cache is encrypted or compressed, the value is read into memory
+// and saved with Set instead, so it is encoded like any other value.
+func (c Cache) SetReader(key string, r io.Reader, duration time.Duration) error {
+	// Validate the key.
+	if len(key) == 0 {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if c.aead != nil || c.compression != CompressionNone {
+		value, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("error reading value: %w", err)
+		}
+		return c.Set(key, value, duration)
+	}
+	unlock := c.lockVersions(key)
+	defer unlock()
+	err := c.keepVersion(c.Filename(key))
+	if err != nil {
+		return fmt.Errorf("error keeping version: %w", err)
+	}
+	now := c.now()
+	err = c.writeRaw(Data{Key: key, Expiry: c.expiryFrom(now, duration), CreatedAt: now}, r)
+	if err != nil {
+		return err
+	}
+	err = c.evict(key)
+	if err != nil {
+		return fmt.Errorf("error evicting entries: %w", err)
+	}
+	return nil
+}
+
+// GetReader gets a cache entry from disk and returns a reader for the value.
+// It returns an error if the entry is expired.
+// The caller must close the reader.
+// Values saved with SetReader are streamed from disk;
+// other values are read into memory first.
+func (c Cache) GetReader(key string) (io.ReadCloser, error) {
+	filename := c.Filename(key)
+	rec, err := c.readRecord(filename)
+	if err != nil {
+		c.stats.miss()
+		return nil, err
+	}
+	if expired(rec.Expiry, c.now()) {
+		c.stats.expiredHit()
+		return nil, ErrExpired
+	}
+	if !rec.Raw {
+		entry, err := c.decode(rec)
+		if err != nil {
+			c.stats.miss()
+			return nil, err
+		}
+		c.stats.hit()
+		c.recordAccess(key, rec.Data)
+		return io.NopCloser(bytes.NewReader(entry.Value)), nil
+	}
+	f, err := c.store.ReadEntry(c.sidecar(filename))
+	if err != nil {
+		c.stats.miss()
+		return nil, fmt.Errorf("error reading data: %w", err)
+	}
+	c.stats.hit()
+	c.recordAccess(key, rec.Data)
+	return f, nil
+}
@@ -0,0 +1,151 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestStream(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir)
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "stream"
+	value := bytes.Repeat([]byte("0123456789"), 10000)
+
+	t.Run("TestSetReader", func(t *testing.T) {
+		err := cache.SetReader(key, bytes.NewReader(value), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		r, err := cache.GetReader(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Error reading value: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected streamed value to round trip")
+		}
+		// The value is stored verbatim next to the entry file.
+		raw, err := os.ReadFile(cache.ValueFilepath(key))
+		if err != nil {
+			t.Fatalf("Error reading raw value: %v", err)
+		}
+		if !bytes.Equal(raw, value) {
+			t.Fatalf("Expected raw value to be stored verbatim")
+		}
+	})
+
+	t.Run("TestGet", func(t *testing.T) {
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected streamed value to round trip")
+		}
+		data, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(data) != 1 {
+			t.Fatalf("Expected 1 key, got %d", len(data))
+		}
+	})
+
+	t.Run("TestGetReaderInline", func(t *testing.T) {
+		err := cache.Set("inline", []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		r, err := cache.GetReader("inline")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Error reading value: %v", err)
+		}
+		if string(got) != "value" {
+			t.Fatalf("Expected cache value to be value, got %s", string(got))
+		}
+	})
+
+	t.Run("TestExpired", func(t *testing.T) {
+		err := cache.SetReader("expired", strings.NewReader("value"), -1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, err = cache.GetReader("expired")
+		if err == nil {
+			t.Fatalf("Expected error getting expired cache")
+		}
+	})
+
+	t.Run("TestOverwriteAndRemove", func(t *testing.T) {
+		err := cache.Set(key, []byte("small"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		sidecar := cache.ValueFilepath(key)
+		if _, err := os.Stat(sidecar); !os.IsNotExist(err) {
+			t.Fatalf("Expected sidecar to be removed on Set")
+		}
+		err = cache.SetReader(key, bytes.NewReader(value), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.Remove(key)
+		if err != nil {
+			t.Fatalf("Error deleting cache: %v", err)
+		}
+		if _, err := os.Stat(sidecar); !os.IsNotExist(err) {
+			t.Fatalf("Expected sidecar to be removed on Remove")
+		}
+		err = cache.Flush()
+		if err != nil {
+			t.Fatalf("Error flushing cache: %v", err)
+		}
+		entries, err := os.ReadDir(cacheDir)
+		if err != nil {
+			t.Fatalf("Error reading directory: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("Expected empty directory, got %d files", len(entries))
+		}
+	})
+
+	t.Run("TestEncrypted", func(t *testing.T) {
+		cache, err := diskcache.New(cacheDir, diskcache.WithEncryption(bytes.Repeat([]byte("k"), 32)))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.SetReader(key, bytes.NewReader(value), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		if _, err := os.Stat(cache.ValueFilepath(key)); !os.IsNotExist(err) {
+			t.Fatalf("Expected no sidecar in an encrypted cache")
+		}
+		got, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected streamed value, got %d bytes", len(got))
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// SyncOptions configures Sync.
+type SyncOptions struct {
+	// Bidirectional also copies the entries of the destination
+	// to the source, so both caches end up with the same entries.
+	// When both caches have an entry with different contents,
+	// the one that expires later is kept, then the newer one.
+	Bidirectional bool
+}
+
+// SyncReport describes the entries copied by Sync.
+type SyncReport struct {
+	// Copied is the keys of the entries copied to the destination.
+	Copied []string
+	// CopiedBack is the keys of the entries copied to the source.
+	CopiedBack []string
+}
+
+// Sync copies the unexpired entries of a cache that are missing or
+// different in another cache, such as a replica on a network share
+// or a backup volume, keeping their expiry and creation times.
+// The caches can have different options, since entries are copied decoded.
+// By default, the source replaces the entries of the destination that differ;
+// with Bidirectional, entries are copied both ways and the one that expires
+// last wins. Sync never removes entries.
+func Sync(src, dst Cache, opts SyncOptions) (SyncReport, error) {
+	var report SyncReport
+	seen := make(map[string]bool)
+	err := syncEntries(src, dst, opts.Bidirectional, seen, &report.Copied, &report.CopiedBack)
+	if err != nil {
+		return report, err
+	}
+	if opts.Bidirectional {
+		err = syncEntries(dst, src, true, seen, &report.CopiedBack, &report.Copied)
+		if err != nil {
+			return report, err
+		}
+	}
+	err = errors.Join(dst.evict(""), src.evict(""))
+	if err != nil {
+		return report, fmt.Errorf("error evicting entries: %w", err)
+	}
+	return report, nil
+}
+
+// syncEntries copies the unexpired entries of src that are missing or
+// different in dst, appending their keys to copied, and adds their keys
+// to seen, skipping the keys already in it.
+// If bidirectional is true, an entry of dst that wins over the entry
+// of src is copied to src instead, appending its key to copiedBack.
+func syncEntries(src, dst Cache, bidirectional bool, seen map[string]bool, copied, copiedBack *[]string) error {
+	filenames, err := src.filenames()
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+	now := src.now()
+	for _, filename := range filenames {
+		rec, err := src.readDecoded(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading entry: %w", err)
+		}
+		entry := rec.Data
+		if seen[entry.Key] || expired(entry.Expiry, now) {
+			continue
+		}
+		seen[entry.Key] = true
+		other, err := dst.readDecoded(dst.Filename(entry.Key))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return fmt.Errorf("error reading entry: %w", err)
+		case !expired(other.Expiry, now):
+			same, err := sameEntry(entry, other.Data)
+			if err != nil {
+				return err
+			}
+			if same {
+				continue
+			}
+			if bidirectional && !syncWins(entry, other.Data) {
+				err = src.write(other.Data)
+				if err != nil {
+					return fmt.Errorf("error copying %q: %w", entry.Key, err)
+				}
+				*copiedBack = append(*copiedBack, entry.Key)
+				continue
+			}
+		}
+		err = dst.write(entry)
+		if err != nil {
+			return fmt.Errorf("error copying %q: %w", entry.Key, err)
+		}
+		*copied = append(*copied, entry.Key)
+	}
+	return nil
+}
+
+// sameEntry returns true if two entries have the same contents,
+// ignoring when they were last read.
+func sameEntry(a, b Data) (bool, error) {
+	_, sumA, err := marshalRemote(a)
+	if err != nil {
+		return false, err
+	}
+	_, sumB, err := marshalRemote(b)
+	if err != nil {
+		return false, err
+	}
+	return sumA == sumB, nil
+}
+
+// syncWins returns true if an entry replaces another with the same key
+// in a bidirectional Sync: it expires later, with entries that never
+// expire last, or expires at the same time and was created later.
+func syncWins(entry, other Data) bool {
+	switch {
+	case entry.Expiry.Equal(other.Expiry):
+		return entry.CreatedAt.After(other.CreatedAt)
+	case entry.Expiry.IsZero():
+		return true
+	case other.Expiry.IsZero():
+		return false
+	default:
+		return entry.Expiry.After(other.Expiry)
+	}
+}
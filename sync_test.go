@@ -0,0 +1,104 @@
+package diskcache_test
+
+import (
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestSync(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newCache := func(options ...diskcache.Option) diskcache.Cache {
+		t.Helper()
+		options = append(options, diskcache.WithClock(clock))
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), options...)
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		return cache
+	}
+	set := func(cache diskcache.Cache, key string, value string, duration time.Duration) {
+		t.Helper()
+		err := cache.Set(key, []byte(value), duration)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	get := func(cache diskcache.Cache, key string) string {
+		t.Helper()
+		value, err := cache.Get(key)
+		if err != nil {
+			t.Fatalf("Error getting %s: %v", key, err)
+		}
+		return string(value)
+	}
+
+	t.Run("TestOneWay", func(t *testing.T) {
+		src := newCache()
+		dst := newCache(diskcache.WithSharding(), diskcache.WithCompression(diskcache.Gzip))
+		set(src, "new", "value", 1*time.Hour)
+		set(src, "updated", "new", 1*time.Minute)
+		set(src, "same", "value", 1*time.Hour)
+		set(src, "expired", "value", 1*time.Second)
+		set(dst, "updated", "old", 1*time.Hour)
+		set(dst, "only", "value", 1*time.Hour)
+		set(dst, "same", "value", 1*time.Hour)
+		clock.Advance(2 * time.Second)
+		report, err := diskcache.Sync(src, dst, diskcache.SyncOptions{})
+		if err != nil {
+			t.Fatalf("Error syncing caches: %v", err)
+		}
+		slices.Sort(report.Copied)
+		if !slices.Equal(report.Copied, []string{"new", "updated"}) || len(report.CopiedBack) != 0 {
+			t.Fatalf("Expected new and updated to be copied, got %+v", report)
+		}
+		if get(dst, "updated") != "new" || get(dst, "only") != "value" {
+			t.Fatalf("Expected the source to replace the destination")
+		}
+		if dst.Has("expired") || src.Has("only") {
+			t.Fatalf("Expected expired entries and the destination not to be copied")
+		}
+		_, ttl, err := dst.GetWithTTL("new")
+		if err != nil || ttl != 1*time.Hour-2*time.Second {
+			t.Fatalf("Expected the expiry to be kept, got %v, %v", ttl, err)
+		}
+	})
+
+	t.Run("TestBidirectional", func(t *testing.T) {
+		a := newCache()
+		b := newCache()
+		set(a, "a", "value", 1*time.Hour)
+		set(b, "b", "value", 1*time.Hour)
+		set(a, "later", "a", 2*time.Hour)
+		set(b, "later", "b", 1*time.Hour)
+		set(a, "forever", "a", 1*time.Hour)
+		set(b, "forever", "b", diskcache.NoExpiry)
+		report, err := diskcache.Sync(a, b, diskcache.SyncOptions{Bidirectional: true})
+		if err != nil {
+			t.Fatalf("Error syncing caches: %v", err)
+		}
+		slices.Sort(report.Copied)
+		slices.Sort(report.CopiedBack)
+		if !slices.Equal(report.Copied, []string{"a", "later"}) || !slices.Equal(report.CopiedBack, []string{"b", "forever"}) {
+			t.Fatalf("Unexpected report %+v", report)
+		}
+		for _, cache := range []diskcache.Cache{a, b} {
+			if get(cache, "a") != "value" || get(cache, "b") != "value" {
+				t.Fatalf("Expected both caches to have both entries")
+			}
+			if get(cache, "later") != "a" || get(cache, "forever") != "b" {
+				t.Fatalf("Expected the entries that expire last to win")
+			}
+		}
+		report, err = diskcache.Sync(a, b, diskcache.SyncOptions{Bidirectional: true})
+		if err != nil {
+			t.Fatalf("Error syncing caches: %v", err)
+		}
+		if len(report.Copied) != 0 || len(report.CopiedBack) != 0 {
+			t.Fatalf("Expected nothing to be copied, got %+v", report)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package diskcache
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tiered is a cache that keeps the most recently used entries of a Cache
+// in memory, so reading them again doesn't read the disk.
+// Set and Remove write through to the disk cache before updating
+// the memory, and an entry is dropped from memory when it expires
+// on disk, so both tiers agree on what Get returns and until when.
+// Reads served from memory skip the hooks, statistics, and access
+// tracking of the disk cache.
+// Writes made to the disk cache other than through the Tiered,
+// such as by another process, aren't seen until the entry
+// is dropped from memory by expiring, being evicted, or Invalidate.
+// A Tiered is safe for concurrent use.
+type Tiered struct {
+	disk       Cache
+	maxEntries int
+	// locks serializes the writes to each key.
+	locks *keyLocks
+
+	mu sync.Mutex
+	// lru holds the memory entries, most recently used first.
+	lru   *list.List
+	items map[string]*list.Element
+	// gen counts the writes, so a read from disk doesn't replace
+	// an entry written while it was reading.
+	gen uint64
+}
+
+// tieredEntry is an entry held in memory by a Tiered.
+type tieredEntry struct {
+	key    string
+	value  []byte
+	expiry time.Time
+}
+
+// NewTiered returns a Tiered in front of a disk cache that keeps
+// at most maxEntries entries in memory.
+func NewTiered(disk Cache, maxEntries int) (*Tiered, error) {
+	if maxEntries <= 0 {
+		return nil, fmt.Errorf("invalid max entries: %d", maxEntries)
+	}
+	return &Tiered{
+		disk:       disk,
+		maxEntries: maxEntries,
+		locks:      &keyLocks{locks: make(map[string]*keyLock)},
+		lru:        list.New(),
+		items:      make(map[string]*list.Element),
+	}, nil
+}
+
+// Disk returns the disk cache behind the Tiered.
+func (t *Tiered) Disk() Cache {
+	return t.disk
+}
+
+// Len returns the number of entries held in memory.
+func (t *Tiered) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lru.Len()
+}
+
+// Get returns the value of a cache entry from memory,
+// or else from disk, keeping it in memory for the next Get.
+// It returns an error if the entry is expired.
+func (t *Tiered) Get(key string) ([]byte, error) {
+	value, _, err := t.GetWithTTL(key)
+	return value, err
+}
+
+// GetWithTTL returns the value of a cache entry and its remaining lifetime,
+// from memory or else from disk, like Get.
+// The lifetime is NoExpiry if the entry never expires.
+func (t *Tiered) GetWithTTL(key string) ([]byte, time.Duration, error) {
+	now := t.disk.now()
+	entry, gen, ok := t.get(key, now)
+	if !ok {
+		done := t.disk.observe("Get", key)
+		data, read, err := t.disk.getEntry(key)
+		done(OperationResult{Bytes: len(data.Value), Hit: err == nil, Err: err})
+		if err != nil {
+			return nil, 0, err
+		}
+		entry = tieredEntry{key: key, value: data.Value, expiry: data.Expiry}
+		t.fill(entry, gen)
+		now = read
+	}
+	value := bytes.Clone(entry.value)
+	if entry.expiry.IsZero() {
+		return value, NoExpiry, nil
+	}
+	return value, entry.expiry.Sub(now), nil
+}
+
+// get returns the memory entry of a key, if it isn't expired,
+// and the write count to fill it from disk otherwise.
+func (t *Tiered) get(key string, now time.Time) (tieredEntry, uint64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	elem, ok := t.items[key]
+	if !ok {
+		return tieredEntry{}, t.gen, false
+	}
+	entry := elem.Value.(tieredEntry)
+	if expired(entry.expiry, now) {
+		t.remove(key)
+		return tieredEntry{}, t.gen, false
+	}
+	t.lru.MoveToFront(elem)
+	return entry, t.gen, true
+}
+
+// fill keeps an entry read from disk in memory,
+// unless there was a write since the read began.
+func (t *Tiered) fill(entry tieredEntry, gen uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.gen != gen {
+		return
+	}
+	t.put(entry)
+}
+
+// put keeps an entry in memory, evicting the least recently used
+// entries beyond the cap.
+// It must be called with the lock held.
+func (t *Tiered) put(entry tieredEntry) {
+	if elem, ok := t.items[entry.key]; ok {
+		elem.Value = entry
+		t.lru.MoveToFront(elem)
+	} else {
+		t.items[entry.key] = t.lru.PushFront(entry)
+	}
+	for t.lru.Len() > t.maxEntries {
+		t.remove(t.lru.Back().Value.(tieredEntry).key)
+	}
+}
+
+// remove drops an entry from memory.
+// It must be called with the lock held.
+func (t *Tiered) remove(key string) {
+	elem, ok := t.items[key]
+	if !ok {
+		return
+	}
+	t.lru.Remove(elem)
+	delete(t.items, key)
+}
+
+// Set saves a cache entry to disk and keeps it in memory.
+func (t *Tiered) Set(key string, value []byte, duration time.Duration) error {
+	// The expiry in memory is computed before the one on disk,
+	// so the entry never outlives its file.
+	expiry := t.disk.expiryFrom(t.disk.now(), duration)
+	return t.write(key, func() error {
+		return t.disk.Set(key, value, duration)
+	}, &tieredEntry{key: key, value: bytes.Clone(value), expiry: expiry})
+}
+
+// Remove deletes a cache entry from disk and memory.
+func (t *Tiered) Remove(key string) error {
+	return t.write(key, func() error {
+		return t.disk.Remove(key)
+	}, nil)
+}
+
+// Invalidate drops an entry from memory, so the next Get reads it from disk.
+func (t *Tiered) Invalidate(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.gen++
+	t.remove(key)
+}
+
+// write drops the entry of a key from memory while fn writes it to disk,
+// then, if fn succeeds and entry isn't nil, keeps entry in memory.
+// Reads from disk that overlap the write don't fill the memory,
+// since they may have read the entry from before it.
+func (t *Tiered) write(key string, fn func() error, entry *tieredEntry) error {
+	unlock := t.locks.lock(key)
+	defer unlock()
+	t.Invalidate(key)
+	err := fn()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.gen++
+	if err != nil || entry == nil {
+		return err
+	}
+	t.put(*entry)
+	return nil
+}
@@ -0,0 +1,148 @@
+package diskcache_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestTiered(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	opens := 0
+	fsys := &faultFS{fail: func(op string, name string) error {
+		if op == "open" && filepath.Ext(name) == ".json" {
+			opens++
+		}
+		return nil
+	}}
+	disk, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys), diskcache.WithClock(clock))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	tiered, err := diskcache.NewTiered(disk, 2)
+	if err != nil {
+		t.Fatalf("Error creating tiered cache: %v", err)
+	}
+	err = tiered.Set("key", []byte("value"), 1*time.Hour)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestWriteThrough", func(t *testing.T) {
+		value, err := disk.Get("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if string(value) != "value" {
+			t.Fatalf("Expected value, got %s", value)
+		}
+	})
+
+	t.Run("TestMemoryHit", func(t *testing.T) {
+		opens = 0
+		for range 10 {
+			value, ttl, err := tiered.GetWithTTL("key")
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			if string(value) != "value" || ttl != 1*time.Hour {
+				t.Fatalf("Expected value for 1h, got %s for %v", value, ttl)
+			}
+		}
+		if opens != 0 {
+			t.Fatalf("Expected no files to be opened, got %d", opens)
+		}
+	})
+
+	t.Run("TestDiskFill", func(t *testing.T) {
+		err := disk.Set("disk", []byte("value"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		opens = 0
+		for range 3 {
+			_, err := tiered.Get("disk")
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+		}
+		if opens != 1 {
+			t.Fatalf("Expected 1 file to be opened, got %d", opens)
+		}
+	})
+
+	t.Run("TestEvict", func(t *testing.T) {
+		for i := range 5 {
+			err := tiered.Set(fmt.Sprintf("key%d", i), []byte("value"), 1*time.Hour)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		if tiered.Len() != 2 {
+			t.Fatalf("Expected 2 entries in memory, got %d", tiered.Len())
+		}
+		value, err := tiered.Get("key0")
+		if err != nil || string(value) != "value" {
+			t.Fatalf("Expected evicted entry to be read from disk, got %s, %v", value, err)
+		}
+	})
+
+	t.Run("TestExpiry", func(t *testing.T) {
+		err := tiered.Set("short", []byte("value"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		clock.Advance(2 * time.Minute)
+		_, err = tiered.Get("short")
+		if !errors.Is(err, diskcache.ErrExpired) {
+			t.Fatalf("Expected ErrExpired, got %v", err)
+		}
+		_, err = disk.Get("short")
+		if !errors.Is(err, diskcache.ErrExpired) {
+			t.Fatalf("Expected ErrExpired from disk, got %v", err)
+		}
+	})
+
+	t.Run("TestRemove", func(t *testing.T) {
+		err := tiered.Remove("key")
+		if err != nil {
+			t.Fatalf("Error removing cache: %v", err)
+		}
+		_, err = tiered.Get("key")
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected ErrNotExist, got %v", err)
+		}
+		if disk.Has("key") {
+			t.Fatalf("Expected key to be removed from disk")
+		}
+	})
+
+	t.Run("TestInvalidate", func(t *testing.T) {
+		err := tiered.Set("stale", []byte("old"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = disk.Set("stale", []byte("new"), 1*time.Hour)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		tiered.Invalidate("stale")
+		value, err := tiered.Get("stale")
+		if err != nil || string(value) != "new" {
+			t.Fatalf("Expected new value, got %s, %v", value, err)
+		}
+	})
+
+	t.Run("TestInvalidMaxEntries", func(t *testing.T) {
+		_, err := diskcache.NewTiered(disk, 0)
+		if err == nil {
+			t.Fatalf("Expected error for invalid max entries, but got nil")
+		}
+	})
+}
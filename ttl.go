@@ -0,0 +1,75 @@
+package diskcache
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithDefaultTTL sets the duration of entries saved with SetDefault.
+// The default is NoExpiry.
+func WithDefaultTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		if d < 0 {
+			c.err = fmt.Errorf("invalid default TTL: %v", d)
+			return
+		}
+		c.defaultTTL = d
+	}
+}
+
+// WithMinTTL sets the minimum duration of entries.
+// Shorter durations, including negative ones, are raised to it,
+// so entries can't expire instantly.
+// NoExpiry isn't affected unless the cache has a maximum TTL.
+func WithMinTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		if d < 0 {
+			c.err = fmt.Errorf("invalid minimum TTL: %v", d)
+			return
+		}
+		c.minTTL = d
+	}
+}
+
+// WithMaxTTL sets the maximum duration of entries.
+// Longer durations, including NoExpiry, are lowered to it,
+// so entries can't outlive it.
+// A maximum of zero means no maximum.
+func WithMaxTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		if d < 0 {
+			c.err = fmt.Errorf("invalid maximum TTL: %v", d)
+			return
+		}
+		c.maxTTL = d
+	}
+}
+
+// clampTTL clamps a duration to the minimum and maximum TTL of the cache.
+// NoExpiry is clamped only to the maximum TTL.
+func (c Cache) clampTTL(d time.Duration) time.Duration {
+	if d == NoExpiry {
+		if c.maxTTL > 0 {
+			return c.maxTTL
+		}
+		return NoExpiry
+	}
+	if c.minTTL > 0 && d < c.minTTL {
+		d = c.minTTL
+	}
+	if c.maxTTL > 0 && d > c.maxTTL {
+		d = c.maxTTL
+	}
+	return d
+}
+
+// SetDefault saves a cache entry with a key and value
+// for the default duration of the cache.
+func (c Cache) SetDefault(key string, value []byte) error {
+	return c.Set(key, value, c.defaultTTL)
+}
+
+// DefaultTTL returns the default duration of the cache.
+func (c Cache) DefaultTTL() time.Duration {
+	return c.defaultTTL
+}
@@ -0,0 +1,121 @@
+package diskcache_test
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestDefaultTTL(t *testing.T) {
+	t.Run("TestSetDefault", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithDefaultTTL(15*time.Minute))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		if cache.DefaultTTL() != 15*time.Minute {
+			t.Fatalf("Expected default TTL of 15m, got %v", cache.DefaultTTL())
+		}
+		err = cache.SetDefault("key", []byte("value"))
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		_, ttl, err := cache.GetWithTTL("key")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if ttl <= 14*time.Minute || ttl > 15*time.Minute {
+			t.Fatalf("Expected TTL of about 15m, got %v", ttl)
+		}
+	})
+
+	t.Run("TestNoDefault", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.SetDefault("key", []byte("value"))
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		if !cache.Expiry("key").IsZero() {
+			t.Fatalf("Expected no expiry, got %s", cache.Expiry("key"))
+		}
+	})
+
+	t.Run("TestInvalid", func(t *testing.T) {
+		_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithDefaultTTL(-time.Minute))
+		if err == nil {
+			t.Fatalf("Expected error for negative default TTL")
+		}
+	})
+}
+
+func TestTTLClamps(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithMinTTL(1*time.Minute), diskcache.WithMaxTTL(15*time.Minute))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	for name, tc := range map[string]struct {
+		duration time.Duration
+		want     time.Duration
+	}{
+		"TestWithin":   {5 * time.Minute, 5 * time.Minute},
+		"TestTooShort": {1 * time.Second, 1 * time.Minute},
+		"TestNegative": {-1 * time.Hour, 1 * time.Minute},
+		"TestTooLong":  {24 * time.Hour, 15 * time.Minute},
+		"TestNoExpiry": {diskcache.NoExpiry, 15 * time.Minute},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := cache.Set(name, []byte("value"), tc.duration)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			_, ttl, err := cache.GetWithTTL(name)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			if ttl <= tc.want-time.Second || ttl > tc.want {
+				t.Fatalf("Expected TTL of about %v, got %v", tc.want, ttl)
+			}
+		})
+	}
+
+	t.Run("TestTouch", func(t *testing.T) {
+		err := cache.Set("touch", []byte("value"), 5*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.Touch("touch", diskcache.NoExpiry)
+		if err != nil {
+			t.Fatalf("Error touching cache: %v", err)
+		}
+		if cache.Expiry("touch").After(time.Now().Add(15 * time.Minute)) {
+			t.Fatalf("Expected expiry within 15m, got %s", cache.Expiry("touch"))
+		}
+	})
+
+	t.Run("TestAppend", func(t *testing.T) {
+		err := cache.Append("append", []byte("value"))
+		if err != nil {
+			t.Fatalf("Error appending cache: %v", err)
+		}
+		if cache.Expiry("append").IsZero() {
+			t.Fatalf("Expected expiry for appended entry")
+		}
+	})
+
+	t.Run("TestInvalid", func(t *testing.T) {
+		for _, options := range [][]diskcache.Option{
+			{diskcache.WithMinTTL(-time.Minute)},
+			{diskcache.WithMaxTTL(-time.Minute)},
+			{diskcache.WithMinTTL(time.Hour), diskcache.WithMaxTTL(time.Minute)},
+		} {
+			_, err := diskcache.New(path.Join(t.TempDir(), "testcache"), options...)
+			if err == nil {
+				t.Fatalf("Expected error for invalid TTL options")
+			}
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package diskcache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Txn is a group of Set and Remove operations staged by Tx
+// and committed together.
+// The operations take effect in order when the transaction commits,
+// so a later operation on a key replaces an earlier one.
+type Txn struct {
+	cache Cache
+	ops   []txOp
+}
+
+// txOp is a staged operation of a transaction.
+type txOp struct {
+	key string
+	// contents is the encoded entry file of a Set, or nil for a Remove.
+	contents []byte
+	entry    Data
+}
+
+// Set stages saving a cache entry with a key, value, and duration.
+// The entry is encoded when it is staged,
+// so its expiry is computed from when Set is called.
+func (tx *Txn) Set(key string, value []byte, duration time.Duration) error {
+	if len(key) == 0 {
+		return fmt.Errorf("key cannot be empty")
+	}
+	now := tx.cache.now()
+	entry := Data{
+		Key:       key,
+		Value:     value,
+		Expiry:    tx.cache.expiryFrom(now, duration),
+		CreatedAt: now,
+	}
+	rec, err := tx.cache.encode(entry)
+	if err != nil {
+		return err
+	}
+	contents, err := tx.cache.marshal(rec)
+	if err != nil {
+		return err
+	}
+	tx.stage(txOp{key: key, contents: contents, entry: entry})
+	return nil
+}
+
+// Remove stages deleting a cache entry.
+// Removing a key that doesn't exist is a no-op.
+func (tx *Txn) Remove(key string) {
+	tx.stage(txOp{key: key})
+}
+
+// stage adds an operation to the transaction,
+// replacing any earlier operation on the same key.
+func (tx *Txn) stage(op txOp) {
+	for i := range tx.ops {
+		if tx.ops[i].key == op.key {
+			tx.ops = append(tx.ops[:i], tx.ops[i+1:]...)
+			break
+		}
+	}
+	tx.ops = append(tx.ops, op)
+}
+
+// Tx calls fn to stage operations on a transaction,
+// then commits them if fn returns nil.
+// Either all the operations are applied or, if fn returns an error
+// or committing fails, none of them are.
+// While a transaction commits, its keys are locked against CAS, Increment,
+// Append, and other transactions, and reads from the cache and its copies
+// wait for it, so they never see some of its operations but not others.
+// Reads of several entries may still straddle a commit,
+// unless they are made in View.
+// Readers in other processes aren't isolated and may see a partial commit.
+// Tx doesn't support caches with raw values or previous versions.
+func (c Cache) Tx(fn func(tx *Txn) error) error {
+	if c.readOnly {
+		return errReadOnly("commit", c.dir)
+	}
+	if c.rawValues || c.versions > 0 {
+		return errors.New("transactions aren't supported with raw values or versions")
+	}
+	tx := &Txn{cache: c}
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+	return c.commit(tx.ops)
+}
+
+// View calls fn with a copy of the cache on which no transaction
+// commits until fn returns, so the entries fn reads through it
+// are all from before or all from after each transaction.
+// Transactions on the cache wait for fn,
+// so fn should be quick and mustn't wait for them.
+func (c Cache) View(fn func(c Cache) error) error {
+	unlock := c.commits.rlock()
+	defer unlock()
+	view := c
+	// The view holds the read lock, so its reads mustn't take it again.
+	view.commits = nil
+	return fn(view)
+}
+
+// txUndo is what committing an operation replaced,
+// to restore it if the commit fails.
+type txUndo struct {
+	filename string
+	// contents is the replaced entry file, or nil if there was none.
+	contents []byte
+	// data is the replaced entry for the removal hooks.
+	data Data
+}
+
+// commit applies the operations of a transaction,
+// restoring the replaced entry files if any operation fails.
+func (c Cache) commit(ops []txOp) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	keys := make([]string, len(ops))
+	for i, op := range ops {
+		keys[i] = op.key
+	}
+	unlock := c.locks.lockAll(keys...)
+	defer unlock()
+	undos := make([]txUndo, len(ops))
+	for i, op := range ops {
+		filename := c.Filename(op.key)
+		undos[i] = txUndo{filename: filename, data: c.hookData(filename, op.key, false)}
+	}
+	err := c.apply(ops, undos)
+	if err != nil {
+		return err
+	}
+	c.log(slog.LevelDebug, "committed transaction", "operations", len(ops))
+	for i, op := range ops {
+		if op.contents == nil && undos[i].contents != nil {
+			c.callHooks(undos[i].data, false)
+		}
+	}
+	var errs error
+	for _, op := range ops {
+		if op.contents != nil {
+			errs = errors.Join(errs, c.evict(op.key))
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("error evicting entries: %w", errs)
+	}
+	return nil
+}
+
+// apply writes and removes the entry files of operations
+// whose keys are locked, as one commit that reads wait for,
+// journaled if the cache has a journal.
+// It records the replaced entry files in undos,
+// which name the files of the operations,
+// and restores them if any operation fails.
+func (c Cache) apply(ops []txOp, undos []txUndo) error {
+	unlock := c.commits.lock()
+	defer unlock()
+	for i := range ops {
+		contents, err := c.readBlob(undos[i].filename)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error reading entry: %w", err)
+		}
+		undos[i].contents = contents
+	}
+	var journal string
+	var jops []journalOp
+	if c.journal {
+		var err error
+		jops = c.journalOps(ops, undos)
+		journal, err = c.writeJournal(jops)
+		if err != nil {
+			return err
+		}
+	}
+	for i, op := range ops {
+		var err error
+		if op.contents != nil {
+			_, err = c.store.WriteEntry(undos[i].filename, bytes.NewReader(op.contents))
+		} else if undos[i].contents != nil {
+			err = c.store.RemoveEntry(undos[i].filename)
+		}
+		if err != nil {
+			err = fmt.Errorf("error committing transaction: %w", err)
+			rollbackErr := c.rollback(undos[:i+1])
+			if rollbackErr != nil {
+				// Keep the journal to complete the transaction on open.
+				return errors.Join(err, rollbackErr)
+			}
+			return errors.Join(err, c.removeJournal(journal))
+		}
+	}
+	for i, op := range ops {
+		filename := undos[i].filename
+		// Remove the sidecar of a previously streamed value, if any,
+		// which the entry file no longer refers to.
+		err := c.removeEntryIfExists(c.sidecar(filename))
+		if err != nil {
+			c.log(slog.LevelWarn, "error removing sidecar", "key", op.key, "error", err)
+		}
+		if op.contents != nil {
+			c.index.set(op.entry, int64(len(op.contents)), filename)
+			c.stats.write(len(op.contents))
+			continue
+		}
+		if undos[i].contents != nil {
+			c.index.remove(op.key)
+			c.stats.remove(1)
+		}
+	}
+	if journal != "" {
+		c.finishJournal(journal, jops)
+	}
+	return nil
+}
+
+// rollback restores the entry files replaced by a failed commit.
+func (c Cache) rollback(undos []txUndo) error {
+	var errs error
+	for i := len(undos) - 1; i >= 0; i-- {
+		undo := undos[i]
+		var err error
+		if undo.contents != nil {
+			_, err = c.store.WriteEntry(undo.filename, bytes.NewReader(undo.contents))
+		} else {
+			err = c.removeEntryIfExists(undo.filename)
+		}
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error rolling back %s: %w", undo.filename, err))
+		}
+	}
+	return errs
+}
+
+// commitLock makes reads wait for transactions to commit,
+// shared by all copies of a cache.
+// A nil commitLock is valid and locking it is a no-op.
+type commitLock struct {
+	mu sync.RWMutex
+}
+
+// lock locks out reads and returns a function to unlock them,
+// which may be called more than once.
+func (l *commitLock) lock() func() {
+	if l == nil {
+		return func() {}
+	}
+	l.mu.Lock()
+	var once sync.Once
+	return func() {
+		once.Do(l.mu.Unlock)
+	}
+}
+
+// rlock locks out commits and returns a function to unlock them.
+func (l *commitLock) rlock() func() {
+	if l == nil {
+		return func() {}
+	}
+	l.mu.RLock()
+	return l.mu.RUnlock
+}
@@ -0,0 +1,178 @@
+package diskcache_test
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestTx(t *testing.T) {
+	t.Run("TestCommit", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithIndex())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("old", []byte("old"), time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.Tx(func(tx *diskcache.Txn) error {
+			err := tx.Set("a", []byte("first"), time.Minute)
+			if err != nil {
+				return err
+			}
+			err = tx.Set("b", []byte("b"), time.Minute)
+			if err != nil {
+				return err
+			}
+			tx.Remove("old")
+			tx.Remove("missing")
+			// A later operation on a key replaces an earlier one.
+			return tx.Set("a", []byte("a"), time.Minute)
+		})
+		if err != nil {
+			t.Fatalf("Error committing transaction: %v", err)
+		}
+		for _, key := range []string{"a", "b"} {
+			value, err := cache.Get(key)
+			if err != nil || string(value) != key {
+				t.Fatalf("Expected %s to be %q, got %q, %v", key, key, value, err)
+			}
+		}
+		if cache.Has("old") {
+			t.Fatalf("Expected old to be removed")
+		}
+		keys, err := cache.Keys()
+		if err != nil || len(keys) != 2 {
+			t.Fatalf("Expected index to hold a and b, got %v, %v", keys, err)
+		}
+	})
+
+	t.Run("TestAbort", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		err = cache.Set("old", []byte("old"), time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		abort := errors.New("abort")
+		err = cache.Tx(func(tx *diskcache.Txn) error {
+			tx.Set("new", []byte("new"), time.Minute)
+			tx.Remove("old")
+			return abort
+		})
+		if !errors.Is(err, abort) {
+			t.Fatalf("Expected abort error, got %v", err)
+		}
+		if cache.Has("new") || !cache.Has("old") {
+			t.Fatalf("Expected no operations to be applied")
+		}
+	})
+
+	t.Run("TestRollback", func(t *testing.T) {
+		fsys := &faultFS{}
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), diskcache.WithFS(fsys), diskcache.WithIndex())
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		for _, key := range []string{"a", "b"} {
+			err = cache.Set(key, []byte("old "+key), time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+		}
+		fsys.fail = func(op string, name string) error {
+			if op == "rename" && name == cache.Filepath("c") {
+				return syscall.ENOSPC
+			}
+			return nil
+		}
+		err = cache.Tx(func(tx *diskcache.Txn) error {
+			tx.Set("a", []byte("new a"), time.Minute)
+			tx.Remove("b")
+			return tx.Set("c", []byte("new c"), time.Minute)
+		})
+		if !errors.Is(err, syscall.ENOSPC) {
+			t.Fatalf("Expected ENOSPC, got %v", err)
+		}
+		fsys.fail = nil
+		for _, key := range []string{"a", "b"} {
+			value, err := cache.Get(key)
+			if err != nil || string(value) != "old "+key {
+				t.Fatalf("Expected %s to be rolled back, got %q, %v", key, value, err)
+			}
+		}
+		if _, err := cache.Read("c"); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Expected c not to exist, got %v", err)
+		}
+		assertNoTempFiles(t, cache.Dir())
+	})
+
+	t.Run("TestIsolation", func(t *testing.T) {
+		cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+		if err != nil {
+			t.Fatalf("Error creating cache: %v", err)
+		}
+		set := func(value string) {
+			err := cache.Tx(func(tx *diskcache.Txn) error {
+				for _, key := range []string{"x", "y", "z"} {
+					err := tx.Set(key, []byte(value), time.Minute)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Error committing transaction: %v", err)
+			}
+		}
+		set("0")
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				set(string(rune('a' + i%26)))
+			}
+		}()
+		for range 200 {
+			var list []diskcache.Data
+			err := cache.View(func(c diskcache.Cache) error {
+				var err error
+				list, err = c.List()
+				return err
+			})
+			if err != nil {
+				t.Fatalf("Error listing cache: %v", err)
+			}
+			for _, data := range list[1:] {
+				if string(data.Value) != string(list[0].Value) {
+					t.Fatalf("Expected to see whole transactions, got %q and %q", list[0].Value, data.Value)
+				}
+			}
+		}
+		wg.Wait()
+	})
+
+	t.Run("TestUnsupported", func(t *testing.T) {
+		for _, option := range []diskcache.Option{diskcache.WithRawValues(), diskcache.WithVersions(2)} {
+			cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"), option)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			err = cache.Tx(func(tx *diskcache.Txn) error { return nil })
+			if err == nil {
+				t.Fatalf("Expected error for unsupported cache")
+			}
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package diskcache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// VacuumReport describes what Vacuum did.
+type VacuumReport struct {
+	// Removed is the number of expired entries removed.
+	Removed int
+	// Rewritten is the number of entries rewritten.
+	Rewritten int
+	// SizeBefore is the total size in bytes of the entries before Vacuum.
+	SizeBefore int64
+	// SizeAfter is the total size in bytes of the entries after Vacuum.
+	SizeAfter int64
+}
+
+// Reclaimed returns the number of bytes Vacuum freed.
+// It's negative if the rewritten entries are larger,
+// such as after changing to a less compact format.
+func (r VacuumReport) Reclaimed() int64 {
+	return r.SizeBefore - r.SizeAfter
+}
+
+// Vacuum removes the expired entries and rewrites the rest with the
+// cache's options, such as its format and compression, so entries
+// written before the options changed are stored like new ones.
+// Rewritten entries keep their expiry and creation times.
+// Entries written by other processes during Vacuum may be
+// overwritten with the values they replaced, so it's best run
+// while nothing else writes to the cache.
+func (c Cache) Vacuum() (VacuumReport, error) {
+	var report VacuumReport
+	var err error
+	report.SizeBefore, err = c.Size()
+	if err != nil {
+		return VacuumReport{}, err
+	}
+	expired, err := c.CleanReport()
+	if err != nil {
+		return VacuumReport{}, err
+	}
+	err = c.Clean()
+	if err != nil {
+		return VacuumReport{}, fmt.Errorf("error removing expired entries: %w", err)
+	}
+	report.Removed = len(expired.Entries)
+	filenames, err := c.readDir()
+	if err != nil {
+		return VacuumReport{}, fmt.Errorf("error reading directory: %w", err)
+	}
+	var errs error
+	for _, filename := range filenames {
+		rec, err := c.readDecoded(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error reading entry %s: %w", filename, err))
+			continue
+		}
+		err = c.write(rec.Data)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("error writing entry %s: %w", filename, err))
+			continue
+		}
+		report.Rewritten++
+	}
+	report.SizeAfter, err = c.Size()
+	return report, errors.Join(errs, err)
+}
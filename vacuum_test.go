@@ -0,0 +1,74 @@
+package diskcache_test
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestVacuum(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cache, err := diskcache.New(cacheDir, diskcache.WithClock(clock))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	value := bytes.Repeat([]byte("value "), 1000)
+	err = cache.Set("kept", value, 1*time.Hour)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	err = cache.Set("expired", value, 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+	vacuumed, err := diskcache.New(cacheDir, diskcache.WithClock(clock),
+		diskcache.WithFormat(diskcache.Binary), diskcache.WithCompression(diskcache.Gzip))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	report, err := vacuumed.Vacuum()
+	if err != nil {
+		t.Fatalf("Error vacuuming cache: %v", err)
+	}
+
+	t.Run("TestReport", func(t *testing.T) {
+		if report.Removed != 1 || report.Rewritten != 1 {
+			t.Fatalf("Expected 1 entry removed and 1 rewritten, got %+v", report)
+		}
+		if report.Reclaimed() <= 0 {
+			t.Fatalf("Expected bytes to be reclaimed, got %+v", report)
+		}
+	})
+
+	t.Run("TestRewritten", func(t *testing.T) {
+		contents, err := os.ReadFile(cache.Filepath("kept"))
+		if err != nil {
+			t.Fatalf("Error reading file: %v", err)
+		}
+		if !bytes.HasPrefix(contents, []byte("DCB\x01")) {
+			t.Fatalf("Expected entry to be rewritten in the binary format")
+		}
+		got, ttl, err := cache.GetWithTTL("kept")
+		if err != nil {
+			t.Fatalf("Error getting cache: %v", err)
+		}
+		if !bytes.Equal(got, value) {
+			t.Fatalf("Expected value to be kept")
+		}
+		if ttl != 58*time.Minute {
+			t.Fatalf("Expected expiry to be kept, got %v", ttl)
+		}
+	})
+
+	t.Run("TestRemoved", func(t *testing.T) {
+		if cache.Has("expired") {
+			t.Fatalf("Expected expired entry to be removed")
+		}
+	})
+}
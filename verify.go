@@ -0,0 +1,62 @@
+package diskcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrCorrupt is returned when a cache entry fails verification.
+var ErrCorrupt = errors.New("cache entry is corrupt")
+
+// WithVerify verifies the checksum of every entry read by Get, Read, and List,
+// and returns an error wrapping ErrCorrupt if it doesn't match.
+// Entries written before checksums existed aren't verified.
+func WithVerify() Option {
+	return func(c *Cache) {
+		c.verify = true
+	}
+}
+
+// Verify reads a cache entry and checks that it decodes
+// and that its value matches its checksum.
+// It returns an error wrapping ErrCorrupt if the entry is corrupt,
+// or an error wrapping fs.ErrNotExist if the entry doesn't exist.
+func (c Cache) Verify(key string) error {
+	return c.verifyFile(c.Filename(key))
+}
+
+// VerifyAll verifies every cache entry.
+// It returns the joined errors of the corrupt entries, if any.
+func (c Cache) VerifyAll() error {
+	filenames, err := c.readDir()
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+	var errs error
+	for _, filename := range filenames {
+		err := c.verifyFile(filename)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("entry %s: %w", filename, err))
+		}
+	}
+	return errs
+}
+
+// verifyFile verifies a cache entry by its filename.
+func (c Cache) verifyFile(filename string) error {
+	c.verify = true
+	_, err := c.readFile(filename)
+	if err == nil || errors.Is(err, ErrCorrupt) || errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrCorrupt, err)
+}
+
+// checksum returns the hex-encoded SHA-256 hash of a value.
+func checksum(value []byte) string {
+	hash := sha256.Sum256(value)
+	return hex.EncodeToString(hash[:])
+}
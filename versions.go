@@ -0,0 +1,195 @@
+package diskcache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// WithVersions keeps the previous n values of each key,
+// in files such as <hash>.v1.json for the most recent previous value.
+// Set, SetReader, CAS, Increment, Decrement, Append, Copy, and Rename
+// keep a version of the value they replace;
+// Touch, Import, Pull, Sync, Migrate, and Vacuum don't,
+// and transactions aren't supported with versions.
+// Versions are removed with their entry and aren't counted in Stats or Size.
+// A count of zero or less keeps no versions.
+func WithVersions(n int) Option {
+	return func(c *Cache) {
+		c.versions = n
+	}
+}
+
+// ReadVersion reads a previous version of a cache entry
+// and returns all its data.
+// Version 1 is the most recent previous value, and version 0 is the current one.
+// It does not check if the entry is expired.
+func (c Cache) ReadVersion(key string, n int) (Data, error) {
+	if n < 0 || n > c.versions {
+		return Data{}, fmt.Errorf("invalid version: %d", n)
+	}
+	filename := c.Filename(key)
+	if n > 0 {
+		filename = c.versionName(filename, n)
+	}
+	return c.readFile(filename)
+}
+
+// Rollback replaces a cache entry with its most recent previous version,
+// with the expiry that version had.
+// The other versions move up, so repeated rollbacks go further back.
+// It returns an error if the entry has no previous version.
+func (c Cache) Rollback(key string) error {
+	unlock := c.locks.lock(key)
+	defer unlock()
+	filename := c.Filename(key)
+	err := c.rollbackFiles(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no previous version of key %q: %w", key, err)
+	}
+	if err != nil {
+		return err
+	}
+	if c.index == nil {
+		return nil
+	}
+	rec, err := c.readHeader(filename)
+	if err != nil {
+		return err
+	}
+	sizes, err := c.sizes()
+	if err != nil {
+		return err
+	}
+	data := rec.Data
+	data.Key = key
+	c.index.set(data, sizes[filename], filename)
+	return nil
+}
+
+// rollbackFiles replaces a cache entry file with its most recent
+// previous version and moves the other versions up.
+func (c Cache) rollbackFiles(filename string) error {
+	unlock := c.files.lock(filename)
+	defer unlock()
+	err := c.copyVersion(c.versionName(filename, 1), filename)
+	if err != nil {
+		return err
+	}
+	for i := 1; i < c.versions; i++ {
+		err := c.moveVersion(c.versionName(filename, i+1), c.versionName(filename, i))
+		if err != nil {
+			return err
+		}
+	}
+	return c.moveVersion(c.versionName(filename, c.versions), "")
+}
+
+// lockVersions locks a key while its versions are shifted
+// and its entry replaced, if the cache keeps versions,
+// and returns a function to unlock it.
+// Set, SetReader, and Rollback take it, so concurrent calls
+// don't keep the same value twice or lose versions.
+func (c Cache) lockVersions(key string) func() {
+	if c.versions <= 0 {
+		return func() {}
+	}
+	return c.locks.lock(key)
+}
+
+// keepVersion shifts the versions of a cache entry down
+// and saves the current entry as version 1, if it exists,
+// before the entry is replaced.
+func (c Cache) keepVersion(filename string) error {
+	if c.versions <= 0 {
+		return nil
+	}
+	unlock := c.files.lock(filename)
+	defer unlock()
+	r, err := c.store.ReadEntry(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.Close()
+	for i := c.versions; i > 1; i-- {
+		err := c.moveVersion(c.versionName(filename, i-1), c.versionName(filename, i))
+		if err != nil {
+			return err
+		}
+	}
+	return c.copyVersion(filename, c.versionName(filename, 1))
+}
+
+// moveVersion moves a version of a cache entry, including its sidecar,
+// replacing the destination, or removes the destination
+// if the version doesn't exist.
+// An empty destination removes the version.
+func (c Cache) moveVersion(src string, dst string) error {
+	if dst != "" {
+		err := c.copyVersion(src, dst)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = errors.Join(c.removeEntryIfExists(dst), c.removeEntryIfExists(c.sidecar(dst)))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Join(c.removeEntryIfExists(src), c.removeEntryIfExists(c.sidecar(src)))
+}
+
+// copyVersion copies a cache entry file and its sidecar, if any,
+// replacing the destination and its sidecar.
+func (c Cache) copyVersion(src string, dst string) error {
+	contents, err := c.readBlob(src)
+	if err != nil {
+		return err
+	}
+	sidecar, err := c.readBlob(c.sidecar(src))
+	switch {
+	case err == nil:
+		_, err = c.store.WriteEntry(c.sidecar(dst), bytes.NewReader(sidecar))
+	case errors.Is(err, fs.ErrNotExist):
+		err = c.removeEntryIfExists(c.sidecar(dst))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = c.store.WriteEntry(dst, bytes.NewReader(contents))
+	return err
+}
+
+// removeVersions deletes the previous versions of a cache entry.
+func (c Cache) removeVersions(filename string) error {
+	var errs error
+	for i := 1; i <= c.versions; i++ {
+		version := c.versionName(filename, i)
+		errs = errors.Join(errs, c.removeEntryIfExists(version), c.removeEntryIfExists(c.sidecar(version)))
+	}
+	return errs
+}
+
+// versionName returns the filename of a version of a cache entry.
+func (c Cache) versionName(filename string, n int) string {
+	return strings.TrimSuffix(filename, c.ext) + ".v" + strconv.Itoa(n) + c.ext
+}
+
+// isVersion returns true if the filename is a version of a cache entry.
+func (c Cache) isVersion(filename string) bool {
+	name := strings.TrimSuffix(filename, c.ext)
+	i := strings.LastIndex(name, ".v")
+	if i < 0 || i+2 == len(name) {
+		return false
+	}
+	for _, r := range name[i+2:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
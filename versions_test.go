@@ -0,0 +1,144 @@
+package diskcache_test
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestVersions(t *testing.T) {
+	cacheDir := path.Join(t.TempDir(), "testcache")
+	cache, err := diskcache.New(cacheDir, diskcache.WithVersions(2), diskcache.WithIndex())
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	key := "config"
+	for _, value := range []string{"v1", "v2", "v3"} {
+		err := cache.Set(key, []byte(value), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+	}
+	err = cache.SetReader(key, strings.NewReader("v4"), 1*time.Minute)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	t.Run("TestReadVersion", func(t *testing.T) {
+		for n, want := range []string{"v4", "v3", "v2"} {
+			data, err := cache.ReadVersion(key, n)
+			if err != nil {
+				t.Fatalf("Error reading version %d: %v", n, err)
+			}
+			if string(data.Value) != want {
+				t.Fatalf("Expected version %d to be %s, got %s", n, want, data.Value)
+			}
+		}
+		_, err := cache.ReadVersion(key, 3)
+		if err == nil {
+			t.Fatalf("Expected error reading version beyond the limit")
+		}
+	})
+
+	t.Run("TestList", func(t *testing.T) {
+		list, err := cache.List()
+		if err != nil {
+			t.Fatalf("Error listing cache: %v", err)
+		}
+		if len(list) != 1 {
+			t.Fatalf("Expected versions not to be listed, got %d entries", len(list))
+		}
+	})
+
+	t.Run("TestRollback", func(t *testing.T) {
+		for _, want := range []string{"v3", "v2"} {
+			err := cache.Rollback(key)
+			if err != nil {
+				t.Fatalf("Error rolling back: %v", err)
+			}
+			got, err := cache.Get(key)
+			if err != nil {
+				t.Fatalf("Error getting cache: %v", err)
+			}
+			if string(got) != want {
+				t.Fatalf("Expected cache value to be %s, got %s", want, got)
+			}
+		}
+		err := cache.Rollback(key)
+		if err == nil {
+			t.Fatalf("Expected error rolling back without a previous version")
+		}
+	})
+
+	t.Run("TestRemove", func(t *testing.T) {
+		err := cache.Set(key, []byte("v5"), 1*time.Minute)
+		if err != nil {
+			t.Fatalf("Error saving cache: %v", err)
+		}
+		err = cache.Remove(key)
+		if err != nil {
+			t.Fatalf("Error removing cache: %v", err)
+		}
+		_, err = cache.ReadVersion(key, 1)
+		if err == nil {
+			t.Fatalf("Expected versions to be removed")
+		}
+	})
+
+	t.Run("TestIncrement", func(t *testing.T) {
+		for range 2 {
+			_, err := cache.Increment("counter", 1, 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error incrementing counter: %v", err)
+			}
+		}
+		previous, err := cache.ReadVersion("counter", 1)
+		if err != nil || string(previous.Value) != "1" {
+			t.Fatalf("Expected previous counter value 1, got %s, %v", previous.Value, err)
+		}
+	})
+
+	t.Run("TestAppend", func(t *testing.T) {
+		for _, data := range []string{"a", "b"} {
+			err := cache.Append("log", []byte(data))
+			if err != nil {
+				t.Fatalf("Error appending: %v", err)
+			}
+		}
+		previous, err := cache.ReadVersion("log", 1)
+		if err != nil || string(previous.Value) != "a" {
+			t.Fatalf("Expected previous log value a, got %s, %v", previous.Value, err)
+		}
+	})
+
+	t.Run("TestConcurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := cache.Set("concurrent", []byte(strconv.Itoa(i)), 1*time.Minute)
+				if err != nil {
+					t.Errorf("Error saving cache: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		seen := make(map[string]bool)
+		for n := range 3 {
+			data, err := cache.ReadVersion("concurrent", n)
+			if err != nil {
+				t.Fatalf("Error reading version %d: %v", n, err)
+			}
+			if seen[string(data.Value)] {
+				t.Fatalf("Expected distinct versions, got %s twice", data.Value)
+			}
+			seen[string(data.Value)] = true
+		}
+	})
+}
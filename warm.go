@@ -0,0 +1,103 @@
+package diskcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WarmSpec is an entry for Warm to load.
+type WarmSpec struct {
+	Key string
+	// Load returns the value of the entry.
+	Load func(ctx context.Context) ([]byte, error)
+	// TTL is how long the entry lives, or NoExpiry.
+	TTL time.Duration
+}
+
+// WarmReport describes the entries loaded by Warm.
+type WarmReport struct {
+	// Loaded is the keys of the entries loaded and saved.
+	Loaded []string
+	// Skipped is the keys of the entries already in the cache.
+	Skipped []string
+}
+
+// Warm pre-populates the cache, such as when a service is deployed,
+// by loading and saving the entries that are missing or expired,
+// up to DefaultMaxParallel at a time.
+// It stops loading entries when the context is done,
+// waits for the loads in progress, and returns an error
+// wrapping the context error along with any load errors.
+// The context is passed to the loaders, the operation hook, and the logger.
+func (c Cache) Warm(ctx context.Context, entries []WarmSpec) (WarmReport, error) {
+	c = c.WithContext(ctx)
+	done := c.observe("Warm", "")
+	report, err := c.warm(ctx, entries)
+	done(OperationResult{Err: err})
+	return report, err
+}
+
+// warm loads and saves the entries that are missing or expired.
+func (c Cache) warm(ctx context.Context, entries []WarmSpec) (WarmReport, error) {
+	var (
+		report WarmReport
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errs   error
+	)
+	sem := make(chan struct{}, DefaultMaxParallel)
+loop:
+	for _, spec := range entries {
+		if !c.IsExpired(spec.Key) {
+			report.Skipped = append(report.Skipped, spec.Key)
+			continue
+		}
+		// Check the context first, since select picks randomly
+		// when a slot is also free.
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(spec WarmSpec) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := c.warmEntry(ctx, spec)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				c.log(slog.LevelWarn, "error warming entry", "key", spec.Key, "error", err)
+				errs = errors.Join(errs, fmt.Errorf("error warming %q: %w", spec.Key, err))
+				return
+			}
+			report.Loaded = append(report.Loaded, spec.Key)
+		}(spec)
+	}
+	wg.Wait()
+	slices.Sort(report.Loaded)
+	if err := ctx.Err(); err != nil {
+		errs = errors.Join(fmt.Errorf("warm canceled: %w", err), errs)
+	}
+	return report, errs
+}
+
+// warmEntry loads and saves an entry.
+func (c Cache) warmEntry(ctx context.Context, spec WarmSpec) error {
+	if spec.Load == nil {
+		return errors.New("loader is nil")
+	}
+	value, err := spec.Load(ctx)
+	if err != nil {
+		return err
+	}
+	return c.Set(spec.Key, value, spec.TTL)
+}
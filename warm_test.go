@@ -0,0 +1,83 @@
+package diskcache_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestWarm(t *testing.T) {
+	cache, err := diskcache.New(path.Join(t.TempDir(), "testcache"))
+	if err != nil {
+		t.Fatalf("Error creating cache: %v", err)
+	}
+	err = cache.Set("present", []byte("old"), 1*time.Hour)
+	if err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+	var loads atomic.Int32
+	load := func(value string) func(context.Context) ([]byte, error) {
+		return func(context.Context) ([]byte, error) {
+			loads.Add(1)
+			return []byte(value), nil
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("TestWarm", func(t *testing.T) {
+		report, err := cache.Warm(ctx, []diskcache.WarmSpec{
+			{Key: "key1", Load: load("value1"), TTL: 1 * time.Hour},
+			{Key: "key2", Load: load("value2"), TTL: diskcache.NoExpiry},
+			{Key: "present", Load: load("new"), TTL: 1 * time.Hour},
+		})
+		if err != nil {
+			t.Fatalf("Error warming cache: %v", err)
+		}
+		if !slices.Equal(report.Loaded, []string{"key1", "key2"}) || !slices.Equal(report.Skipped, []string{"present"}) {
+			t.Fatalf("Unexpected report %+v", report)
+		}
+		if loads.Load() != 2 {
+			t.Fatalf("Expected 2 loads, got %d", loads.Load())
+		}
+		for key, want := range map[string]string{"key1": "value1", "key2": "value2", "present": "old"} {
+			value, err := cache.Get(key)
+			if err != nil || string(value) != want {
+				t.Fatalf("Expected %s for %s, got %s, %v", want, key, value, err)
+			}
+		}
+	})
+
+	t.Run("TestError", func(t *testing.T) {
+		errLoad := errors.New("load failed")
+		report, err := cache.Warm(ctx, []diskcache.WarmSpec{
+			{Key: "failed", Load: func(context.Context) ([]byte, error) { return nil, errLoad }},
+			{Key: "key3", Load: load("value3")},
+		})
+		if !errors.Is(err, errLoad) {
+			t.Fatalf("Expected load error, got %v", err)
+		}
+		if !slices.Equal(report.Loaded, []string{"key3"}) || cache.Has("failed") {
+			t.Fatalf("Expected only key3 to be loaded, got %+v", report)
+		}
+	})
+
+	t.Run("TestCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		report, err := cache.Warm(ctx, []diskcache.WarmSpec{
+			{Key: "canceled", Load: load("value")},
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if len(report.Loaded) != 0 {
+			t.Fatalf("Expected nothing to be loaded, got %+v", report)
+		}
+	})
+}
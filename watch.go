@@ -0,0 +1,251 @@
+package diskcache
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// EventType is the type of change to a cache entry.
+type EventType string
+
+const (
+	// EventSet is emitted when an entry is written.
+	EventSet EventType = "set"
+	// EventRemove is emitted when an entry is removed.
+	EventRemove EventType = "remove"
+	// EventExpire is emitted when an entry expires.
+	EventExpire EventType = "expire"
+)
+
+// Event is a change to a cache entry.
+type Event struct {
+	Type   EventType
+	Key    string
+	Expiry time.Time
+}
+
+// watchExpiryInterval is how often Watch checks for expired entries.
+const watchExpiryInterval = time.Second
+
+// Watch watches the cache directory and emits an event when an entry is set,
+// removed, or expires, including changes made by other processes.
+// The channel is closed when the context is done.
+// Because filenames are hashes, removals are reported only for entries
+// the watcher has seen, either when it started or since,
+// unless the cache encodes keys in filenames.
+// Caches backed by another store can't be watched.
+func (c Cache) Watch(ctx context.Context) (<-chan Event, error) {
+	_, err := c.dirStore()
+	if err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating watcher: %w", err)
+	}
+	w := &watch{
+		cache:   c,
+		watcher: watcher,
+		entries: make(map[string]Data),
+		events:  make(chan Event),
+	}
+	err = w.add("")
+	if err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("error watching directory: %w", err)
+	}
+	filenames, err := c.readDir()
+	if err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	for _, filename := range filenames {
+		entry, err := c.readKey(filename)
+		if err == nil {
+			w.entries[filename] = entry
+		}
+	}
+	go w.run(ctx)
+	return w.events, nil
+}
+
+// watch is the state of a running Watch.
+type watch struct {
+	cache   Cache
+	watcher *fsnotify.Watcher
+	// entries maps the filenames of the known entries to their keys and expiries.
+	entries map[string]Data
+	events  chan Event
+}
+
+// add watches a directory, relative to the cache directory,
+// and, if the cache is sharded, its shard subdirectories.
+func (w *watch) add(dir string) error {
+	err := w.watcher.Add(w.cache.filepath(dir))
+	if err != nil {
+		return err
+	}
+	if !w.cache.sharded {
+		return nil
+	}
+	dirEntries, err := os.ReadDir(w.cache.filepath(dir))
+	if err != nil {
+		return err
+	}
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() && isShard(dirEntry.Name()) {
+			err := w.add(filepath.Join(dir, dirEntry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// run translates file system events to cache events until the context is done.
+func (w *watch) run(ctx context.Context) {
+	defer close(w.events)
+	defer w.watcher.Close()
+	ticker := time.NewTicker(watchExpiryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !w.handle(ctx, event) {
+				return
+			}
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-ticker.C:
+			if !w.expire(ctx, w.cache.now()) {
+				return
+			}
+		}
+	}
+}
+
+// handle emits the cache event for a file system event, if any.
+// It returns false if the context is done.
+func (w *watch) handle(ctx context.Context, event fsnotify.Event) bool {
+	filename, err := filepath.Rel(w.cache.dir, event.Name)
+	if err != nil {
+		return true
+	}
+	switch {
+	case event.Has(fsnotify.Create) && w.cache.sharded && isShard(filepath.Base(filename)):
+		// A new shard subdirectory.
+		// Entries written before it was watched are reported by scanning it.
+		_ = w.add(filename)
+		return w.scan(ctx, filename)
+	case !w.cache.isEntry(filename):
+		return true
+	case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
+		entry, err := w.cache.readKey(filename)
+		if err != nil {
+			return true
+		}
+		w.entries[filename] = entry
+		return w.emit(ctx, Event{Type: EventSet, Key: entry.Key, Expiry: entry.Expiry})
+	case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+		entry, ok := w.entries[filename]
+		delete(w.entries, filename)
+		if !ok {
+			// Entries the watcher hasn't seen are reported
+			// if the key can be decoded from the filename.
+			key, ok := w.cache.keyOf(filename)
+			if !ok {
+				return true
+			}
+			entry = Data{Key: key}
+		}
+		return w.emit(ctx, Event{Type: EventRemove, Key: entry.Key, Expiry: entry.Expiry})
+	}
+	return true
+}
+
+// scan emits a set event for each unknown entry in a directory,
+// relative to the cache directory, and its subdirectories.
+// It returns false if the context is done.
+func (w *watch) scan(ctx context.Context, dir string) bool {
+	var filenames []string
+	_ = filepath.WalkDir(w.cache.filepath(dir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		filename, err := filepath.Rel(w.cache.dir, path)
+		if err != nil || !w.cache.isEntry(filename) {
+			return nil
+		}
+		if _, ok := w.entries[filename]; !ok {
+			filenames = append(filenames, filename)
+		}
+		return nil
+	})
+	for _, filename := range filenames {
+		entry, err := w.cache.readKey(filename)
+		if err != nil {
+			continue
+		}
+		w.entries[filename] = entry
+		if !w.emit(ctx, Event{Type: EventSet, Key: entry.Key, Expiry: entry.Expiry}) {
+			return false
+		}
+	}
+	return true
+}
+
+// expire emits an expire event for each known entry that expired.
+// It reports each expiry once.
+// It returns false if the context is done.
+func (w *watch) expire(ctx context.Context, now time.Time) bool {
+	var events []Event
+	for filename, entry := range w.entries {
+		if expired(entry.Expiry, now) {
+			events = append(events, Event{Type: EventExpire, Key: entry.Key, Expiry: entry.Expiry})
+			// Clear the expiry so the entry is reported once, but removals still are.
+			entry.Expiry = time.Time{}
+			w.entries[filename] = entry
+		}
+	}
+	for _, event := range events {
+		if !w.emit(ctx, event) {
+			return false
+		}
+	}
+	return true
+}
+
+// emit sends an event unless the context is done.
+// It returns false if the context is done.
+func (w *watch) emit(ctx context.Context, event Event) bool {
+	select {
+	case w.events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// readKey reads the key, expiry, and metadata of a cache entry
+// without reading its sidecar.
+func (c Cache) readKey(filename string) (Data, error) {
+	rec, err := c.readHeader(filename)
+	if err != nil {
+		return Data{}, err
+	}
+	return rec.Data, nil
+}
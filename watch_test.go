@@ -0,0 +1,86 @@
+package diskcache_test
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jluckyiv/diskcache"
+)
+
+func TestWatch(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []diskcache.Option
+	}{
+		{"TestFlat", nil},
+		{"TestSharded", []diskcache.Option{diskcache.WithSharding()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cacheDir := path.Join(t.TempDir(), "testcache")
+			cache, err := diskcache.New(cacheDir, tc.options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			err = cache.Set("existing", []byte("value"), 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			events, err := cache.Watch(ctx)
+			if err != nil {
+				t.Fatalf("Error watching cache: %v", err)
+			}
+
+			// Another process writes to the same directory.
+			other, err := diskcache.New(cacheDir, tc.options...)
+			if err != nil {
+				t.Fatalf("Error creating cache: %v", err)
+			}
+			err = other.Set("new", []byte("value"), 1*time.Minute)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			waitForEvent(t, events, diskcache.EventSet, "new")
+
+			err = other.Remove("existing")
+			if err != nil {
+				t.Fatalf("Error deleting cache: %v", err)
+			}
+			waitForEvent(t, events, diskcache.EventRemove, "existing")
+
+			err = other.Set("short", []byte("value"), 100*time.Millisecond)
+			if err != nil {
+				t.Fatalf("Error saving cache: %v", err)
+			}
+			waitForEvent(t, events, diskcache.EventExpire, "short")
+
+			cancel()
+			for range events {
+			}
+		})
+	}
+}
+
+// waitForEvent waits for an event of the type for the key,
+// skipping other events.
+func waitForEvent(t *testing.T, events <-chan diskcache.Event, typ diskcache.EventType, key string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("Expected %s event for %s, but the channel closed", typ, key)
+			}
+			if event.Type == typ && event.Key == key {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Expected %s event for %s", typ, key)
+		}
+	}
+}